@@ -0,0 +1,51 @@
+// Package golangci registers the yake policy analyzers as a golangci-lint
+// module plugin. "yake code linter-new --lang go --yake" writes the
+// .custom-gcl.yml that builds a golangci-lint binary with it.
+package golangci
+
+import (
+	"fmt"
+
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/linter"
+	"github.com/vitalvas/yake/internal/policy"
+	"golang.org/x/tools/go/analysis"
+)
+
+// golangci-lint only finds module plugins registered while the custom
+// binary starts up.
+func init() {
+	register.Plugin(linter.YakeLinter, New)
+}
+
+// Plugin runs the per-file yake policies configured in .yake.yaml of the
+// working directory.
+type Plugin struct {
+	cfg *config.Config
+}
+
+// New creates the plugin. The linter takes no settings of its own; every
+// rule is configured in .yake.yaml.
+func New(_ any) (register.LinterPlugin, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load yake config: %w", err)
+	}
+
+	return &Plugin{cfg: cfg}, nil
+}
+
+// BuildAnalyzers returns the analyzers of the enabled policies.
+func (p *Plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	return policy.Analyzers(p.cfg), nil
+}
+
+// GetLoadMode asks for type information only when policy.type_check is set.
+func (p *Plugin) GetLoadMode() string {
+	if p.cfg.Policy.TypeCheck != nil && *p.cfg.Policy.TypeCheck {
+		return register.LoadModeTypesInfo
+	}
+
+	return register.LoadModeSyntax
+}
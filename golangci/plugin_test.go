@@ -0,0 +1,70 @@
+package golangci
+
+import (
+	"os"
+	"testing"
+
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/linter"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("is registered under the yake linter name", func(t *testing.T) {
+		newPlugin, err := register.GetPlugin(linter.YakeLinter)
+		require.NoError(t, err)
+		assert.NotNil(t, newPlugin)
+	})
+
+	t.Run("builds analyzers of the enabled policies", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy:\n  no_init:\n    enable: false\n"), 0644))
+
+		plugin, err := New(nil)
+		require.NoError(t, err)
+
+		analyzers, err := plugin.BuildAnalyzers()
+		require.NoError(t, err)
+		require.NotEmpty(t, analyzers)
+
+		for _, analyzer := range analyzers {
+			assert.NotEqual(t, "no_init", analyzer.Name)
+		}
+
+		assert.Equal(t, register.LoadModeSyntax, plugin.GetLoadMode())
+	})
+
+	t.Run("loads type information when type checking is enabled", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy:\n  type_check: true\n"), 0644))
+
+		plugin, err := New(nil)
+		require.NoError(t, err)
+		assert.Equal(t, register.LoadModeTypesInfo, plugin.GetLoadMode())
+	})
+
+	t.Run("returns error for invalid config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy: [\n"), 0644))
+
+		_, err := New(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load yake config")
+	})
+}
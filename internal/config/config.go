@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"regexp"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -21,6 +24,11 @@ const (
 	DefaultMaxTestDuration       = 10 * time.Second
 	DefaultPackageNamingPattern  = `^[0-9a-z]{3,32}$`
 	DefaultMaxSingleLineFields   = 5
+	DefaultMaxCyclomatic         = 30
+	DefaultMaxCognitive          = 60
+	DefaultMaxFuncLines          = 120
+	DefaultMaxNesting            = 6
+	DefaultRatchetTolerance      = 0.5
 )
 
 type Config struct {
@@ -33,50 +41,143 @@ type TestsConfig struct {
 }
 
 type PolicyConfig struct {
+	// Exclude lists path globs left out of every policy.
+	Exclude []string `yaml:"exclude"`
+	// TypeCheck loads the packages with full type information, built with
+	// the test tags, so checks resolve types instead of guessing from
+	// syntax. Disabled by default since it compiles the dependencies.
+	TypeCheck *bool `yaml:"type_check"`
+
 	EntryPoints            *EntryPointsPolicy      `yaml:"entry_points"`
 	PackageNaming          *PackageNamingPolicy    `yaml:"package_naming"`
 	ASCIIOnly              *PolicyToggle           `yaml:"ascii_only"`
 	StringConcat           *PolicyToggle           `yaml:"string_concat"`
 	StdlibWrappers         *PolicyToggle           `yaml:"stdlib_wrappers"`
 	FuncSignature          *FuncSignaturePolicy    `yaml:"func_signature"`
+	Complexity             *ComplexityPolicy       `yaml:"complexity"`
 	CompositeLiteral       *CompositeLiteralPolicy `yaml:"composite_literal"`
 	Stuttering             *PolicyToggle           `yaml:"stuttering"`
 	GetterNaming           *PolicyToggle           `yaml:"getter_naming"`
 	PrivateExportedMethods *PolicyToggle           `yaml:"private_exported_methods"`
 	NoInit                 *PolicyToggle           `yaml:"no_init"`
+	ErrorHandling          *PolicyToggle           `yaml:"error_handling"`
+	Context                *PolicyToggle           `yaml:"context"`
+	Termination            *TerminationPolicy      `yaml:"termination"`
+	Concurrency            *PolicyToggle           `yaml:"concurrency"`
 	TestFileNaming         *PolicyToggle           `yaml:"test_file_naming"`
+	TestQuality            *TestQualityPolicy      `yaml:"test_quality"`
+	Imports                *ImportsPolicy          `yaml:"imports"`
 	TestDuration           *TestDurationPolicy     `yaml:"test_duration"`
 	Coverage               *CoveragePolicy         `yaml:"coverage"`
+	Suppressions           *SuppressionsPolicy     `yaml:"suppressions"`
+}
+
+// PolicyScope limits a policy section to files matching Paths (all files when
+// empty) minus those matching ExcludePaths. A glob without a slash matches any
+// path element; "**" matches any number of directories.
+type PolicyScope struct {
+	Paths        []string `yaml:"paths"`
+	ExcludePaths []string `yaml:"exclude_paths"`
 }
 
 type PolicyToggle struct {
-	Enabled *bool `yaml:"enable"`
+	Enabled     *bool `yaml:"enable"`
+	PolicyScope `yaml:",inline"`
 }
 
 type EntryPointsPolicy struct {
 	Enabled      *bool `yaml:"enable"`
 	MaxMainLines *int  `yaml:"max_main_lines"`
+	PolicyScope  `yaml:",inline"`
 }
 
 type PackageNamingPolicy struct {
-	Enabled *bool   `yaml:"enable"`
-	Pattern *string `yaml:"pattern"`
+	Enabled     *bool   `yaml:"enable"`
+	Pattern     *string `yaml:"pattern"`
+	PolicyScope `yaml:",inline"`
 }
 
 type CompositeLiteralPolicy struct {
 	Enabled             *bool `yaml:"enable"`
 	MaxSingleLineFields *int  `yaml:"max_single_line_fields"`
+	PolicyScope         `yaml:",inline"`
 }
 
 type FuncSignaturePolicy struct {
-	Enabled    *bool `yaml:"enable"`
-	MaxParams  *int  `yaml:"max_params"`
-	MaxResults *int  `yaml:"max_results"`
+	Enabled     *bool `yaml:"enable"`
+	MaxParams   *int  `yaml:"max_params"`
+	MaxResults  *int  `yaml:"max_results"`
+	PolicyScope `yaml:",inline"`
+}
+
+// ComplexityPolicy limits the complexity of every function. A limit of 0
+// turns that metric off.
+type ComplexityPolicy struct {
+	Enabled          *bool `yaml:"enable"`
+	ComplexityLimits `yaml:",inline"`
+	// Overrides replace limits for the files matching their paths. The first
+	// matching override wins; limits it leaves unset keep the section value.
+	Overrides   []ComplexityOverride `yaml:"overrides"`
+	PolicyScope `yaml:",inline"`
+}
+
+type ComplexityLimits struct {
+	MaxCyclomatic *int `yaml:"max_cyclomatic"`
+	MaxCognitive  *int `yaml:"max_cognitive"`
+	// MaxFuncLines counts the non-blank lines of a function body.
+	MaxFuncLines *int `yaml:"max_func_lines"`
+	MaxNesting   *int `yaml:"max_nesting"`
+}
+
+type ComplexityOverride struct {
+	Paths            []string `yaml:"paths"`
+	ComplexityLimits `yaml:",inline"`
+}
+
+// TerminationPolicy forbids ending the process outside package main.
+type TerminationPolicy struct {
+	Enabled *bool `yaml:"enable"`
+	// NoStdout also forbids printing to stdout with fmt.Print*, print and
+	// println outside package main.
+	NoStdout *bool `yaml:"no_stdout"`
+	// AllowPackages lists package directories that may exit and print like
+	// package main, such as the one implementing the CLI.
+	AllowPackages []string `yaml:"allow_packages"`
+	PolicyScope   `yaml:",inline"`
+}
+
+// TestQualityPolicy checks how tests are written: helpers call t.Helper,
+// environment changes go through t.Setenv and skips give a reason.
+type TestQualityPolicy struct {
+	Enabled *bool `yaml:"enable"`
+	// RequireParallel requires t.Parallel() as the first statement of every
+	// test that does not change the environment or working directory.
+	RequireParallel *bool `yaml:"require_parallel"`
+	// RequireTestify reports "if ... { t.Fatal(...) }" checks that should be
+	// require or assert calls.
+	RequireTestify *bool `yaml:"require_testify"`
+	PolicyScope    `yaml:",inline"`
 }
 
 type TestDurationPolicy struct {
 	Enabled     *bool   `yaml:"enable"`
 	MaxDuration *string `yaml:"max_duration"`
+	// MaxTestDuration limits every test and subtest on its own. Unset turns
+	// the per-test check off.
+	MaxTestDuration *string `yaml:"max_test_duration"`
+	// SlowestTests logs the given number of slowest top-level tests.
+	SlowestTests *int `yaml:"slowest_tests"`
+	// PackageOverrides replaces the limits of single packages, keyed by
+	// package directory as in CoveragePolicy.PackageOverrides.
+	PackageOverrides map[string]TestDurationOverride `yaml:"package_overrides"`
+	PolicyScope      `yaml:",inline"`
+}
+
+// TestDurationOverride holds the limits of one package. Unset limits keep the
+// section's values.
+type TestDurationOverride struct {
+	MaxDuration     *string `yaml:"max_duration"`
+	MaxTestDuration *string `yaml:"max_test_duration"`
 }
 
 type CoveragePolicy struct {
@@ -85,6 +186,58 @@ type CoveragePolicy struct {
 	MaxUncoveredFuncLines *int               `yaml:"max_uncovered_func_lines"`
 	ExcludePackages       []string           `yaml:"exclude_packages"`
 	PackageOverrides      map[string]float64 `yaml:"package_overrides"`
+	// PatchMin is the minimum coverage of the lines added or changed since
+	// the default branch. Unset or zero turns the patch check off.
+	PatchMin *float64 `yaml:"patch_min"`
+	// Ratchet fails when a package drops below the coverage recorded in
+	// .yake-coverage.json by more than RatchetTolerance percentage points.
+	Ratchet          *bool    `yaml:"ratchet"`
+	RatchetTolerance *float64 `yaml:"ratchet_tolerance"`
+	// CoverDirs are GOCOVERDIR directories written by binaries built with
+	// `go build -cover`, merged with the test passes before evaluation.
+	CoverDirs   []string `yaml:"cover_dirs"`
+	PolicyScope `yaml:",inline"`
+}
+
+// ImportsPolicy restricts which packages may import which. Globs name
+// packages of the module by their directory relative to the module root, as
+// path scopes do, and any package by its full import path; a glob matching a
+// package also covers the packages below it.
+type ImportsPolicy struct {
+	Enabled *bool `yaml:"enable"`
+	// Layers lists the architecture layers from the top down. A package may
+	// import packages of its own layer and of the layers below, never of a
+	// layer above.
+	Layers []ImportLayer `yaml:"layers"`
+	// Rules restrict the imports of selected packages.
+	Rules []ImportRule `yaml:"rules"`
+	// Forbidden lists packages, or whole modules, no package may import.
+	Forbidden   []string `yaml:"forbidden"`
+	PolicyScope `yaml:",inline"`
+}
+
+// ImportLayer names a group of packages by glob.
+type ImportLayer struct {
+	Name     string   `yaml:"name"`
+	Packages []string `yaml:"packages"`
+}
+
+// ImportRule applies to the packages matching From. They must not import
+// packages matching Deny and, when Allow is set, may import only the module
+// packages matching Allow.
+type ImportRule struct {
+	From   string   `yaml:"from"`
+	Deny   []string `yaml:"deny"`
+	Allow  []string `yaml:"allow"`
+	Reason string   `yaml:"reason"`
+}
+
+// SuppressionsPolicy controls the checks on //yake:ignore directives. The
+// directives themselves are always honored.
+type SuppressionsPolicy struct {
+	Enabled       *bool `yaml:"enable"`
+	RequireReason *bool `yaml:"require_reason"`
+	PolicyScope   `yaml:",inline"`
 }
 
 // Load reads File, unmarshals and validates it. When the file does not exist
@@ -112,12 +265,58 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) validate() error {
-	if c.Policy.TestDuration != nil && c.Policy.TestDuration.MaxDuration != nil {
-		if _, err := time.ParseDuration(*c.Policy.TestDuration.MaxDuration); err != nil {
-			return fmt.Errorf("test_duration.max_duration: %w", err)
+	if err := validateGlobs("policy.exclude", c.Policy.Exclude); err != nil {
+		return err
+	}
+
+	for name, scope := range c.Policy.Scopes() {
+		if err := validateGlobs(fmt.Sprintf("%s.paths", name), scope.Paths); err != nil {
+			return err
+		}
+
+		if err := validateGlobs(fmt.Sprintf("%s.exclude_paths", name), scope.ExcludePaths); err != nil {
+			return err
+		}
+	}
+
+	if c.Policy.Complexity != nil {
+		for i, override := range c.Policy.Complexity.Overrides {
+			if len(override.Paths) == 0 {
+				return fmt.Errorf("complexity.overrides[%d]: paths are required", i)
+			}
+
+			if err := validateGlobs(fmt.Sprintf("complexity.overrides[%d].paths", i), override.Paths); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.Policy.Termination != nil {
+		if err := validateGlobs("termination.allow_packages", c.Policy.Termination.AllowPackages); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Policy.Imports.validate(); err != nil {
+		return err
+	}
+
+	if c.Policy.Coverage != nil && c.Policy.Coverage.PatchMin != nil {
+		if patchMin := *c.Policy.Coverage.PatchMin; patchMin < 0 || patchMin > 100 {
+			return fmt.Errorf("coverage.patch_min: %v is not a percentage", patchMin)
 		}
 	}
 
+	if c.Policy.Coverage != nil && c.Policy.Coverage.RatchetTolerance != nil {
+		if tolerance := *c.Policy.Coverage.RatchetTolerance; tolerance < 0 || tolerance > 100 {
+			return fmt.Errorf("coverage.ratchet_tolerance: %v is not a percentage", tolerance)
+		}
+	}
+
+	if err := c.Policy.TestDuration.validate(); err != nil {
+		return err
+	}
+
 	if c.Policy.PackageNaming != nil && c.Policy.PackageNaming.Pattern != nil {
 		if _, err := regexp.Compile(*c.Policy.PackageNaming.Pattern); err != nil {
 			return fmt.Errorf("package_naming.pattern: %w", err)
@@ -126,3 +325,146 @@ func (c *Config) validate() error {
 
 	return nil
 }
+
+// Scopes returns the path scope of every configured section, keyed by the
+// section name. Sections left out of the file have no entry.
+func (p *PolicyConfig) Scopes() map[string]PolicyScope {
+	scopes := make(map[string]PolicyScope)
+
+	toggles := map[string]*PolicyToggle{
+		"ascii_only":               p.ASCIIOnly,
+		"string_concat":            p.StringConcat,
+		"stdlib_wrappers":          p.StdlibWrappers,
+		"stuttering":               p.Stuttering,
+		"getter_naming":            p.GetterNaming,
+		"private_exported_methods": p.PrivateExportedMethods,
+		"no_init":                  p.NoInit,
+		"error_handling":           p.ErrorHandling,
+		"context":                  p.Context,
+		"concurrency":              p.Concurrency,
+		"test_file_naming":         p.TestFileNaming,
+	}
+
+	for name, toggle := range toggles {
+		if toggle != nil {
+			scopes[name] = toggle.PolicyScope
+		}
+	}
+
+	if p.EntryPoints != nil {
+		scopes["entry_points"] = p.EntryPoints.PolicyScope
+	}
+
+	if p.PackageNaming != nil {
+		scopes["package_naming"] = p.PackageNaming.PolicyScope
+	}
+
+	if p.FuncSignature != nil {
+		scopes["func_signature"] = p.FuncSignature.PolicyScope
+	}
+
+	if p.Complexity != nil {
+		scopes["complexity"] = p.Complexity.PolicyScope
+	}
+
+	if p.CompositeLiteral != nil {
+		scopes["composite_literal"] = p.CompositeLiteral.PolicyScope
+	}
+
+	if p.Termination != nil {
+		scopes["termination"] = p.Termination.PolicyScope
+	}
+
+	if p.Imports != nil {
+		scopes["imports"] = p.Imports.PolicyScope
+	}
+
+	if p.TestQuality != nil {
+		scopes["test_quality"] = p.TestQuality.PolicyScope
+	}
+
+	if p.TestDuration != nil {
+		scopes["test_duration"] = p.TestDuration.PolicyScope
+	}
+
+	if p.Coverage != nil {
+		scopes["coverage"] = p.Coverage.PolicyScope
+	}
+
+	if p.Suppressions != nil {
+		scopes["suppressions"] = p.Suppressions.PolicyScope
+	}
+
+	return scopes
+}
+
+func (p *ImportsPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+
+	for i, layer := range p.Layers {
+		if layer.Name == "" || len(layer.Packages) == 0 {
+			return fmt.Errorf("imports.layers[%d]: name and packages are required", i)
+		}
+
+		if err := validateGlobs(fmt.Sprintf("imports.layers[%d].packages", i), layer.Packages); err != nil {
+			return err
+		}
+	}
+
+	for i, rule := range p.Rules {
+		if rule.From == "" || len(rule.Deny)+len(rule.Allow) == 0 {
+			return fmt.Errorf("imports.rules[%d]: from and deny or allow are required", i)
+		}
+
+		globs := append([]string{rule.From}, rule.Deny...)
+		if err := validateGlobs(fmt.Sprintf("imports.rules[%d]", i), append(globs, rule.Allow...)); err != nil {
+			return err
+		}
+	}
+
+	return validateGlobs("imports.forbidden", p.Forbidden)
+}
+
+func validateGlobs(field string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid glob %q: %w", field, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *TestDurationPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+
+	durations := map[string]*string{
+		"test_duration.max_duration":      p.MaxDuration,
+		"test_duration.max_test_duration": p.MaxTestDuration,
+	}
+
+	for pkg, override := range p.PackageOverrides {
+		durations[fmt.Sprintf("test_duration.package_overrides[%s].max_duration", pkg)] = override.MaxDuration
+		durations[fmt.Sprintf("test_duration.package_overrides[%s].max_test_duration", pkg)] = override.MaxTestDuration
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(durations)) {
+		if durations[field] == nil {
+			continue
+		}
+
+		if _, err := time.ParseDuration(*durations[field]); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+
+	if p.SlowestTests != nil && *p.SlowestTests < 0 {
+		return fmt.Errorf("test_duration.slowest_tests: %d is negative", *p.SlowestTests)
+	}
+
+	return nil
+}
@@ -51,6 +51,8 @@ func TestLoad(t *testing.T) {
     enable: false
   string_concat:
     enable: false
+  suppressions:
+    require_reason: true
 tests:
   tags:
     - integration
@@ -70,6 +72,7 @@ tests:
 		assert.Equal(t, "^[a-z]{2,16}$", *cfg.Policy.PackageNaming.Pattern)
 		assert.False(t, *cfg.Policy.ASCIIOnly.Enabled)
 		assert.False(t, *cfg.Policy.StringConcat.Enabled)
+		assert.True(t, *cfg.Policy.Suppressions.RequireReason)
 		assert.Equal(t, []string{"integration", "e2e"}, cfg.Tests.Tags)
 	})
 
@@ -128,6 +131,22 @@ tests:
 		assert.Equal(t, []string{"internal/cmd", "internal/database"}, cfg.Policy.Coverage.ExcludePackages)
 	})
 
+	t.Run("parses type check flag", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile(File, []byte("policy:\n  type_check: true\n"), 0644))
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Policy.TypeCheck)
+		assert.True(t, *cfg.Policy.TypeCheck)
+	})
+
 	t.Run("parses coverage package overrides", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		originalDir, _ := os.Getwd()
@@ -151,6 +170,85 @@ tests:
 		assert.Equal(t, map[string]float64{"internal/cmd": 40.0, "internal/database": 50.0}, cfg.Policy.Coverage.PackageOverrides)
 	})
 
+	t.Run("parses imports policy", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		content := `policy:
+  imports:
+    layers:
+      - name: core
+        packages: [internal/core]
+      - name: tools
+        packages: [internal/tools]
+    rules:
+      - from: internal/domain/**
+        deny: [internal/transport/**]
+        reason: keep the domain transport-agnostic
+    forbidden: [github.com/pkg/errors]
+`
+		require.NoError(t, os.WriteFile(File, []byte(content), 0644))
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Policy.Imports)
+		assert.Equal(t, []ImportLayer{
+			{
+				Name:     "core",
+				Packages: []string{"internal/core"},
+			},
+			{
+				Name:     "tools",
+				Packages: []string{"internal/tools"},
+			},
+		}, cfg.Policy.Imports.Layers)
+		assert.Equal(t, []ImportRule{
+			{
+				From:   "internal/domain/**",
+				Deny:   []string{"internal/transport/**"},
+				Reason: "keep the domain transport-agnostic",
+			},
+		}, cfg.Policy.Imports.Rules)
+		assert.Equal(t, []string{"github.com/pkg/errors"}, cfg.Policy.Imports.Forbidden)
+	})
+
+	t.Run("parses complexity limits and overrides", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		content := `policy:
+  complexity:
+    max_cyclomatic: 15
+    max_cognitive: 20
+    max_func_lines: 60
+    max_nesting: 4
+    overrides:
+      - paths: [internal/legacy/**]
+        max_cyclomatic: 40
+`
+		require.NoError(t, os.WriteFile(File, []byte(content), 0644))
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Policy.Complexity)
+		assert.Equal(t, 15, *cfg.Policy.Complexity.MaxCyclomatic)
+		assert.Equal(t, 20, *cfg.Policy.Complexity.MaxCognitive)
+		assert.Equal(t, 60, *cfg.Policy.Complexity.MaxFuncLines)
+		assert.Equal(t, 4, *cfg.Policy.Complexity.MaxNesting)
+		require.Len(t, cfg.Policy.Complexity.Overrides, 1)
+		assert.Equal(t, []string{"internal/legacy/**"}, cfg.Policy.Complexity.Overrides[0].Paths)
+		assert.Equal(t, 40, *cfg.Policy.Complexity.Overrides[0].MaxCyclomatic)
+		assert.Nil(t, cfg.Policy.Complexity.Overrides[0].MaxNesting)
+	})
+
 	t.Run("returns error on invalid regex pattern", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		originalDir, _ := os.Getwd()
@@ -197,6 +295,39 @@ func Test_Config_validate(t *testing.T) {
 		assert.Contains(t, err.Error(), "test_duration.max_duration")
 	})
 
+	t.Run("invalid per-test and override durations fail", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				TestDuration: &TestDurationPolicy{MaxTestDuration: stringPtr("fast")},
+			},
+		}
+		err := cfg.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test_duration.max_test_duration")
+
+		cfg.Policy.TestDuration = &TestDurationPolicy{
+			MaxTestDuration: stringPtr("2s"),
+			PackageOverrides: map[string]TestDurationOverride{
+				"internal/db": {MaxDuration: stringPtr("30s"), MaxTestDuration: stringPtr("1m?")},
+			},
+		}
+		err = cfg.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test_duration.package_overrides[internal/db].max_test_duration")
+	})
+
+	t.Run("negative slowest_tests fails", func(t *testing.T) {
+		slowest := -1
+		cfg := &Config{
+			Policy: PolicyConfig{
+				TestDuration: &TestDurationPolicy{SlowestTests: &slowest},
+			},
+		}
+		err := cfg.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test_duration.slowest_tests")
+	})
+
 	t.Run("valid pattern passes", func(t *testing.T) {
 		cfg := &Config{
 			Policy: PolicyConfig{
@@ -216,4 +347,176 @@ func Test_Config_validate(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "package_naming.pattern")
 	})
+	t.Run("invalid global exclude fails", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{Exclude: []string{"[bad"}},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "policy.exclude")
+	})
+
+	t.Run("invalid section paths fail", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				NoInit: &PolicyToggle{PolicyScope: PolicyScope{Paths: []string{"[bad"}}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no_init.paths")
+	})
+
+	t.Run("invalid section exclude paths fail", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Coverage: &CoveragePolicy{PolicyScope: PolicyScope{ExcludePaths: []string{"[bad"}}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "coverage.exclude_paths")
+	})
+
+	t.Run("complexity override without paths fails", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Complexity: &ComplexityPolicy{Overrides: []ComplexityOverride{{}}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "complexity.overrides[0]")
+	})
+
+	t.Run("invalid complexity override paths fail", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Complexity: &ComplexityPolicy{Overrides: []ComplexityOverride{{Paths: []string{"[bad"}}}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "complexity.overrides[0].paths")
+	})
+
+	t.Run("patch_min outside 0-100 fails", func(t *testing.T) {
+		patchMin := 120.0
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Coverage: &CoveragePolicy{PatchMin: &patchMin},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "coverage.patch_min")
+	})
+
+	t.Run("negative ratchet_tolerance fails", func(t *testing.T) {
+		tolerance := -1.0
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Coverage: &CoveragePolicy{RatchetTolerance: &tolerance},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "coverage.ratchet_tolerance")
+	})
+
+	t.Run("invalid termination allow packages fail", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Termination: &TerminationPolicy{AllowPackages: []string{"[bad"}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "termination.allow_packages")
+	})
+
+	t.Run("imports layer without packages fails", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Imports: &ImportsPolicy{Layers: []ImportLayer{{Name: "core"}}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "imports.layers[0]")
+	})
+
+	t.Run("imports rule without deny or allow fails", func(t *testing.T) {
+		cfg := &Config{
+			Policy: PolicyConfig{
+				Imports: &ImportsPolicy{Rules: []ImportRule{{From: "internal/domain"}}},
+			},
+		}
+		err := cfg.validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "imports.rules[0]")
+	})
+
+	t.Run("invalid imports globs fail", func(t *testing.T) {
+		for field, imports := range map[string]*ImportsPolicy{
+			"imports.layers[0].packages": {Layers: []ImportLayer{{Name: "core", Packages: []string{"[bad"}}}},
+			"imports.rules[0]":           {Rules: []ImportRule{{From: "a", Allow: []string{"[bad"}}}},
+			"imports.forbidden":          {Forbidden: []string{"[bad"}},
+		} {
+			cfg := &Config{
+				Policy: PolicyConfig{Imports: imports},
+			}
+			err := cfg.validate()
+			assert.Error(t, err, field)
+			assert.ErrorContains(t, err, field)
+		}
+	})
+}
+
+func TestPolicyConfig_Scopes(t *testing.T) {
+	t.Run("returns no scopes for empty config", func(t *testing.T) {
+		assert.Empty(t, (&PolicyConfig{}).Scopes())
+	})
+
+	t.Run("keys scopes by section name", func(t *testing.T) {
+		scope := PolicyScope{Paths: []string{"internal"}, ExcludePaths: []string{"mocks"}}
+
+		p := &PolicyConfig{
+			EntryPoints:      &EntryPointsPolicy{PolicyScope: scope},
+			PackageNaming:    &PackageNamingPolicy{PolicyScope: scope},
+			StringConcat:     &PolicyToggle{PolicyScope: scope},
+			FuncSignature:    &FuncSignaturePolicy{PolicyScope: scope},
+			Complexity:       &ComplexityPolicy{PolicyScope: scope},
+			CompositeLiteral: &CompositeLiteralPolicy{PolicyScope: scope},
+			Termination:      &TerminationPolicy{PolicyScope: scope},
+			Imports:          &ImportsPolicy{PolicyScope: scope},
+			TestQuality:      &TestQualityPolicy{PolicyScope: scope},
+			TestDuration:     &TestDurationPolicy{PolicyScope: scope},
+			Coverage:         &CoveragePolicy{PolicyScope: scope},
+			Suppressions:     &SuppressionsPolicy{PolicyScope: scope},
+		}
+
+		scopes := p.Scopes()
+		assert.Len(t, scopes, 12)
+
+		for _, name := range []string{"entry_points", "package_naming", "string_concat", "func_signature", "complexity", "composite_literal", "termination", "imports", "test_quality", "test_duration", "coverage", "suppressions"} {
+			assert.Equal(t, scope, scopes[name], name)
+		}
+	})
+
+	t.Run("parses inline scope fields", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		content := "policy:\n  exclude: [third_party]\n  no_init:\n    enable: true\n    paths: [internal]\n    exclude_paths: [\"**/mocks\"]\n"
+		require.NoError(t, os.WriteFile(File, []byte(content), 0644))
+
+		cfg, err := Load()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third_party"}, cfg.Policy.Exclude)
+		assert.Equal(t, PolicyScope{Paths: []string{"internal"}, ExcludePaths: []string{"**/mocks"}}, cfg.Policy.Scopes()["no_init"])
+	})
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -18,13 +19,15 @@ func createLinterNewCommand() *cobra.Command {
 		Short: "Create a new linter configuration file",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			lang, _ := cmd.Flags().GetString("lang")
+			yake, _ := cmd.Flags().GetBool("yake")
+
 			switch lang {
 			case "go":
 				if _, err := os.Stat(".golangci.yml"); err == nil {
 					return fmt.Errorf("linter config file already exists")
 				}
 
-				if err := codeLinterNewGolang(); err != nil {
+				if err := codeLinterNewGolang(yake); err != nil {
 					return err
 				}
 
@@ -36,6 +39,7 @@ func createLinterNewCommand() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringP("lang", "l", "", "Programming language (required)")
+	cmd.Flags().Bool("yake", false, "Enable the yake policy linter through a custom golangci-lint build")
 	cmd.MarkFlagRequired("lang")
 	return cmd
 }
@@ -48,7 +52,7 @@ var codeSubcommands = []*cobra.Command{
 			if _, err := os.Stat("go.mod"); err == nil {
 
 				if _, err := os.Stat(".golangci.yml"); err != nil {
-					if err := codeLinterNewGolang(); err != nil {
+					if err := codeLinterNewGolang(false); err != nil {
 						return err
 					}
 				}
@@ -81,14 +85,14 @@ func createGithubReleasePleaseCommand() *cobra.Command {
 			}
 
 			if cfg.Force {
-				return codeGithubReleasePleaseWorkflow(cfg)
+				return codeGithubReleasePleaseWorkflow(cmd.Context(), cfg)
 			}
 
 			if _, err := os.Stat(".github/workflows/release-please.yml"); err == nil {
 				return fmt.Errorf("release-please workflow already exists")
 			}
 
-			return codeGithubReleasePlease(cfg)
+			return codeGithubReleasePlease(cmd.Context(), cfg)
 		},
 	}
 
@@ -98,16 +102,26 @@ func createGithubReleasePleaseCommand() *cobra.Command {
 	return cmd
 }
 
-func codeLinterNewGolang() error {
-	payload := linter.GetGolangCI()
+func codeLinterNewGolang(yake bool) error {
+	payload := linter.GetGolangCI(yake)
 
 	log.Println("Creating .golangci.yml")
 
-	return tools.WriteYamlFile(".golangci.yml", payload)
+	if err := tools.WriteYamlFile(".golangci.yml", payload); err != nil {
+		return err
+	}
+
+	if !yake {
+		return nil
+	}
+
+	log.Println("Creating .custom-gcl.yml")
+
+	return tools.WriteYamlFile(".custom-gcl.yml", linter.GetCustomGCL())
 }
 
-func codeGithubReleasePleaseWorkflow(cfg releasePleaseConfig) error {
-	branch, err := tools.DetectDefaultBranch()
+func codeGithubReleasePleaseWorkflow(ctx context.Context, cfg releasePleaseConfig) error {
+	branch, err := tools.DetectDefaultBranch(ctx)
 	if err != nil {
 		return err
 	}
@@ -163,8 +177,8 @@ func codeGithubLangGolang() error {
 	return tools.WriteStringToFile(".github/workflows/golang.yml", content)
 }
 
-func codeGithubReleasePlease(cfg releasePleaseConfig) error {
-	if err := codeGithubReleasePleaseWorkflow(cfg); err != nil {
+func codeGithubReleasePlease(ctx context.Context, cfg releasePleaseConfig) error {
+	if err := codeGithubReleasePleaseWorkflow(ctx, cfg); err != nil {
 		return err
 	}
 
@@ -193,7 +207,7 @@ func createGoreleaserCommand() *cobra.Command {
 
 			deb, _ := cmd.Flags().GetBool("deb")
 
-			return codeGoreleaser(goreleaserConfig{
+			return codeGoreleaser(cmd.Context(), goreleaserConfig{
 				DebianPackage: deb,
 			})
 		},
@@ -208,8 +222,8 @@ type goreleaserConfig struct {
 	DebianPackage bool
 }
 
-func codeGoreleaser(cfg goreleaserConfig) error {
-	repo, err := tools.DetectGitHubRepo()
+func codeGoreleaser(ctx context.Context, cfg goreleaserConfig) error {
+	repo, err := tools.DetectGitHubRepo(ctx)
 	if err != nil {
 		return err
 	}
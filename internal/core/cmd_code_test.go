@@ -175,11 +175,32 @@ func TestCodeLinterNewGolang(t *testing.T) {
 
 		os.Chdir(tmpDir)
 
-		err := codeLinterNewGolang()
+		err := codeLinterNewGolang(false)
 		assert.NoError(t, err)
 
 		_, statErr := os.Stat(".golangci.yml")
 		assert.NoError(t, statErr)
+
+		_, statErr = os.Stat(".custom-gcl.yml")
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("adds the yake plugin build", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, codeLinterNewGolang(true))
+
+		golangci, err := os.ReadFile(".golangci.yml")
+		require.NoError(t, err)
+		assert.Contains(t, string(golangci), "type: module")
+
+		custom, err := os.ReadFile(".custom-gcl.yml")
+		require.NoError(t, err)
+		assert.Contains(t, string(custom), "import: github.com/vitalvas/yake/golangci")
 	})
 }
 
@@ -211,7 +232,7 @@ func TestCodeGithubReleasePlease(t *testing.T) {
 		os.Chdir(tmpDir)
 		initTestGitRepo(t, "main")
 
-		err := codeGithubReleasePlease(releasePleaseConfig{})
+		err := codeGithubReleasePlease(t.Context(), releasePleaseConfig{})
 		assert.NoError(t, err)
 
 		_, statErr := os.Stat(".github/workflows/release-please.yml")
@@ -232,7 +253,7 @@ func TestCodeGithubReleasePlease(t *testing.T) {
 		os.Chdir(tmpDir)
 		initTestGitRepo(t, "main")
 
-		err := codeGithubReleasePlease(releasePleaseConfig{})
+		err := codeGithubReleasePlease(t.Context(), releasePleaseConfig{})
 		require.NoError(t, err)
 
 		content, readErr := os.ReadFile(".github/workflows/release-please.yml")
@@ -248,7 +269,7 @@ func TestCodeGithubReleasePlease(t *testing.T) {
 		os.Chdir(tmpDir)
 		initTestGitRepo(t, "master")
 
-		err := codeGithubReleasePlease(releasePleaseConfig{})
+		err := codeGithubReleasePlease(t.Context(), releasePleaseConfig{})
 		assert.NoError(t, err)
 
 		info, statErr := os.Stat(".github/workflows")
@@ -264,7 +285,7 @@ func TestCodeGithubReleasePlease(t *testing.T) {
 		os.Chdir(tmpDir)
 		initTestGitRepo(t, "develop")
 
-		err := codeGithubReleasePlease(releasePleaseConfig{})
+		err := codeGithubReleasePlease(t.Context(), releasePleaseConfig{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "could not detect default branch")
 	})
@@ -490,7 +511,7 @@ func TestCodeGoreleaser(t *testing.T) {
 		gitCmd := exec.Command("git", "remote", "add", "origin", "git@github.com:myowner/myapp.git")
 		require.NoError(t, gitCmd.Run())
 
-		err := codeGoreleaser(goreleaserConfig{})
+		err := codeGoreleaser(t.Context(), goreleaserConfig{})
 		require.NoError(t, err)
 
 		content, readErr := os.ReadFile(".goreleaser.yml")
@@ -514,7 +535,7 @@ func TestCodeGoreleaser(t *testing.T) {
 		os.Chdir(tmpDir)
 		initTestGitRepo(t, "main")
 
-		err := codeGoreleaser(goreleaserConfig{})
+		err := codeGoreleaser(t.Context(), goreleaserConfig{})
 		assert.Error(t, err)
 	})
 }
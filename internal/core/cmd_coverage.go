@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/policy"
+	"github.com/vitalvas/yake/internal/testrun"
+)
+
+func createCoverageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Coverage-related commands",
+	}
+
+	cmd.AddCommand(createCoverageReportCommand())
+
+	return cmd
+}
+
+func createCoverageReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show coverage by package and function, with an annotated HTML report",
+		Long: `Run the tests and show the merged coverage of every pass and configured
+coverage directory as a table of packages and their functions with uncovered
+lines, worst first. Functions the coverage policy reports are marked with "!".
+The same table and the annotated source are written as a standalone HTML page.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			htmlOutput, _ := cmd.Flags().GetString("html")
+
+			if _, err := os.Stat("go.mod"); err != nil {
+				return nil
+			}
+
+			if profile == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+
+				tests, merged, err := measureCoverage(cmd.Context(), cfg)
+				if err != nil {
+					return err
+				}
+
+				defer tests.Close()
+
+				profile = merged
+			}
+
+			report, err := policy.NewCoverageReport(profile)
+			if err != nil {
+				return fmt.Errorf("failed to build coverage report: %w", err)
+			}
+
+			if err := report.WriteText(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			if htmlOutput == "" {
+				return nil
+			}
+
+			var page bytes.Buffer
+			if err := report.WriteHTML(&page); err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(htmlOutput, page.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write coverage report: %w", err)
+			}
+
+			log.Printf("Wrote HTML coverage report to %s", htmlOutput)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Report an existing coverage profile instead of running the tests")
+	cmd.Flags().String("html", "coverage.html", "HTML report file to write; empty skips it")
+
+	return cmd
+}
+
+// measureCoverage runs the configured test passes and merges their coverage
+// with the configured coverage directories. A failing test still leaves
+// coverage worth reporting, so it is only logged. The caller closes the run.
+func measureCoverage(ctx context.Context, cfg *config.Config) (*testrun.Result, string, error) {
+	tests, err := testrun.Run(ctx, testrun.Options{Tags: cfg.Tests.Tags})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := tests.Err(); err != nil {
+		log.Printf("Tests failed, the coverage report may be incomplete: %v", err)
+	}
+
+	var coverDirs []string
+	if cfg.Policy.Coverage != nil {
+		coverDirs = cfg.Policy.Coverage.CoverDirs
+	}
+
+	profile, err := tests.MergedProfile(ctx, coverDirs)
+	if err != nil {
+		tests.Close()
+		return nil, "", err
+	}
+
+	return tests, profile, nil
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCoverageProject lays out a module with one half-covered package and
+// changes into it.
+func writeCoverageProject(t *testing.T) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(originalDir) })
+
+	require.NoError(t, os.Chdir(tmpDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.Mkdir("calc", 0755))
+	require.NoError(t, os.WriteFile("calc/calc.go", []byte("package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n"), 0644))
+	require.NoError(t, os.WriteFile("calc/calc_test.go", []byte("package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"add\")\n\t}\n}\n"), 0644))
+}
+
+func TestCreateCoverageCommand(t *testing.T) {
+	cmd := createCoverageCommand()
+
+	reportCmd, _, err := cmd.Find([]string{"report"})
+	require.NoError(t, err)
+	assert.Equal(t, "report", reportCmd.Use)
+	assert.Equal(t, "coverage.html", reportCmd.Flags().Lookup("html").DefValue)
+	assert.NotNil(t, reportCmd.Flags().Lookup("profile"))
+}
+
+func TestCoverageReportCommand(t *testing.T) {
+	// Skip the race detector's one second delay at exit of the nested run.
+	t.Setenv("GORACE", "atexit_sleep_ms=0")
+
+	t.Run("runs the tests and writes both reports", func(t *testing.T) {
+		writeCoverageProject(t)
+
+		var out bytes.Buffer
+
+		cmd := createCoverageReportCommand()
+		cmd.SetContext(t.Context())
+		cmd.SetOut(&out)
+		require.NoError(t, cmd.RunE(cmd, nil))
+
+		assert.Contains(t, out.String(), "calc.go:7 Sub")
+		assert.Contains(t, out.String(), "50.0%")
+
+		page, err := os.ReadFile("coverage.html")
+		require.NoError(t, err)
+		assert.Contains(t, string(page), "calc/calc.go")
+	})
+
+	t.Run("reports an existing profile", func(t *testing.T) {
+		writeCoverageProject(t)
+		require.NoError(t, os.WriteFile("cover.out", []byte("mode: set\ntestproject/calc/calc.go:4.2,4.14 1 0\n"), 0644))
+
+		var out bytes.Buffer
+
+		cmd := createCoverageReportCommand()
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--profile", "cover.out", "--html", ""})
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "calc.go:3 Add")
+
+		_, err := os.Stat("coverage.html")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("returns error for a missing profile", func(t *testing.T) {
+		writeCoverageProject(t)
+
+		cmd := createCoverageReportCommand()
+		cmd.SetArgs([]string{"--profile", "missing.out"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to build coverage report")
+	})
+
+	t.Run("returns error when the report cannot be written", func(t *testing.T) {
+		writeCoverageProject(t)
+		require.NoError(t, os.WriteFile("cover.out", []byte("mode: set\n"), 0644))
+
+		cmd := createCoverageReportCommand()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--profile", "cover.out", "--html", "missing/coverage.html"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write coverage report")
+	})
+
+	t.Run("does nothing without go.mod", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		cmd := createCoverageReportCommand()
+		require.NoError(t, cmd.RunE(cmd, nil))
+	})
+}
@@ -36,6 +36,7 @@ func assertCommandBehavior(t *testing.T, createCmd func() *cobra.Command, expect
 		os.Chdir(tmpDir)
 
 		cmd := createCmd()
+		cmd.SetContext(t.Context())
 		err := cmd.RunE(cmd, nil)
 		assert.NoError(t, err)
 	})
@@ -51,6 +52,7 @@ func assertCommandBehavior(t *testing.T, createCmd func() *cobra.Command, expect
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
 		cmd := createCmd()
+		cmd.SetContext(t.Context())
 		err := cmd.RunE(cmd, nil)
 		assert.NoError(t, err)
 	})
@@ -1,11 +1,20 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/vitalvas/yake/internal/config"
 	"github.com/vitalvas/yake/internal/policy"
+	"github.com/vitalvas/yake/internal/testrun"
+	"github.com/vitalvas/yake/internal/tools"
 )
 
 func createPolicyCommand() *cobra.Command {
@@ -22,12 +31,76 @@ func createPolicyCommand() *cobra.Command {
 }
 
 var policySubcommands = []*cobra.Command{
-	{
+	createPolicyRunCommand(),
+	createPolicyBaselineCommand(),
+	createPolicyCoverageCommand(),
+	createPolicyFixCommand(),
+	createPolicyLintCommand(),
+}
+
+type policyRunConfig struct {
+	Format string
+	Output string
+	Jobs   int
+	// Baseline is the baseline file whose violations are tolerated. Empty or
+	// missing means every violation fails the run.
+	Baseline string
+	// Tests is an instrumented test run to reuse instead of running the
+	// tests again for the duration and coverage checks.
+	Tests *testrun.Result
+	// Changes restricts the per-file checks to changed files, as computed
+	// by --changed-since or --staged. Nil checks the whole project.
+	Changes          tools.Changes
+	ChangedLines     bool
+	SkipProjectRules bool
+}
+
+func createPolicyRunCommand() *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run policy checks for the project",
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			jobs, _ := cmd.Flags().GetInt("jobs")
+			baseline, _ := cmd.Flags().GetString("baseline")
+			changedSince, _ := cmd.Flags().GetString("changed-since")
+			staged, _ := cmd.Flags().GetBool("staged")
+			changedLines, _ := cmd.Flags().GetBool("changed-lines")
+			skipProjectRules, _ := cmd.Flags().GetBool("skip-project-rules")
+
+			if !cmd.Flags().Changed("format") {
+				format = defaultPolicyFormat()
+			}
+
+			if !slices.Contains(policy.ReportFormats, format) {
+				return fmt.Errorf("unsupported report format: %s (use one of: %s)",
+					format, strings.Join(policy.ReportFormats, ", "))
+			}
+
+			if jobs < 1 {
+				return fmt.Errorf("invalid --jobs value %d: must be at least 1", jobs)
+			}
+
+			if changedSince == "" && !staged && (changedLines || skipProjectRules) {
+				return fmt.Errorf("--changed-lines and --skip-project-rules require --changed-since or --staged")
+			}
+
 			if _, err := os.Stat("go.mod"); err == nil {
-				if err := policy.RunGolangChecks(); err != nil {
+				changes, err := resolveChanges(cmd.Context(), changedSince, staged)
+				if err != nil {
+					return err
+				}
+
+				if err := runGolangPolicy(cmd.Context(), policyRunConfig{
+					Format:           format,
+					Output:           output,
+					Jobs:             jobs,
+					Baseline:         baseline,
+					Changes:          changes,
+					ChangedLines:     changedLines,
+					SkipProjectRules: skipProjectRules,
+				}); err != nil {
 					return err
 				}
 			}
@@ -36,5 +109,311 @@ var policySubcommands = []*cobra.Command{
 
 			return nil
 		},
-	},
+	}
+
+	cmd.Flags().StringP("format", "f", policy.FormatText, "Report format: text, json, sarif, junit, github (default github inside GitHub Actions)")
+	cmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+	cmd.Flags().IntP("jobs", "j", policy.DefaultJobs(), "Number of checks and files to analyze in parallel")
+	cmd.Flags().String("baseline", policy.BaselineFile, "Baseline file with accepted violations")
+	cmd.Flags().String("changed-since", "", "Check only files changed since the merge base with this git ref")
+	cmd.Flags().Bool("staged", false, "Check only files staged for commit")
+	cmd.Flags().Bool("changed-lines", false, "Report only violations on changed lines")
+	cmd.Flags().Bool("skip-project-rules", false, "Skip project-wide rules such as coverage and entry_points in a diff-aware run")
+	cmd.MarkFlagsMutuallyExclusive("changed-since", "staged")
+
+	return cmd
+}
+
+// resolveChanges computes the changed files for a diff-aware run. It returns
+// nil when neither --changed-since nor --staged is set.
+func resolveChanges(ctx context.Context, changedSince string, staged bool) (tools.Changes, error) {
+	switch {
+	case staged:
+		log.Println("Checking files staged for commit")
+
+		return tools.StagedChanges(ctx)
+	case changedSince != "":
+		log.Printf("Checking files changed since %s", changedSince)
+
+		return tools.ChangesSince(ctx, changedSince)
+	default:
+		return nil, nil
+	}
+}
+
+func createPolicyBaselineCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Record current policy violations as accepted",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			jobs, _ := cmd.Flags().GetInt("jobs")
+
+			if jobs < 1 {
+				return fmt.Errorf("invalid --jobs value %d: must be at least 1", jobs)
+			}
+
+			if _, err := os.Stat("go.mod"); err != nil {
+				return nil
+			}
+
+			violations, err := policy.RunGolangChecks(cmd.Context(), policy.RunOptions{Jobs: jobs})
+			if err != nil {
+				return fmt.Errorf("failed to record baseline: %w", err)
+			}
+
+			if err := policy.NewBaseline(violations).Save(output); err != nil {
+				return err
+			}
+
+			log.Printf("Recorded %d policy violations in %s", len(violations), output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", policy.BaselineFile, "Baseline file to write")
+	cmd.Flags().IntP("jobs", "j", policy.DefaultJobs(), "Number of checks and files to analyze in parallel")
+
+	return cmd
+}
+
+func createPolicyCoverageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Coverage ratchet commands",
+	}
+
+	cmd.AddCommand(createPolicyCoverageUpdateCommand())
+
+	return cmd
+}
+
+func createPolicyCoverageUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Raise the recorded coverage floors to the current coverage",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			output, _ := cmd.Flags().GetString("output")
+
+			if _, err := os.Stat("go.mod"); err != nil {
+				return nil
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			tests, err := testrun.Run(cmd.Context(), testrun.Options{Tags: cfg.Tests.Tags})
+			if err != nil {
+				return err
+			}
+
+			defer tests.Close()
+
+			changed, err := policy.UpdateCoverageFloors(cmd.Context(), output, tests)
+			if err != nil {
+				return fmt.Errorf("failed to update coverage floors: %w", err)
+			}
+
+			log.Printf("Updated %d coverage floors in %s", len(changed), output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", policy.CoverageFile, "Coverage floors file to update")
+
+	return cmd
+}
+
+func createPolicyFixCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Rewrite mechanically fixable policy violations",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			jobs, _ := cmd.Flags().GetInt("jobs")
+
+			if jobs < 1 {
+				return fmt.Errorf("invalid --jobs value %d: must be at least 1", jobs)
+			}
+
+			if _, err := os.Stat("go.mod"); err != nil {
+				return nil
+			}
+
+			fixed, err := policy.FixGolang(policy.FixOptions{Jobs: jobs})
+			if err != nil {
+				return fmt.Errorf("failed to fix policy violations: %w", err)
+			}
+
+			for _, file := range fixed {
+				if dryRun {
+					fmt.Fprint(cmd.OutOrStdout(), file.Diff())
+					continue
+				}
+
+				if err := file.Write(); err != nil {
+					return err
+				}
+			}
+
+			if dryRun {
+				log.Printf("%d files would be changed", len(fixed))
+			} else {
+				log.Printf("Fixed %d files", len(fixed))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Print a unified diff instead of writing the files")
+	cmd.Flags().IntP("jobs", "j", policy.DefaultJobs(), "Number of checks and files to analyze in parallel")
+
+	return cmd
+}
+
+func createPolicyLintCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint [flags] [packages]",
+		Short: "Run the per-file policies as a go/analysis multichecker",
+		Long: `Run the per-file policies as go/analysis analyzers over the given packages.
+
+Flags are those of the standard analysis driver, for example -fix to apply the
+suggested fixes, -json for machine-readable output, or -<rule>=false to turn a
+single analyzer off. Run "yake policy lint help" for the full list.`,
+		DisableFlagParsing: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			runAnalyzers(args, policy.Analyzers(cfg))
+
+			return nil
+		},
+	}
+}
+
+// defaultPolicyFormat picks GitHub annotations when running inside GitHub
+// Actions and plain text everywhere else.
+func defaultPolicyFormat() string {
+	if tools.InGitHubActions() {
+		return policy.FormatGitHub
+	}
+
+	return policy.FormatText
+}
+
+// runGolangPolicy runs the Go policy checks. Plain text output without a
+// report file is returned as the error itself; any other report is written
+// out and the error only summarizes the violation count. GitHub annotations
+// on stdout also keep the full text error so the job log stays readable.
+func runGolangPolicy(ctx context.Context, cfg policyRunConfig) error {
+	violations, err := policy.RunGolangChecks(ctx, policy.RunOptions{
+		Jobs:             cfg.Jobs,
+		Tests:            cfg.Tests,
+		Changed:          cfg.Changes,
+		ChangedLines:     cfg.ChangedLines,
+		SkipProjectRules: cfg.SkipProjectRules,
+	})
+
+	violations, baselineErr := applyBaseline(cfg.Baseline, violations, cfg.Changes == nil)
+	if baselineErr != nil {
+		return errors.Join(baselineErr, err)
+	}
+
+	if cfg.Format == policy.FormatText && cfg.Output == "" {
+		return errors.Join(policy.ViolationsError(violations), err)
+	}
+
+	if cfg.Format == policy.FormatGitHub {
+		if summaryErr := writePolicySummary(violations); summaryErr != nil {
+			err = errors.Join(summaryErr, err)
+		}
+	}
+
+	if writeErr := writePolicyReport(cfg, violations); writeErr != nil {
+		return errors.Join(writeErr, err)
+	}
+
+	if cfg.Format == policy.FormatGitHub && cfg.Output == "" {
+		return errors.Join(policy.ViolationsError(violations), err)
+	}
+
+	if len(violations) > 0 {
+		err = errors.Join(fmt.Errorf("%d policy violations found", len(violations)), err)
+	}
+
+	return err
+}
+
+// applyBaseline drops the violations recorded in the baseline file and logs
+// the entries that no longer occur, so the file can shrink over time. Partial
+// runs pass reportFixed=false since unchecked files would look fixed. Without
+// a baseline file the violations are returned unchanged.
+func applyBaseline(path string, violations []policy.Violation, reportFixed bool) ([]policy.Violation, error) {
+	if path == "" {
+		return violations, nil
+	}
+
+	baseline, err := policy.LoadBaseline(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return violations, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	fresh, fixed := baseline.Filter(violations)
+
+	log.Printf("Baseline %s: %d accepted, %d new", path, len(violations)-len(fresh), len(fresh))
+
+	if reportFixed && len(fixed) > 0 {
+		log.Printf("%d baseline entries are fixed; run 'yake policy baseline' to remove them:", len(fixed))
+
+		for _, entry := range fixed {
+			log.Printf("  - %s: %s (%s)", entry.File, entry.Message, entry.Rule)
+		}
+	}
+
+	return fresh, nil
+}
+
+// writePolicySummary appends a markdown table of violations to the GitHub
+// Actions job summary.
+func writePolicySummary(violations []policy.Violation) error {
+	var buf bytes.Buffer
+
+	if err := policy.WriteSummary(&buf, violations); err != nil {
+		return err
+	}
+
+	if err := tools.AppendGitHubStepSummary(buf.String()); err != nil {
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+
+	return nil
+}
+
+func writePolicyReport(cfg policyRunConfig, violations []policy.Violation) error {
+	var buf bytes.Buffer
+
+	if err := policy.WriteReport(&buf, cfg.Format, violations); err != nil {
+		return err
+	}
+
+	if cfg.Output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	log.Printf("Writing %s policy report to %s", cfg.Format, cfg.Output)
+
+	return tools.WriteStringToFile(cfg.Output, buf.String())
 }
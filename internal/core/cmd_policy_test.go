@@ -1,12 +1,20 @@
 package core
 
 import (
+	"bytes"
+	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/vitalvas/yake/internal/policy"
+	"github.com/vitalvas/yake/internal/tools"
 )
 
 func TestCreatePolicyCommand(t *testing.T) {
@@ -33,10 +41,61 @@ func TestCreatePolicyCommand(t *testing.T) {
 
 		require.NotNil(t, runCmd, "run subcommand should exist")
 		assert.Equal(t, "Run policy checks for the project", runCmd.Short)
+
+		formatFlag := runCmd.Flags().Lookup("format")
+		require.NotNil(t, formatFlag)
+		assert.Equal(t, "text", formatFlag.DefValue)
+		assert.NotNil(t, runCmd.Flags().Lookup("output"))
+
+		jobsFlag := runCmd.Flags().Lookup("jobs")
+		require.NotNil(t, jobsFlag)
+		assert.Equal(t, "j", jobsFlag.Shorthand)
+		assert.Equal(t, strconv.Itoa(policy.DefaultJobs()), jobsFlag.DefValue)
+
+		baselineFlag := runCmd.Flags().Lookup("baseline")
+		require.NotNil(t, baselineFlag)
+		assert.Equal(t, policy.BaselineFile, baselineFlag.DefValue)
+	})
+
+	t.Run("has baseline subcommand", func(t *testing.T) {
+		cmd := createPolicyCommand()
+
+		baselineCmd, _, err := cmd.Find([]string{"baseline"})
+		require.NoError(t, err)
+		assert.Equal(t, "Record current policy violations as accepted", baselineCmd.Short)
+
+		outputFlag := baselineCmd.Flags().Lookup("output")
+		require.NotNil(t, outputFlag)
+		assert.Equal(t, policy.BaselineFile, outputFlag.DefValue)
+		assert.NotNil(t, baselineCmd.Flags().Lookup("jobs"))
+	})
+
+	t.Run("has coverage update subcommand", func(t *testing.T) {
+		cmd := createPolicyCommand()
+
+		updateCmd, _, err := cmd.Find([]string{"coverage", "update"})
+		require.NoError(t, err)
+		assert.Equal(t, "Raise the recorded coverage floors to the current coverage", updateCmd.Short)
+
+		outputFlag := updateCmd.Flags().Lookup("output")
+		require.NotNil(t, outputFlag)
+		assert.Equal(t, policy.CoverageFile, outputFlag.DefValue)
+	})
+
+	t.Run("has fix subcommand", func(t *testing.T) {
+		cmd := createPolicyCommand()
+
+		fixCmd, _, err := cmd.Find([]string{"fix"})
+		require.NoError(t, err)
+		assert.Equal(t, "Rewrite mechanically fixable policy violations", fixCmd.Short)
+		assert.NotNil(t, fixCmd.Flags().Lookup("dry-run"))
+		assert.NotNil(t, fixCmd.Flags().Lookup("jobs"))
 	})
 }
 
 func TestPolicyRunCommand(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+
 	t.Run("returns nil when no go.mod exists", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		originalDir, _ := os.Getwd()
@@ -52,4 +111,530 @@ func TestPolicyRunCommand(t *testing.T) {
 		err = runCmd.RunE(runCmd, nil)
 		assert.NoError(t, err)
 	})
+
+	t.Run("rejects unsupported format", func(t *testing.T) {
+		cmd := createPolicyRunCommand()
+		cmd.SetArgs([]string{"--format", "yaml"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported report format: yaml")
+	})
+
+	t.Run("rejects non-positive jobs", func(t *testing.T) {
+		cmd := createPolicyRunCommand()
+		cmd.SetArgs([]string{"--jobs", "0"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --jobs value 0")
+	})
+
+	t.Run("rejects changed-since with staged", func(t *testing.T) {
+		cmd := createPolicyRunCommand()
+		cmd.SetArgs([]string{"--changed-since", "main", "--staged"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "none of the others can be")
+	})
+
+	t.Run("rejects changed-lines without diff mode", func(t *testing.T) {
+		cmd := createPolicyRunCommand()
+		cmd.SetArgs([]string{"--changed-lines"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "require --changed-since or --staged")
+	})
+
+	t.Run("returns error for unknown ref", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+
+		cmd := createPolicyRunCommand()
+		cmd.SetArgs([]string{"--changed-since", "no-such-ref"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to find merge base with no-such-ref")
+	})
+}
+
+func Test_resolveChanges(t *testing.T) {
+	t.Run("returns nil without diff mode", func(t *testing.T) {
+		changes, err := resolveChanges(t.Context(), "", false)
+		require.NoError(t, err)
+		assert.Nil(t, changes)
+	})
+
+	t.Run("returns staged changes", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		for _, args := range [][]string{
+			{"init", "-q"},
+			{"config", "user.email", "test@example.com"},
+			{"config", "user.name", "Test"},
+		} {
+			require.NoError(t, exec.Command("git", args...).Run())
+		}
+
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n"), 0644))
+		require.NoError(t, exec.Command("git", "add", "main.go").Run())
+
+		changes, err := resolveChanges(t.Context(), "", true)
+		require.NoError(t, err)
+		assert.Equal(t, tools.Changes{"main.go": {{Start: 1, End: 1}}}, changes)
+	})
+}
+
+func TestPolicyBaselineCommand(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+
+	t.Run("records violations and lets the next run pass", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc init() {}\n\nfunc main() {}\n"), 0644))
+
+		baselineCmd := createPolicyBaselineCommand()
+		require.NoError(t, baselineCmd.RunE(baselineCmd, nil))
+
+		baseline, err := policy.LoadBaseline(policy.BaselineFile)
+		require.NoError(t, err)
+		assert.Len(t, baseline.Violations, 2)
+
+		runCmd := createPolicyRunCommand()
+		assert.NoError(t, runCmd.RunE(runCmd, nil))
+
+		// A new violation still fails the run.
+		require.NoError(t, os.WriteFile("util.go", []byte("package main\n\nfunc init() {}\n"), 0644))
+
+		err = runCmd.RunE(runCmd, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "util.go:3:1")
+		assert.NotContains(t, err.Error(), "- main.go")
+	})
+
+	t.Run("does nothing without go.mod", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		cmd := createPolicyBaselineCommand()
+		cmd.SetContext(t.Context())
+		require.NoError(t, cmd.RunE(cmd, nil))
+
+		_, err := os.Stat(policy.BaselineFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("rejects non-positive jobs", func(t *testing.T) {
+		cmd := createPolicyBaselineCommand()
+		cmd.SetArgs([]string{"--jobs", "0"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --jobs value 0")
+	})
+
+	t.Run("returns error when checks cannot run", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy: ["), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+
+		cmd := createPolicyBaselineCommand()
+		cmd.SetContext(t.Context())
+		err := cmd.RunE(cmd, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record baseline")
+	})
+
+	t.Run("returns error when baseline cannot be written", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+
+		cmd := createPolicyBaselineCommand()
+		cmd.SetArgs([]string{"--output", filepath.Join("missing", policy.BaselineFile)})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write baseline")
+	})
+}
+
+func TestPolicyCoverageUpdateCommand(t *testing.T) {
+	// Skip the race detector's one second delay at exit of the nested run.
+	t.Setenv("GORACE", "atexit_sleep_ms=0")
+
+	t.Run("records the coverage of each package", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.Mkdir("calc", 0755))
+		require.NoError(t, os.WriteFile("calc/calc.go", []byte("package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n"), 0644))
+		require.NoError(t, os.WriteFile("calc/calc_test.go", []byte("package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"add\")\n\t}\n}\n"), 0644))
+
+		cmd := createPolicyCoverageUpdateCommand()
+		cmd.SetContext(t.Context())
+		require.NoError(t, cmd.RunE(cmd, nil))
+
+		floors, err := policy.LoadCoverageFloors(policy.CoverageFile)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{"calc": 50}, floors.Packages)
+	})
+
+	t.Run("does nothing without go.mod", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		cmd := createPolicyCoverageUpdateCommand()
+		cmd.SetContext(t.Context())
+		require.NoError(t, cmd.RunE(cmd, nil))
+
+		_, err := os.Stat(policy.CoverageFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestPolicyFixCommand(t *testing.T) {
+	source := "package main\n\nfunc main() {\n\tname := \"x\"\n\tprintln(\"hello \" + name)\n}\n"
+
+	setup := func(t *testing.T) {
+		t.Helper()
+
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		t.Cleanup(func() { os.Chdir(originalDir) })
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte(source), 0644))
+	}
+
+	t.Run("prints a diff without writing in dry run", func(t *testing.T) {
+		setup(t)
+
+		var out bytes.Buffer
+
+		cmd := createPolicyFixCommand()
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--dry-run"})
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "--- a/main.go")
+		assert.Contains(t, out.String(), "+\tprintln(fmt.Sprintf(\"hello %s\", name))")
+
+		data, err := os.ReadFile("main.go")
+		require.NoError(t, err)
+		assert.Equal(t, source, string(data))
+	})
+
+	t.Run("writes fixed files", func(t *testing.T) {
+		setup(t)
+
+		cmd := createPolicyFixCommand()
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+
+		data, err := os.ReadFile("main.go")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "import \"fmt\"")
+		assert.Contains(t, string(data), "println(fmt.Sprintf(\"hello %s\", name))")
+	})
+
+	t.Run("returns nil when no go.mod exists", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		cmd := createPolicyFixCommand()
+		cmd.SetArgs([]string{})
+		assert.NoError(t, cmd.Execute())
+	})
+
+	t.Run("returns error for invalid config", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy: [\n"), 0644))
+
+		cmd := createPolicyFixCommand()
+		cmd.SetArgs([]string{})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fix policy violations")
+	})
+
+	t.Run("rejects non-positive jobs", func(t *testing.T) {
+		cmd := createPolicyFixCommand()
+		cmd.SetArgs([]string{"--jobs", "0"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --jobs value 0")
+	})
+}
+
+func Test_applyBaseline(t *testing.T) {
+	violations := []policy.Violation{
+		{Rule: policy.RuleNoInit, File: "a.go", Message: "init() function is forbidden"},
+		{Rule: policy.RuleNoInit, File: "b.go", Message: "init() function is forbidden"},
+	}
+
+	t.Run("keeps violations without a baseline path", func(t *testing.T) {
+		got, err := applyBaseline("", violations, true)
+		require.NoError(t, err)
+		assert.Equal(t, violations, got)
+	})
+
+	t.Run("keeps violations when the baseline file is missing", func(t *testing.T) {
+		got, err := applyBaseline(filepath.Join(t.TempDir(), policy.BaselineFile), violations, true)
+		require.NoError(t, err)
+		assert.Equal(t, violations, got)
+	})
+
+	t.Run("drops accepted violations and logs fixed entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), policy.BaselineFile)
+		accepted := []policy.Violation{
+			violations[0],
+			{Rule: policy.RuleNoInit, File: "c.go", Message: "init() function is forbidden"},
+		}
+		require.NoError(t, policy.NewBaseline(accepted).Save(path))
+
+		var logs bytes.Buffer
+
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		got, err := applyBaseline(path, violations, true)
+		require.NoError(t, err)
+		assert.Equal(t, violations[1:], got)
+		assert.Contains(t, logs.String(), "1 accepted, 1 new")
+		assert.Contains(t, logs.String(), "1 baseline entries are fixed")
+		assert.Contains(t, logs.String(), "c.go: init() function is forbidden (no_init)")
+	})
+
+	t.Run("does not log fixed entries in partial runs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), policy.BaselineFile)
+		require.NoError(t, policy.NewBaseline(violations[:1]).Save(path))
+
+		var logs bytes.Buffer
+
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		got, err := applyBaseline(path, nil, false)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.NotContains(t, logs.String(), "baseline entries are fixed")
+	})
+
+	t.Run("returns error for invalid baseline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), policy.BaselineFile)
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+		_, err := applyBaseline(path, violations, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse baseline")
+	})
+}
+
+func Test_defaultPolicyFormat(t *testing.T) {
+	t.Run("uses text outside GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		assert.Equal(t, policy.FormatText, defaultPolicyFormat())
+	})
+
+	t.Run("uses annotations inside GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
+		assert.Equal(t, policy.FormatGitHub, defaultPolicyFormat())
+	})
+}
+
+func TestRunGolangPolicy(t *testing.T) {
+	t.Run("returns violations as error", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc init() {}\n\nfunc main() {}\n"), 0644))
+
+		err := runGolangPolicy(t.Context(), policyRunConfig{Format: policy.FormatText, Jobs: 2})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "entry point violations:")
+		assert.Contains(t, err.Error(), "main.go:3:1: unexpected function 'init'")
+	})
+
+	t.Run("writes report file and summarizes violations", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc init() {}\n\nfunc main() {}\n"), 0644))
+
+		err := runGolangPolicy(t.Context(), policyRunConfig{Format: policy.FormatJSON, Output: "report.json"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "policy violations found")
+
+		content, readErr := os.ReadFile("report.json")
+		require.NoError(t, readErr)
+		assert.Contains(t, string(content), `"rule": "entry_points"`)
+		assert.Contains(t, string(content), `"rule": "no_init"`)
+	})
+
+	t.Run("writes empty report when clean", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+
+		err := runGolangPolicy(t.Context(), policyRunConfig{Format: policy.FormatSARIF, Output: "report.sarif"})
+		require.NoError(t, err)
+
+		content, readErr := os.ReadFile("report.sarif")
+		require.NoError(t, readErr)
+		assert.Contains(t, string(content), `"version": "2.1.0"`)
+	})
+
+	t.Run("writes github annotations and job summary", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		summaryPath := filepath.Join(tmpDir, "summary.md")
+		t.Setenv("GITHUB_STEP_SUMMARY", summaryPath)
+
+		yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc init() {}\n\nfunc main() {}\n"), 0644))
+
+		err := runGolangPolicy(t.Context(), policyRunConfig{Format: policy.FormatGitHub, Output: "annotations.txt"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "policy violations found")
+
+		annotations, readErr := os.ReadFile("annotations.txt")
+		require.NoError(t, readErr)
+		assert.Contains(t, string(annotations), "::error file=main.go,line=3,col=1,title=no_init::")
+
+		summary, readErr := os.ReadFile(summaryPath)
+		require.NoError(t, readErr)
+		assert.Contains(t, string(summary), "## yake policy")
+		assert.Contains(t, string(summary), "| `no_init` | `main.go:3:1` |")
+	})
+}
+
+func TestPolicyLintCommand(t *testing.T) {
+	// The multichecker driver exits the process, so the command runs in a
+	// copy of the test binary.
+	if os.Getenv("YAKE_TEST_POLICY_LINT") == "1" {
+		cmd := createPolicyLintCommand()
+		cmd.SetArgs([]string{"./..."})
+		cmd.Execute()
+
+		return
+	}
+
+	setup := func(t *testing.T, config string) string {
+		t.Helper()
+
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		t.Cleanup(func() { os.Chdir(originalDir) })
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc init() {}\n\nfunc main() {}\n"), 0644))
+		require.NoError(t, os.WriteFile(".yake.yaml", []byte(config), 0644))
+
+		return tmpDir
+	}
+
+	t.Run("reports violations through the analysis driver", func(t *testing.T) {
+		tmpDir := setup(t, "policy:\n  string_concat:\n    enable: false\n")
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPolicyLintCommand$")
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), "YAKE_TEST_POLICY_LINT=1", "GORACE=atexit_sleep_ms=0")
+
+		output, err := cmd.CombinedOutput()
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+		assert.Contains(t, string(output), "main.go:3:1: init() function is forbidden")
+	})
+
+	t.Run("returns error for invalid config", func(t *testing.T) {
+		setup(t, "policy: [\n")
+
+		cmd := createPolicyLintCommand()
+		cmd.SetContext(t.Context())
+		require.Error(t, cmd.RunE(cmd, nil))
+	})
+
+	t.Run("leaves flag parsing to the driver", func(t *testing.T) {
+		cmd := createPolicyLintCommand()
+
+		assert.True(t, cmd.DisableFlagParsing)
+	})
 }
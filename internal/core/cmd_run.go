@@ -8,36 +8,46 @@ import (
 
 	"github.com/vitalvas/yake/internal/config"
 	"github.com/vitalvas/yake/internal/policy"
+	"github.com/vitalvas/yake/internal/testrun"
 )
 
 func createRunCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run tests and policy checks",
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			cfg, err := config.Load()
 			if err != nil {
 				return err
 			}
 
+			var tests *testrun.Result
+
 			if _, err := os.Stat("go.mod"); err == nil {
-				if err := runGoTests(cfg.Tests.Tags); err != nil {
+				tests, err = runGoTests(cmd.Context(), cfg.Tests.Tags)
+				if err != nil {
 					return err
 				}
+
+				defer tests.Close()
 			}
 
 			if _, err := os.Stat("Cargo.toml"); err == nil {
-				if err := runRustTests(); err != nil {
+				if err := runRustTests(cmd.Context()); err != nil {
 					return err
 				}
 			}
 
-			if err := runGoreleaserCheck(); err != nil {
+			if err := runGoreleaserCheck(cmd.Context()); err != nil {
 				return err
 			}
 
 			if _, err := os.Stat("go.mod"); err == nil {
-				if err := policy.RunGolangChecks(); err != nil {
+				if err := runGolangPolicy(cmd.Context(), policyRunConfig{
+					Format:   defaultPolicyFormat(),
+					Baseline: policy.BaselineFile,
+					Tests:    tests,
+				}); err != nil {
 					return err
 				}
 			}
@@ -9,6 +9,8 @@ import (
 )
 
 func TestCreateRunCommand(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+
 	assertCommandBehavior(t, createRunCommand, "run", "Run tests and policy checks")
 
 	t.Run("returns error when go tests fail", func(t *testing.T) {
@@ -22,6 +24,7 @@ func TestCreateRunCommand(t *testing.T) {
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() { invalid }\n"), 0644))
 
 		cmd := createRunCommand()
+		cmd.SetContext(t.Context())
 		err := cmd.RunE(cmd, nil)
 		assert.Error(t, err)
 	})
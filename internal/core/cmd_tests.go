@@ -6,11 +6,14 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
+	"github.com/vitalvas/yake/internal/tools"
 )
 
 var taskTimeout = time.Minute
@@ -19,25 +22,28 @@ func createTestsCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "tests",
 		Short: "Run tests with coverage, race detection, and linting",
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			cfg, err := config.Load()
 			if err != nil {
 				return err
 			}
 
 			if _, err := os.Stat("go.mod"); err == nil {
-				if err := runGoTests(cfg.Tests.Tags); err != nil {
+				tests, err := runGoTests(cmd.Context(), cfg.Tests.Tags)
+				if err != nil {
 					return err
 				}
+
+				tests.Close()
 			}
 
 			if _, err := os.Stat("Cargo.toml"); err == nil {
-				if err := runRustTests(); err != nil {
+				if err := runRustTests(cmd.Context()); err != nil {
 					return err
 				}
 			}
 
-			if err := runGoreleaserCheck(); err != nil {
+			if err := runGoreleaserCheck(cmd.Context()); err != nil {
 				return err
 			}
 
@@ -55,64 +61,131 @@ type command struct {
 	args []string
 }
 
-// goTagsArgs returns a "-tags=<tag>" argument for each configured build tag.
-func goTagsArgs(tags []string) []string {
-	args := make([]string, 0, len(tags))
-	for _, tag := range tags {
-		args = append(args, fmt.Sprintf("-tags=%s", tag))
-	}
-
-	return args
-}
-
-// goTestCommands builds the Go command sequence. The untagged vet/test/race
-// run always executes; when build tags are configured an additional tagged
-// pass is appended so both tagged and untagged code paths are exercised.
+// goTestCommands builds the Go command sequence that precedes the test run.
+// The untagged vet always executes; when build tags are configured a tagged
+// vet is appended so both tagged and untagged code paths are checked.
 func goTestCommands(tags []string) []command {
 	commands := []command{
 		{name: "go", args: []string{"fmt", "./..."}},
 		{name: "go", args: []string{"vet", "./..."}},
 		{name: "go", args: []string{"mod", "tidy", "-v"}},
 		{name: "go", args: []string{"clean", "-testcache"}},
-		{name: "go", args: []string{"test", "-cover", "./..."}},
-		{name: "go", args: []string{"test", "-race", "./..."}},
 	}
 
-	if tagsArgs := goTagsArgs(tags); len(tagsArgs) > 0 {
+	if tagsArgs := testrun.TagsArgs(tags); len(tagsArgs) > 0 {
 		vetArgs := append(append([]string{"vet"}, tagsArgs...), "./...")
-		testArgs := append(append([]string{"test", "-cover"}, tagsArgs...), "./...")
-		raceArgs := append(append([]string{"test", "-race"}, tagsArgs...), "./...")
 
-		commands = append(commands,
-			command{name: "go", args: vetArgs},
-			command{name: "go", args: testArgs},
-			command{name: "go", args: raceArgs},
-		)
+		commands = append(commands, command{name: "go", args: vetArgs})
 	}
 
 	return commands
 }
 
-func runGoTests(tags []string) error {
-	commands := goTestCommands(tags)
+// runGoTests runs the Go checks and a single instrumented test run with
+// coverage and race detection, plus a tagged pass when build tags are set.
+// The returned run is handed to the policy checks so they do not test the
+// project again; the caller must close it.
+func runGoTests(ctx context.Context, tags []string) (*testrun.Result, error) {
+	for _, cmdInfo := range goTestCommands(tags) {
+		if err := runCommand(ctx, cmdInfo.name, cmdInfo.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	tests, err := testrun.Run(ctx, testrun.Options{
+		Tags:    tags,
+		Output:  os.Stdout,
+		Timeout: taskTimeout,
+	})
+	if err != nil {
+		annotateFailure(err)
+		return nil, err
+	}
+
+	if err := tests.Err(); err != nil {
+		annotateTestFailures(tests, err)
+		tests.Close()
+
+		return nil, err
+	}
 
 	if _, err := os.Stat(".golangci.yml"); err == nil {
-		commands = append(commands, command{name: "golangci-lint", args: []string{"run"}})
+		if err := runCommand(ctx, "golangci-lint", "run"); err != nil {
+			tests.Close()
+			return nil, err
+		}
 	}
 
-	for _, cmdInfo := range commands {
-		if err := runCommand(cmdInfo.name, cmdInfo.args...); err != nil {
-			return err
+	return tests, nil
+}
+
+// runCommand runs a command with the task timeout. Inside GitHub Actions a
+// failure is also emitted as an error annotation so it shows on the run page.
+func runCommand(ctx context.Context, name string, args ...string) error {
+	err := execCommand(ctx, name, args...)
+	if err != nil {
+		annotateFailure(err)
+	}
+
+	return err
+}
+
+// annotateFailure emits err as an error annotation inside GitHub Actions.
+func annotateFailure(err error) {
+	if tools.InGitHubActions() {
+		fmt.Println(tools.GitHubAnnotation{Title: "yake tests", Message: err.Error()})
+	}
+}
+
+// annotateTestFailures emits an error annotation at the location of every
+// failed test inside GitHub Actions. A run without failed tests, such as one
+// that did not build, is annotated with err alone.
+func annotateTestFailures(tests *testrun.Result, err error) {
+	if !tools.InGitHubActions() {
+		return
+	}
+
+	failures := tests.Failures()
+	if len(failures) == 0 {
+		annotateFailure(err)
+		return
+	}
+
+	for _, failure := range failures {
+		fmt.Println(tools.GitHubAnnotation{
+			File:    failure.File,
+			Line:    failure.Line,
+			Title:   failure.Test,
+			Message: failureMessage(failure),
+		})
+	}
+}
+
+// failureMessage returns the output of a failed test without the lines go
+// test adds to follow it.
+func failureMessage(failure testrun.Failure) string {
+	var lines []string
+
+	for line := range strings.Lines(failure.Output) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- FAIL") {
+			continue
 		}
+
+		lines = append(lines, trimmed)
 	}
 
-	return nil
+	if len(lines) == 0 {
+		return fmt.Sprintf("%s failed in %s", failure.Test, failure.Package)
+	}
+
+	return strings.Join(lines, "\n")
 }
 
-func runCommand(name string, args ...string) error {
+func execCommand(ctx context.Context, name string, args ...string) error {
 	log.Printf("Running: %v", append([]string{name}, args...))
 
-	ctx, cancel := context.WithTimeout(context.Background(), taskTimeout)
+	ctx, cancel := context.WithTimeout(ctx, taskTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, name, args...)
@@ -130,7 +203,7 @@ func runCommand(name string, args ...string) error {
 	return nil
 }
 
-func runRustTests() error {
+func runRustTests(ctx context.Context) error {
 	commands := []command{
 		{name: "cargo", args: []string{"fmt", "--check"}},
 		{name: "cargo", args: []string{"clippy", "--", "-D", "warnings"}},
@@ -138,7 +211,7 @@ func runRustTests() error {
 	}
 
 	for _, cmdInfo := range commands {
-		if err := runCommand(cmdInfo.name, cmdInfo.args...); err != nil {
+		if err := runCommand(ctx, cmdInfo.name, cmdInfo.args...); err != nil {
 			return err
 		}
 	}
@@ -146,7 +219,7 @@ func runRustTests() error {
 	return nil
 }
 
-func runGoreleaserCheck() error {
+func runGoreleaserCheck(ctx context.Context) error {
 	if _, err := os.Stat(".goreleaser.yml"); err != nil {
 		return nil
 	}
@@ -155,5 +228,5 @@ func runGoreleaserCheck() error {
 		return nil
 	}
 
-	return runCommand("goreleaser", "check")
+	return runCommand(ctx, "goreleaser", "check")
 }
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,20 +14,20 @@ import (
 
 func Test_runCommand(t *testing.T) {
 	t.Run("runs successful command", func(t *testing.T) {
-		err := runCommand("echo", "hello")
+		err := runCommand(t.Context(), "echo", "hello")
 
 		assert.NoError(t, err)
 	})
 
 	t.Run("returns error for failed command", func(t *testing.T) {
-		err := runCommand("false")
+		err := runCommand(t.Context(), "false")
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to run")
 	})
 
 	t.Run("returns error for non-existent command", func(t *testing.T) {
-		err := runCommand("nonexistent-command-xyz")
+		err := runCommand(t.Context(), "nonexistent-command-xyz")
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to run")
@@ -35,25 +38,29 @@ func Test_runCommand(t *testing.T) {
 		taskTimeout = 100 * time.Millisecond
 		defer func() { taskTimeout = original }()
 
-		err := runCommand("sleep", "10")
+		err := runCommand(t.Context(), "sleep", "10")
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "task timed out")
 	})
-}
 
-func Test_goTagsArgs(t *testing.T) {
-	t.Run("empty tags returns no args", func(t *testing.T) {
-		assert.Empty(t, goTagsArgs(nil))
-		assert.Empty(t, goTagsArgs([]string{}))
-	})
+	t.Run("annotates failures inside GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
 
-	t.Run("single tag", func(t *testing.T) {
-		assert.Equal(t, []string{"-tags=integration"}, goTagsArgs([]string{"integration"}))
-	})
+		reader, writer, err := os.Pipe()
+		require.NoError(t, err)
+
+		originalStdout := os.Stdout
+		os.Stdout = writer
+		defer func() { os.Stdout = originalStdout }()
+
+		runErr := runCommand(t.Context(), "false")
+		writer.Close()
 
-	t.Run("each tag becomes its own flag", func(t *testing.T) {
-		assert.Equal(t, []string{"-tags=integration", "-tags=e2e"}, goTagsArgs([]string{"integration", "e2e"}))
+		output, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Error(t, runErr)
+		assert.Contains(t, string(output), "::error title=yake tests::failed to run [false]")
 	})
 }
 
@@ -63,25 +70,20 @@ func Test_goTestCommands(t *testing.T) {
 		{name: "go", args: []string{"vet", "./..."}},
 		{name: "go", args: []string{"mod", "tidy", "-v"}},
 		{name: "go", args: []string{"clean", "-testcache"}},
-		{name: "go", args: []string{"test", "-cover", "./..."}},
-		{name: "go", args: []string{"test", "-race", "./..."}},
 	}
 
-	t.Run("without tags runs only the untagged pass", func(t *testing.T) {
+	t.Run("without tags runs only the untagged vet", func(t *testing.T) {
 		assert.Equal(t, untagged, goTestCommands(nil))
 	})
 
-	t.Run("with tags keeps the untagged pass and appends a tagged pass", func(t *testing.T) {
+	t.Run("with tags keeps the untagged vet and appends a tagged vet", func(t *testing.T) {
 		got := goTestCommands([]string{"integration", "e2e"})
 
-		// The untagged run must always come first, unchanged.
+		// The untagged commands must always come first, unchanged.
 		assert.Equal(t, untagged, got[:len(untagged)])
 
-		// Followed by an additional tagged vet/test/race pass.
 		assert.Equal(t, []command{
 			{name: "go", args: []string{"vet", "-tags=integration", "-tags=e2e", "./..."}},
-			{name: "go", args: []string{"test", "-cover", "-tags=integration", "-tags=e2e", "./..."}},
-			{name: "go", args: []string{"test", "-race", "-tags=integration", "-tags=e2e", "./..."}},
 		}, got[len(untagged):])
 	})
 }
@@ -97,9 +99,11 @@ func Test_runGoTests(t *testing.T) {
 		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := runGoTests(nil)
+		tests, err := runGoTests(t.Context(), nil)
+		require.NoError(t, err)
+		defer tests.Close()
 
-		assert.NoError(t, err)
+		assert.Len(t, tests.Passes, 1)
 	})
 
 	t.Run("runs with build tags", func(t *testing.T) {
@@ -112,9 +116,12 @@ func Test_runGoTests(t *testing.T) {
 		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := runGoTests([]string{"integration", "e2e"})
+		tests, err := runGoTests(t.Context(), []string{"integration", "e2e"})
+		require.NoError(t, err)
+		defer tests.Close()
 
-		assert.NoError(t, err)
+		require.Len(t, tests.Passes, 2)
+		assert.Equal(t, []string{"integration", "e2e"}, tests.Passes[1].Tags)
 	})
 
 	t.Run("returns error when command fails", func(t *testing.T) {
@@ -125,10 +132,65 @@ func Test_runGoTests(t *testing.T) {
 		os.Chdir(tmpDir)
 
 		// No go.mod means "go fmt ./..." will fail
-		err := runGoTests(nil)
+		_, err := runGoTests(t.Context(), nil)
 
 		assert.Error(t, err)
 	})
+
+	t.Run("returns error and annotates failed tests", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+		t.Setenv("GITHUB_ACTIONS", "true")
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+		require.NoError(t, os.WriteFile("main_test.go", []byte("package main\n\nimport \"testing\"\n\nfunc TestMain(t *testing.T) {\n\tt.Fatal(\"boom\")\n}\n"), 0644))
+
+		reader, writer, err := os.Pipe()
+		require.NoError(t, err)
+
+		originalStdout := os.Stdout
+		os.Stdout = writer
+		defer func() { os.Stdout = originalStdout }()
+
+		output := make(chan []byte)
+		go func() {
+			data, _ := io.ReadAll(reader)
+			output <- data
+		}()
+
+		_, runErr := runGoTests(t.Context(), nil)
+		writer.Close()
+
+		require.Error(t, runErr)
+		assert.Contains(t, runErr.Error(), "failed to run [go test -json -race")
+		assert.Contains(t, string(<-output), "::error file=main_test.go,line=6,title=TestMain::main_test.go:6: boom")
+	})
+
+	t.Run("returns error when golangci-lint fails", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+		require.NoError(t, os.WriteFile(".golangci.yml", []byte("version: \"2\"\n"), 0644))
+
+		// Shadow golangci-lint with a stub that always fails.
+		binDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "golangci-lint"), []byte("#!/bin/sh\nexit 1\n"), 0755))
+		t.Setenv("PATH", fmt.Sprintf("%s%c%s", binDir, os.PathListSeparator, os.Getenv("PATH")))
+
+		_, err := runGoTests(t.Context(), nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "golangci-lint")
+	})
 }
 
 func Test_runGoreleaserCheck(t *testing.T) {
@@ -139,7 +201,7 @@ func Test_runGoreleaserCheck(t *testing.T) {
 
 		os.Chdir(tmpDir)
 
-		err := runGoreleaserCheck()
+		err := runGoreleaserCheck(t.Context())
 
 		assert.NoError(t, err)
 	})
@@ -157,7 +219,7 @@ func Test_runGoreleaserCheck(t *testing.T) {
 		os.Setenv("PATH", tmpDir)
 		defer os.Setenv("PATH", origPath)
 
-		err := runGoreleaserCheck()
+		err := runGoreleaserCheck(t.Context())
 
 		assert.NoError(t, err)
 	})
@@ -175,7 +237,7 @@ func Test_runRustTests(t *testing.T) {
 		os.Setenv("PATH", tmpDir)
 		defer os.Setenv("PATH", origPath)
 
-		err := runRustTests()
+		err := runRustTests(t.Context())
 
 		assert.Error(t, err)
 	})
@@ -195,6 +257,7 @@ func TestCreateTestsCommand(t *testing.T) {
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() { invalid }\n"), 0644))
 
 		cmd := createTestsCommand()
+		cmd.SetContext(t.Context())
 		err := cmd.RunE(cmd, nil)
 		assert.Error(t, err)
 	})
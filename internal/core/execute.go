@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/multichecker"
 )
 
 func Execute() {
@@ -30,6 +32,7 @@ func Execute() {
 	rootCmd.AddCommand(createRunCommand())
 	rootCmd.AddCommand(createTestsCommand())
 	rootCmd.AddCommand(createPolicyCommand())
+	rootCmd.AddCommand(createCoverageCommand())
 	rootCmd.AddCommand(createGitCommand())
 
 	if err := rootCmd.Execute(); err != nil {
@@ -37,3 +40,11 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// runAnalyzers hands the process over to the multichecker driver, which
+// parses args on its own and exits with the analysis result.
+func runAnalyzers(args []string, analyzers []*analysis.Analyzer) {
+	os.Args = append([]string{"yake policy lint"}, args...)
+
+	multichecker.Main(analyzers...)
+}
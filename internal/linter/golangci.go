@@ -18,13 +18,21 @@ type GolangCILinter struct {
 }
 
 type GolangCILinterSettings struct {
-	GoSec GolangCILinterSettingsGosec `yaml:"gosec,omitempty"`
+	GoSec  GolangCILinterSettingsGosec     `yaml:"gosec,omitempty"`
+	Custom map[string]GolangCICustomLinter `yaml:"custom,omitempty"`
 }
 
 type GolangCILinterSettingsGosec struct {
 	Excludes []string `yaml:"excludes,omitempty"`
 }
 
+// GolangCICustomLinter declares a linter built into a custom golangci-lint
+// binary as a module plugin.
+type GolangCICustomLinter struct {
+	Type        string `yaml:"type"`
+	Description string `yaml:"description,omitempty"`
+}
+
 type GolangCIFormatters struct {
 	Enable     []string           `yaml:"enable,omitempty"`
 	Exclusions GolangCIExclusions `yaml:"exclusions,omitempty"`
@@ -36,7 +44,32 @@ type GolangCIExclusions struct {
 	Paths     []string `yaml:"paths,omitempty"`
 }
 
-func GetGolangCI() GolangCI {
+// CustomGCL is the .custom-gcl.yml configuration "golangci-lint custom" reads
+// to build a golangci-lint binary with module plugins.
+type CustomGCL struct {
+	Version string            `yaml:"version"`
+	Plugins []CustomGCLPlugin `yaml:"plugins"`
+}
+
+type CustomGCLPlugin struct {
+	Module  string `yaml:"module"`
+	Import  string `yaml:"import,omitempty"`
+	Version string `yaml:"version,omitempty"`
+}
+
+const (
+	// YakeLinter is the name yake's analyzers are registered under in
+	// golangci-lint.
+	YakeLinter = "yake"
+	// customGCLVersion is the golangci-lint release the custom binary is
+	// built from.
+	customGCLVersion = "v2.5.0"
+)
+
+// GetGolangCI returns the golangci-lint configuration. With yake set the yake
+// policy analyzers are enabled as a module plugin linter, which needs the
+// custom binary described by GetCustomGCL.
+func GetGolangCI(yake bool) GolangCI {
 	data := GolangCI{
 		Version: "2",
 		Linters: GolangCILinter{
@@ -98,6 +131,16 @@ func GetGolangCI() GolangCI {
 		},
 	}
 
+	if yake {
+		data.Linters.Enable = append(data.Linters.Enable, YakeLinter)
+		data.Linters.Settings.Custom = map[string]GolangCICustomLinter{
+			YakeLinter: {
+				Type:        "module",
+				Description: "yake policy rules",
+			},
+		}
+	}
+
 	slices.Sort(data.Linters.Enable)
 	slices.Sort(data.Linters.Exclusions.Presets)
 	slices.Sort(data.Linters.Exclusions.Paths)
@@ -108,3 +151,18 @@ func GetGolangCI() GolangCI {
 
 	return data
 }
+
+// GetCustomGCL returns the configuration of a custom golangci-lint binary
+// with the yake plugin built in.
+func GetCustomGCL() CustomGCL {
+	return CustomGCL{
+		Version: customGCLVersion,
+		Plugins: []CustomGCLPlugin{
+			{
+				Module:  "github.com/vitalvas/yake",
+				Import:  "github.com/vitalvas/yake/golangci",
+				Version: "latest",
+			},
+		},
+	}
+}
@@ -10,14 +10,14 @@ import (
 
 func TestGetGolangCI(t *testing.T) {
 	t.Run("returns valid config structure", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.Equal(t, "2", result.Version)
 		assert.Equal(t, "none", result.Linters.Default)
 	})
 
 	t.Run("contains expected linters", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		expectedLinters := []string{
 			"govet",
@@ -33,33 +33,33 @@ func TestGetGolangCI(t *testing.T) {
 	})
 
 	t.Run("linters are sorted", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.True(t, slices.IsSorted(result.Linters.Enable))
 	})
 
 	t.Run("exclusion presets are sorted", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.True(t, slices.IsSorted(result.Linters.Exclusions.Presets))
 	})
 
 	t.Run("exclusion paths are sorted", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.True(t, slices.IsSorted(result.Linters.Exclusions.Paths))
 		assert.True(t, slices.IsSorted(result.Formatters.Exclusions.Paths))
 	})
 
 	t.Run("has gosec exclusions", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		require.NotEmpty(t, result.Linters.Settings.GoSec.Excludes)
 		assert.Contains(t, result.Linters.Settings.GoSec.Excludes, "G402")
 	})
 
 	t.Run("has exclusion presets", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		expectedPresets := []string{
 			"comments",
@@ -74,7 +74,7 @@ func TestGetGolangCI(t *testing.T) {
 	})
 
 	t.Run("has exclusion paths", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		expectedPaths := []string{
 			"third_party$",
@@ -89,7 +89,7 @@ func TestGetGolangCI(t *testing.T) {
 	})
 
 	t.Run("contains expected formatters", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		expectedFormatters := []string{
 			"gofmt",
@@ -103,21 +103,47 @@ func TestGetGolangCI(t *testing.T) {
 	})
 
 	t.Run("formatters are sorted", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.True(t, slices.IsSorted(result.Formatters.Enable))
 	})
 
 	t.Run("formatters have same exclusion paths as linters", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.Equal(t, result.Linters.Exclusions.Paths, result.Formatters.Exclusions.Paths)
 	})
 
 	t.Run("generated exclusion is set to lax", func(t *testing.T) {
-		result := GetGolangCI()
+		result := GetGolangCI(false)
 
 		assert.Equal(t, "lax", result.Linters.Exclusions.Generated)
 		assert.Equal(t, "lax", result.Formatters.Exclusions.Generated)
 	})
 }
+
+func TestGetGolangCI_yake(t *testing.T) {
+	t.Run("leaves the yake linter out by default", func(t *testing.T) {
+		result := GetGolangCI(false)
+
+		assert.NotContains(t, result.Linters.Enable, YakeLinter)
+		assert.Empty(t, result.Linters.Settings.Custom)
+	})
+
+	t.Run("enables the yake module plugin", func(t *testing.T) {
+		result := GetGolangCI(true)
+
+		assert.Contains(t, result.Linters.Enable, YakeLinter)
+		assert.True(t, slices.IsSorted(result.Linters.Enable))
+		assert.Equal(t, "module", result.Linters.Settings.Custom[YakeLinter].Type)
+	})
+}
+
+func TestGetCustomGCL(t *testing.T) {
+	result := GetCustomGCL()
+
+	assert.NotEmpty(t, result.Version)
+	require.Len(t, result.Plugins, 1)
+	assert.Equal(t, "github.com/vitalvas/yake", result.Plugins[0].Module)
+	assert.Equal(t, "github.com/vitalvas/yake/golangci", result.Plugins[0].Import)
+}
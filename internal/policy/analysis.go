@@ -0,0 +1,325 @@
+package policy
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/vitalvas/yake/internal/config"
+	"golang.org/x/tools/go/analysis"
+)
+
+// analyzerRule is a policy rule that judges each file on its own, so
+// go/analysis drivers can run it package by package.
+type analyzerRule struct {
+	rule string
+	doc  string
+	// tests includes _test.go files, as the policy check does.
+	tests bool
+	find  func(cfg *config.Config, file *sourceFile) []Violation
+}
+
+var analyzerRules = []analyzerRule{
+	{
+		rule: RuleASCIIOnly,
+		doc:  "reports non-ASCII characters in Go source",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findNonASCIIChars(file)
+		},
+	},
+	{
+		rule:  RuleStringConcat,
+		doc:   "reports string concatenation with '+'",
+		tests: true,
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findStringConcatenations(file)
+		},
+	},
+	{
+		rule: RuleStdlibWrappers,
+		doc:  "reports functions that only forward their parameters to a standard library function",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findStdlibWrappers(file)
+		},
+	},
+	{
+		rule: RuleFuncSignature,
+		doc:  "reports functions with too many parameters or results",
+		find: func(cfg *config.Config, file *sourceFile) []Violation {
+			return findFuncSignatureViolations(file, resolveMaxFuncParams(cfg.Policy.FuncSignature), resolveMaxFuncResults(cfg.Policy.FuncSignature))
+		},
+	},
+	{
+		rule: RuleComplexity,
+		doc:  "reports functions over the cyclomatic, cognitive, length or nesting limits",
+		find: func(cfg *config.Config, file *sourceFile) []Violation {
+			return findComplexityViolations(file, resolveComplexityLimits(cfg.Policy.Complexity, file.path))
+		},
+	},
+	{
+		rule:  RuleCompositeLiteral,
+		doc:   "reports composite literals with too many fields on one line",
+		tests: true,
+		find: func(cfg *config.Config, file *sourceFile) []Violation {
+			return findCompositeLiteralViolations(file, resolveMaxSingleLineFields(cfg.Policy.CompositeLiteral))
+		},
+	},
+	{
+		rule: RuleStuttering,
+		doc:  "reports exported names that repeat the package name",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findStutteringViolations(file)
+		},
+	},
+	{
+		rule: RuleGetterNaming,
+		doc:  "reports getters with a Get prefix",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findGetterViolations(file)
+		},
+	},
+	{
+		rule: RulePrivateExportedMethods,
+		doc:  "reports exported methods on private types",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findPrivateExportedMethodViolations(file)
+		},
+	},
+	{
+		rule: RuleNoInit,
+		doc:  "reports init functions",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findInitViolations(file)
+		},
+	},
+	{
+		rule: RuleErrorHandling,
+		doc:  "reports unwrapped, badly styled, misnamed, compared or discarded errors",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findErrorHandlingViolations(file)
+		},
+	},
+	{
+		rule: RuleContext,
+		doc:  "reports misplaced context parameters, stored contexts, new root contexts and calls with a context variant",
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findContextViolations(file)
+		},
+	},
+	{
+		rule: RuleTermination,
+		doc:  "reports os.Exit, log.Fatal, log.Panic and panic outside package main",
+		find: func(cfg *config.Config, file *sourceFile) []Violation {
+			return findTerminationViolations(file, resolveTerminationOptions(cfg.Policy.Termination))
+		},
+	},
+	{
+		rule:  RuleConcurrency,
+		doc:   "reports untracked goroutines, time.Sleep in tests, copied locks, defer in loops and loop variable captures",
+		tests: true,
+		find: func(_ *config.Config, file *sourceFile) []Violation {
+			return findConcurrencyViolations(file, resolveConcurrencyOptions())
+		},
+	},
+	{
+		rule:  RuleTestQuality,
+		doc:   "reports helpers without t.Helper, os.Setenv and t.Skip without a reason in tests",
+		tests: true,
+		find: func(cfg *config.Config, file *sourceFile) []Violation {
+			return findTestQualityViolations(file, resolveTestQualityOptions(cfg.Policy.TestQuality))
+		},
+	},
+}
+
+var sourceFilesType = reflect.TypeFor[[]*sourceFile]()
+
+// Analyzers returns the per-file rules enabled in cfg as go/analysis
+// analyzers named by rule ID. They honour the section settings and scopes,
+// policy.exclude and //yake:ignore directives like "yake policy run", and
+// attach type information only when policy.type_check is set. Violations of
+// string_concat and composite_literal carry their fix as a SuggestedFix;
+// renames span packages and are left to "yake policy fix".
+func Analyzers(cfg *config.Config) []*analysis.Analyzer {
+	checks := make(map[string]golangPolicyCheck)
+	for _, check := range golangPolicyChecks(cfg) {
+		checks[check.rule] = check
+	}
+
+	sources := sourcesAnalyzer(resolveTypeCheck(cfg.Policy))
+
+	var analyzers []*analysis.Analyzer
+
+	for _, rule := range analyzerRules {
+		if !checks[rule.rule].active() {
+			continue
+		}
+
+		analyzers = append(analyzers, &analysis.Analyzer{
+			Name:     rule.rule,
+			Doc:      rule.doc,
+			Requires: []*analysis.Analyzer{sources},
+			Run: func(pass *analysis.Pass) (any, error) {
+				files := pass.ResultOf[sources].([]*sourceFile)
+				reportViolations(pass, cfg, rule, files)
+
+				return nil, nil
+			},
+		})
+	}
+
+	return analyzers
+}
+
+// sourcesAnalyzer wraps the package files as source files once for all rule
+// analyzers. Files outside the working directory, such as cgo output, and
+// generated protobuf files are left out, as the file index does.
+func sourcesAnalyzer(typeCheck bool) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:       "yake_sources",
+		Doc:        "collects the package files for the yake policy analyzers",
+		ResultType: sourceFilesType,
+		Run: func(pass *analysis.Pass) (any, error) {
+			wd, err := os.Getwd()
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			var typed *typedPackage
+			if typeCheck && pass.TypesInfo != nil {
+				typed = &typedPackage{
+					info:       pass.TypesInfo,
+					interfaces: collectInterfaces([]*types.Package{pass.Pkg}, []*types.Info{pass.TypesInfo}),
+				}
+			}
+
+			var files []*sourceFile
+
+			for _, node := range pass.Files {
+				name := pass.Fset.File(node.Pos()).Name()
+
+				path, err := filepath.Rel(wd, name)
+				if err != nil || strings.HasPrefix(path, "..") || strings.HasSuffix(path, ".pb.go") {
+					continue
+				}
+
+				src, err := pass.ReadFile(name)
+				if err != nil {
+					continue
+				}
+
+				file := newSourceFile(pass.Fset, path, src, node, nil)
+				file.typed = typed
+				files = append(files, file)
+			}
+
+			return files, nil
+		},
+	}
+}
+
+// reportViolations runs one rule over the files and reports what survives
+// the scopes and suppressions as diagnostics.
+func reportViolations(pass *analysis.Pass, cfg *config.Config, rule analyzerRule, files []*sourceFile) {
+	for _, file := range files {
+		if file.isTest() && !rule.tests {
+			continue
+		}
+
+		for _, v := range filterScoped(rule.find(cfg, file), cfg.Policy) {
+			if slices.ContainsFunc(file.suppressions, func(s *suppression) bool { return s.matches(v) }) {
+				continue
+			}
+
+			pass.Report(analysis.Diagnostic{
+				Pos:            violationPos(file, v),
+				Category:       v.Rule,
+				Message:        reportMessage(v),
+				SuggestedFixes: suggestedFixes(cfg, file, v),
+			})
+		}
+	}
+}
+
+// violationPos turns the line and column of v back into a position, or the
+// package clause for findings without a line.
+func violationPos(file *sourceFile, v Violation) token.Pos {
+	tf := file.fset.File(file.node.Pos())
+	if v.Line < 1 || v.Line > tf.LineCount() {
+		return file.node.Package
+	}
+
+	pos := tf.LineStart(v.Line)
+	if v.Column > 1 {
+		pos += token.Pos(v.Column - 1)
+	}
+
+	return pos
+}
+
+// suggestedFixes returns the fix of a single violation, reusing the fixers
+// of "yake policy fix" on the violation's file.
+func suggestedFixes(cfg *config.Config, file *sourceFile, v Violation) []analysis.SuggestedFix {
+	single := &fileIndex{
+		fset:  file.fset,
+		files: []*sourceFile{file},
+	}
+
+	flagged := map[violationKey]bool{keyOf(v): true}
+
+	var (
+		fixes   []fix
+		message string
+	)
+
+	switch v.Rule {
+	case RuleStringConcat:
+		fixes = stringConcatFixes(single, flagged)
+		message = "Replace the concatenation with fmt.Sprintf or a single literal"
+	case RuleCompositeLiteral:
+		fixes = compositeLiteralFixes(single, flagged, resolveMaxSingleLineFields(cfg.Policy.CompositeLiteral))
+		message = "Put each element on its own line"
+	}
+
+	suggested := make([]analysis.SuggestedFix, 0, len(fixes))
+
+	for _, f := range fixes {
+		suggested = append(suggested, analysis.SuggestedFix{
+			Message:   message,
+			TextEdits: textEdits(file, f.edits),
+		})
+	}
+
+	return suggested
+}
+
+// textEdits converts byte offset edits to text edits. A required import is
+// added as a declaration of its own right after the package clause, which is
+// valid whatever imports the file already has.
+func textEdits(file *sourceFile, edits []fixEdit) []analysis.TextEdit {
+	tf := file.fset.File(file.node.Pos())
+
+	var textEdits []analysis.TextEdit
+
+	for _, edit := range edits {
+		textEdits = append(textEdits, analysis.TextEdit{
+			Pos:     tf.Pos(edit.start),
+			End:     tf.Pos(edit.end),
+			NewText: []byte(edit.text),
+		})
+
+		if edit.addImport != "" {
+			textEdits = append(textEdits, analysis.TextEdit{
+				Pos:     file.node.Name.End(),
+				End:     file.node.Name.End(),
+				NewText: fmt.Appendf(nil, "\n\nimport %q", edit.addImport),
+			})
+		}
+	}
+
+	return textEdits
+}
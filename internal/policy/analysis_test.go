@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// analyzedDiagnostic is a diagnostic with its resolved position.
+type analyzedDiagnostic struct {
+	location   string
+	diagnostic analysis.Diagnostic
+}
+
+// analyze runs the analyzers for cfg over pkgs and returns the diagnostics
+// by rule.
+func analyze(t *testing.T, cfg *config.Config, pkgs []*packages.Package) map[string][]analyzedDiagnostic {
+	t.Helper()
+
+	graph, err := checker.Analyze(Analyzers(cfg), pkgs, nil)
+	require.NoError(t, err)
+
+	diagnostics := make(map[string][]analyzedDiagnostic)
+
+	for _, action := range graph.Roots {
+		require.NoError(t, action.Err)
+
+		for _, d := range action.Diagnostics {
+			pos := action.Package.Fset.Position(d.Pos)
+			diagnostics[d.Category] = append(diagnostics[d.Category], analyzedDiagnostic{
+				location:   fmt.Sprintf("%s:%d:%d", filepath.Base(pos.Filename), pos.Line, pos.Column),
+				diagnostic: d,
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+func locations(diagnostics []analyzedDiagnostic) []string {
+	var result []string
+	for _, d := range diagnostics {
+		result = append(result, d.location)
+	}
+
+	return result
+}
+
+func TestAnalyzers(t *testing.T) {
+	writeFixProject(t, map[string]string{
+		"app/app.go": `package app
+
+import "os"
+
+type point struct{ X, Y int }
+
+//yake:ignore no_init -- registered on purpose
+func init() {}
+
+func Name() string {
+	var name string = os.Args[0]
+	return "app " + name
+}
+
+func Join(a, b string) string {
+	return a + b
+}
+
+func Points() []point {
+	return []point{{X: 1, Y: 2}}
+}
+`,
+		"app/app_test.go": "package app\n\nvar _ = \"a\" + Name()\n",
+		"gen/gen.go":      "package gen\n\nfunc init() {}\n",
+	})
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadSyntax, Tests: true}, "./...")
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		Policy: config.PolicyConfig{
+			Exclude:          []string{"gen"},
+			CompositeLiteral: &config.CompositeLiteralPolicy{MaxSingleLineFields: intPtr(1)},
+			Stuttering:       &config.PolicyToggle{Enabled: boolPtr(false)},
+		},
+	}
+
+	t.Run("returns analyzers of the enabled per-file rules", func(t *testing.T) {
+		var names []string
+		for _, analyzer := range Analyzers(cfg) {
+			names = append(names, analyzer.Name)
+		}
+
+		assert.Contains(t, names, RuleStringConcat)
+		assert.NotContains(t, names, RuleStuttering)
+		assert.NotContains(t, names, RuleCoverage)
+		require.NoError(t, analysis.Validate(Analyzers(cfg)))
+	})
+
+	diagnostics := analyze(t, cfg, pkgs)
+
+	t.Run("reports violations outside suppressions and excluded paths", func(t *testing.T) {
+		assert.Empty(t, diagnostics[RuleNoInit])
+		assert.Equal(t, []string{"app.go:12:16", "app_test.go:3:13"}, slices.Compact(slices.Sorted(slices.Values(locations(diagnostics[RuleStringConcat])))))
+		assert.Equal(t, "string concatenation with '+' (use fmt.Sprintf or strings.Builder)", diagnostics[RuleStringConcat][0].diagnostic.Message)
+	})
+
+	t.Run("suggests fixes with the required import", func(t *testing.T) {
+		fixes := diagnostics[RuleStringConcat][0].diagnostic.SuggestedFixes
+		require.Len(t, fixes, 1)
+
+		var texts []string
+		for _, edit := range fixes[0].TextEdits {
+			texts = append(texts, string(edit.NewText))
+		}
+
+		assert.ElementsMatch(t, []string{`fmt.Sprintf("app %s", name)`, "\n\nimport \"fmt\""}, texts)
+
+		literal := diagnostics[RuleCompositeLiteral]
+		require.NotEmpty(t, literal)
+		require.Len(t, literal[0].diagnostic.SuggestedFixes, 1)
+		assert.NotEmpty(t, literal[0].diagnostic.SuggestedFixes[0].TextEdits)
+	})
+
+	t.Run("uses type information only when enabled", func(t *testing.T) {
+		cfg.Policy.TypeCheck = boolPtr(true)
+
+		typed := analyze(t, cfg, pkgs)
+
+		assert.Contains(t, locations(typed[RuleStringConcat]), "app.go:16:11")
+	})
+}
+
+func Test_violationPos(t *testing.T) {
+	fset := token.NewFileSet()
+	file := parseSourceFile(fset, "a.go", []byte("package a\n\nvar x = 1\n"))
+
+	assert.Equal(t, file.node.Package, violationPos(file, Violation{}))
+	assert.Equal(t, file.node.Package, violationPos(file, Violation{Line: 10}))
+	assert.Equal(t, "a.go:3:5", fset.Position(violationPos(file, Violation{Line: 3, Column: 5})).String())
+}
@@ -0,0 +1,292 @@
+package policy
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+// BaselineFile is the default location of the recorded violations.
+const BaselineFile = ".yake-baseline.json"
+
+const baselineVersion = 1
+
+// digitsRegex matches numbers in violation messages. They are masked in
+// fingerprints because counts, durations and percentages drift with unrelated
+// edits; metric rules record their value separately instead.
+var digitsRegex = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// baselineMetric reads the measured value out of the messages of a metric
+// rule, so a baselined violation still fails once it gets worse.
+type baselineMetric struct {
+	rule    string
+	pattern *regexp.Regexp
+	// duration values are parsed with time.ParseDuration and compared in
+	// seconds.
+	duration bool
+	// lowerIsWorse marks metrics such as coverage that regress downwards.
+	lowerIsWorse bool
+	// headroom is the fraction a value may worsen by before it counts as a
+	// regression, for timings that vary between runs.
+	headroom float64
+}
+
+var baselineMetrics = []baselineMetric{
+	{rule: RuleCoverage, pattern: regexp.MustCompile(`^(\d+(?:\.\d+)?)% coverage `), lowerIsWorse: true},
+	{rule: RuleCoverage, pattern: regexp.MustCompile(`^coverage dropped to (\d+(?:\.\d+)?)%`), lowerIsWorse: true},
+	{rule: RuleCoverage, pattern: regexp.MustCompile(`\((\d+) lines\) has no test coverage$`)},
+	{rule: RuleTestDuration, pattern: regexp.MustCompile(`took (\S+) \(maximum `), duration: true, headroom: 0.25},
+	{rule: RuleComplexity, pattern: regexp.MustCompile(`(\d+)(?: lines)? \(maximum \d+\)$`)},
+}
+
+// BaselineEntry is one accepted violation. Fingerprint identifies it without
+// relying on line numbers, so entries survive code moving around the file.
+// Value is the measured value of a metric rule, such as the coverage
+// percentage or the test duration in seconds; zero when not recorded.
+type BaselineEntry struct {
+	Rule        string  `json:"rule"`
+	File        string  `json:"file"`
+	Fingerprint string  `json:"fingerprint"`
+	Message     string  `json:"message"`
+	Value       float64 `json:"value,omitempty"`
+}
+
+// Baseline is the set of violations accepted when a project adopted the
+// policies. Only violations missing from it fail a run.
+type Baseline struct {
+	Version    int             `json:"version"`
+	Violations []BaselineEntry `json:"violations"`
+}
+
+// NewBaseline records violations as baseline entries.
+func NewBaseline(violations []Violation) *Baseline {
+	fp := newFingerprinter()
+
+	baseline := &Baseline{
+		Version:    baselineVersion,
+		Violations: make([]BaselineEntry, 0, len(violations)),
+	}
+
+	for _, v := range violations {
+		value, _, _ := metricValue(v)
+
+		baseline.Violations = append(baseline.Violations, BaselineEntry{
+			Rule:        v.Rule,
+			File:        v.File,
+			Fingerprint: fp.fingerprint(v),
+			Message:     v.Message,
+			Value:       value,
+		})
+	}
+
+	slices.SortStableFunc(baseline.Violations, func(a, b BaselineEntry) int {
+		return cmp.Or(
+			cmp.Compare(ruleOrder(a.Rule), ruleOrder(b.Rule)),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Message, b.Message),
+			cmp.Compare(a.Fingerprint, b.Fingerprint),
+		)
+	})
+
+	return baseline
+}
+
+// LoadBaseline reads a baseline file. A missing file is reported with an
+// error wrapping os.ErrNotExist.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+
+	if baseline.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", baseline.Version, path)
+	}
+
+	return &baseline, nil
+}
+
+// Save writes the baseline as indented JSON.
+func (b *Baseline) Save(path string) error {
+	if err := tools.WriteJSONFile(path, b); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	return nil
+}
+
+// Filter splits a run against the baseline. It returns the violations that
+// are not recorded in the baseline and the entries that no longer occur.
+// Identical entries are matched one for one, so a duplicated violation is new
+// even when its first occurrence is accepted. A metric violation that got
+// worse than its recorded value is new as well.
+func (b *Baseline) Filter(violations []Violation) ([]Violation, []BaselineEntry) {
+	recorded := make(map[BaselineEntry][]float64, len(b.Violations))
+
+	for _, entry := range b.Violations {
+		key := baselineKey(entry)
+		recorded[key] = append(recorded[key], entry.Value)
+	}
+
+	fp := newFingerprinter()
+
+	var fresh []Violation
+
+	for _, v := range violations {
+		key := BaselineEntry{
+			Rule:        v.Rule,
+			File:        v.File,
+			Fingerprint: fp.fingerprint(v),
+		}
+
+		values := recorded[key]
+		if len(values) == 0 {
+			fresh = append(fresh, v)
+			continue
+		}
+
+		// Prefer an entry the violation stays within; a regressed one still
+		// consumes its entry so it is not reported as fixed.
+		i := max(slices.IndexFunc(values, func(value float64) bool {
+			return !regressed(v, value)
+		}), 0)
+
+		if regressed(v, values[i]) {
+			fresh = append(fresh, v)
+		}
+
+		recorded[key] = slices.Delete(values, i, i+1)
+	}
+
+	var fixed []BaselineEntry
+
+	for _, entry := range b.Violations {
+		key := baselineKey(entry)
+		if i := slices.Index(recorded[key], entry.Value); i >= 0 {
+			recorded[key] = slices.Delete(recorded[key], i, i+1)
+			fixed = append(fixed, entry)
+		}
+	}
+
+	return fresh, fixed
+}
+
+// metricValue returns the measured value of a metric rule's violation.
+func metricValue(v Violation) (float64, baselineMetric, bool) {
+	for _, metric := range baselineMetrics {
+		if metric.rule != v.Rule {
+			continue
+		}
+
+		match := metric.pattern.FindStringSubmatch(v.Message)
+		if match == nil {
+			continue
+		}
+
+		if metric.duration {
+			elapsed, err := time.ParseDuration(match[1])
+			if err != nil {
+				return 0, metric, false
+			}
+
+			return elapsed.Seconds(), metric, true
+		}
+
+		value, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, metric, false
+		}
+
+		return value, metric, true
+	}
+
+	return 0, baselineMetric{}, false
+}
+
+// regressed reports whether v measures worse than the recorded value. An
+// entry without a recorded value accepts any value.
+func regressed(v Violation, recorded float64) bool {
+	if recorded == 0 {
+		return false
+	}
+
+	value, metric, ok := metricValue(v)
+	if !ok {
+		return false
+	}
+
+	if metric.lowerIsWorse {
+		return value < recorded*(1-metric.headroom)
+	}
+
+	return value > recorded*(1+metric.headroom)
+}
+
+// baselineKey drops the message, which is informational only.
+func baselineKey(entry BaselineEntry) BaselineEntry {
+	return BaselineEntry{
+		Rule:        entry.Rule,
+		File:        entry.File,
+		Fingerprint: entry.Fingerprint,
+	}
+}
+
+// fingerprinter hashes violations from their rule, file, message and the
+// source line they point at, caching file contents across violations.
+type fingerprinter struct {
+	lines map[string][]string
+}
+
+func newFingerprinter() *fingerprinter {
+	return &fingerprinter{lines: make(map[string][]string)}
+}
+
+func (f *fingerprinter) fingerprint(v Violation) string {
+	sum := sha256.New()
+
+	for _, part := range []string{v.Rule, v.File, digitsRegex.ReplaceAllString(v.Message, "N"), f.sourceLine(v)} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(sum.Sum(nil))[:16]
+}
+
+// sourceLine returns the trimmed text of the line a violation points at, or
+// an empty string for package-level violations and unreadable files.
+func (f *fingerprinter) sourceLine(v Violation) string {
+	if v.Line <= 0 {
+		return ""
+	}
+
+	lines, ok := f.lines[v.File]
+	if !ok {
+		if data, err := os.ReadFile(v.File); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+
+		f.lines[v.File] = lines
+	}
+
+	if v.Line > len(lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(lines[v.Line-1])
+}
@@ -0,0 +1,234 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBaseline(t *testing.T) {
+	t.Run("records violations sorted by rule and file", func(t *testing.T) {
+		baseline := NewBaseline([]Violation{
+			{Rule: RuleNoInit, File: "b.go", Line: 3, Message: "init() function is forbidden"},
+			{Rule: RuleEntryPoints, File: "main.go", Line: 1, Message: "unexpected function 'init'"},
+			{Rule: RuleNoInit, File: "a.go", Line: 7, Message: "init() function is forbidden"},
+		})
+
+		assert.Equal(t, baselineVersion, baseline.Version)
+		require.Len(t, baseline.Violations, 3)
+		assert.Equal(t, RuleEntryPoints, baseline.Violations[0].Rule)
+		assert.Equal(t, "a.go", baseline.Violations[1].File)
+		assert.Equal(t, "b.go", baseline.Violations[2].File)
+		assert.Len(t, baseline.Violations[0].Fingerprint, 16)
+	})
+
+	t.Run("records the value of metric rules", func(t *testing.T) {
+		baseline := NewBaseline([]Violation{
+			{Rule: RuleCoverage, File: "internal/db", Message: "45.0% coverage (minimum 80%)"},
+			{Rule: RuleCoverage, File: "internal/db/db.go", Line: 3, Message: "function 'Open2' (40 lines) has no test coverage"},
+			{Rule: RuleTestDuration, File: "internal/db", Message: "tests took 1m30s (maximum 10s)"},
+			{Rule: RuleNoInit, File: "a.go", Line: 7, Message: "init() function is forbidden"},
+		})
+
+		values := make(map[string]float64)
+		for _, entry := range baseline.Violations {
+			values[entry.Message] = entry.Value
+		}
+
+		assert.Equal(t, map[string]float64{
+			"45.0% coverage (minimum 80%)":                     45,
+			"function 'Open2' (40 lines) has no test coverage": 40,
+			"tests took 1m30s (maximum 10s)":                   90,
+			"init() function is forbidden":                     0,
+		}, values)
+	})
+
+	t.Run("records empty baseline", func(t *testing.T) {
+		baseline := NewBaseline(nil)
+
+		assert.NotNil(t, baseline.Violations)
+		assert.Empty(t, baseline.Violations)
+	})
+}
+
+func TestBaselineSaveAndLoad(t *testing.T) {
+	t.Run("round trips entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), BaselineFile)
+
+		baseline := NewBaseline([]Violation{{Rule: RuleCoverage, File: "internal/db", Message: "50.0% coverage (minimum 80%)"}})
+		require.NoError(t, baseline.Save(path))
+
+		loaded, err := LoadBaseline(path)
+		require.NoError(t, err)
+		assert.Equal(t, baseline, loaded)
+	})
+
+	t.Run("returns not exist error for missing file", func(t *testing.T) {
+		_, err := LoadBaseline(filepath.Join(t.TempDir(), BaselineFile))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("returns error for invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), BaselineFile)
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+		_, err := LoadBaseline(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse baseline")
+	})
+
+	t.Run("returns error for unsupported version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), BaselineFile)
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "violations": []}`), 0644))
+
+		_, err := LoadBaseline(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported baseline version 99")
+	})
+
+	t.Run("returns error when file cannot be written", func(t *testing.T) {
+		err := NewBaseline(nil).Save(filepath.Join(t.TempDir(), "missing", BaselineFile))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write baseline")
+	})
+}
+
+func TestBaselineFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	writeSource := func(t *testing.T, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile("main.go", []byte(content), 0644))
+	}
+
+	concat := Violation{Rule: RuleStringConcat, File: "main.go", Line: 4, Message: "string concatenation with '+' operator"}
+
+	t.Run("accepts violations that moved to another line", func(t *testing.T) {
+		writeSource(t, "package main\n\nfunc main() {\n\t_ = \"a\" + name\n}\n")
+		baseline := NewBaseline([]Violation{concat})
+
+		writeSource(t, "package main\n\n// main runs.\n\nfunc main() {\n\t_ = \"a\" + name\n}\n")
+		moved := concat
+		moved.Line = 6
+
+		fresh, fixed := baseline.Filter([]Violation{moved})
+		assert.Empty(t, fresh)
+		assert.Empty(t, fixed)
+	})
+
+	t.Run("reports violations on changed lines as new", func(t *testing.T) {
+		writeSource(t, "package main\n\nfunc main() {\n\t_ = \"a\" + name\n}\n")
+		baseline := NewBaseline([]Violation{concat})
+
+		writeSource(t, "package main\n\nfunc main() {\n\t_ = \"b\" + name\n}\n")
+
+		fresh, fixed := baseline.Filter([]Violation{concat})
+		assert.Equal(t, []Violation{concat}, fresh)
+		require.Len(t, fixed, 1)
+		assert.Equal(t, "main.go", fixed[0].File)
+	})
+
+	t.Run("reports fixed entries", func(t *testing.T) {
+		writeSource(t, "package main\n\nfunc main() {\n\t_ = \"a\" + name\n}\n")
+		baseline := NewBaseline([]Violation{concat})
+
+		fresh, fixed := baseline.Filter(nil)
+		assert.Empty(t, fresh)
+		assert.Equal(t, baseline.Violations, fixed)
+	})
+
+	t.Run("matches duplicates one for one", func(t *testing.T) {
+		writeSource(t, "package main\n\nfunc main() {\n\t_ = \"a\" + name\n\t_ = \"a\" + name\n}\n")
+		baseline := NewBaseline([]Violation{concat})
+
+		second := concat
+		second.Line = 5
+
+		fresh, fixed := baseline.Filter([]Violation{concat, second})
+		assert.Equal(t, []Violation{second}, fresh)
+		assert.Empty(t, fixed)
+	})
+
+	t.Run("ignores numbers in package-level messages", func(t *testing.T) {
+		coverage := Violation{Rule: RuleCoverage, File: "internal/db", Message: "50.0% coverage (minimum 80%)"}
+		baseline := NewBaseline([]Violation{coverage})
+
+		coverage.Message = "62.5% coverage (minimum 80%)"
+
+		fresh, fixed := baseline.Filter([]Violation{coverage})
+		assert.Empty(t, fresh)
+		assert.Empty(t, fixed)
+	})
+
+	t.Run("reports metric violations that got worse", func(t *testing.T) {
+		coverage := Violation{Rule: RuleCoverage, File: "internal/db", Message: "50.0% coverage (minimum 80%)"}
+		slow := Violation{Rule: RuleTestDuration, File: "internal/db/db_test.go", Line: 9, Message: "test 'TestMigrate' took 2s (maximum 1s)"}
+		tangled := Violation{Rule: RuleComplexity, File: "a.go", Message: "function 'run' has cyclomatic complexity 35 (maximum 30)"}
+		baseline := NewBaseline([]Violation{coverage, slow, tangled})
+
+		coverage.Message = "0.0% coverage (minimum 80%)"
+		slow.Message = "test 'TestMigrate' took 20s (maximum 1s)"
+		tangled.Message = "function 'run' has cyclomatic complexity 36 (maximum 30)"
+
+		fresh, fixed := baseline.Filter([]Violation{coverage, slow, tangled})
+		assert.Equal(t, []Violation{coverage, slow, tangled}, fresh)
+		assert.Empty(t, fixed)
+	})
+
+	t.Run("tolerates timings within the headroom", func(t *testing.T) {
+		slow := Violation{Rule: RuleTestDuration, File: "internal/db", Message: "tests took 12s (maximum 10s)"}
+		baseline := NewBaseline([]Violation{slow})
+
+		slow.Message = "tests took 14.5s (maximum 10s)"
+
+		fresh, fixed := baseline.Filter([]Violation{slow})
+		assert.Empty(t, fresh)
+		assert.Empty(t, fixed)
+	})
+
+	t.Run("does not match other files", func(t *testing.T) {
+		baseline := NewBaseline([]Violation{{Rule: RuleNoInit, File: "a.go", Message: "init() function is forbidden"}})
+		other := Violation{Rule: RuleNoInit, File: "b.go", Message: "init() function is forbidden"}
+
+		fresh, fixed := baseline.Filter([]Violation{other})
+		assert.Equal(t, []Violation{other}, fresh)
+		assert.Len(t, fixed, 1)
+	})
+}
+
+func Test_fingerprinter(t *testing.T) {
+	t.Run("uses empty line for unreadable files", func(t *testing.T) {
+		fp := newFingerprinter()
+
+		assert.Equal(t, "", fp.sourceLine(Violation{File: "/non/existent/file.go", Line: 3}))
+		assert.Contains(t, fp.lines, "/non/existent/file.go")
+	})
+
+	t.Run("uses empty line beyond end of file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "main.go")
+		require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+
+		assert.Equal(t, "", newFingerprinter().sourceLine(Violation{File: path, Line: 10}))
+	})
+
+	t.Run("trims the source line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "main.go")
+		require.NoError(t, os.WriteFile(path, []byte("package main\n\n\tvar x = 1  \n"), 0644))
+
+		assert.Equal(t, "var x = 1", newFingerprinter().sourceLine(Violation{File: path, Line: 3}))
+	})
+
+	t.Run("is stable for equal input", func(t *testing.T) {
+		v := Violation{Rule: RuleNoInit, File: "a.go", Message: "init() function is forbidden"}
+
+		assert.Equal(t, newFingerprinter().fingerprint(v), newFingerprinter().fingerprint(v))
+	})
+}
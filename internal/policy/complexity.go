@@ -0,0 +1,383 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"log"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+// complexityLimits are the limits that apply to one file; 0 turns a metric
+// off.
+type complexityLimits struct {
+	cyclomatic int
+	cognitive  int
+	funcLines  int
+	nesting    int
+}
+
+func (l complexityLimits) with(c config.ComplexityLimits) complexityLimits {
+	if c.MaxCyclomatic != nil {
+		l.cyclomatic = *c.MaxCyclomatic
+	}
+
+	if c.MaxCognitive != nil {
+		l.cognitive = *c.MaxCognitive
+	}
+
+	if c.MaxFuncLines != nil {
+		l.funcLines = *c.MaxFuncLines
+	}
+
+	if c.MaxNesting != nil {
+		l.nesting = *c.MaxNesting
+	}
+
+	return l
+}
+
+// resolveComplexityLimits returns the limits for the file at path: the
+// defaults, then the section limits, then the first override matching path.
+func resolveComplexityLimits(p *config.ComplexityPolicy, path string) complexityLimits {
+	limits := complexityLimits{
+		cyclomatic: config.DefaultMaxCyclomatic,
+		cognitive:  config.DefaultMaxCognitive,
+		funcLines:  config.DefaultMaxFuncLines,
+		nesting:    config.DefaultMaxNesting,
+	}
+
+	if p == nil {
+		return limits
+	}
+
+	limits = limits.with(p.ComplexityLimits)
+
+	for _, override := range p.Overrides {
+		if matchAny(override.Paths, path) {
+			return limits.with(override.ComplexityLimits)
+		}
+	}
+
+	return limits
+}
+
+func checkComplexity(idx *fileIndex, p *config.ComplexityPolicy) ([]Violation, error) {
+	log.Println("Checking function complexity...")
+
+	violations := idx.collect(idx.sources(), func(file *sourceFile) []Violation {
+		return findComplexityViolations(file, resolveComplexityLimits(p, file.path))
+	})
+
+	return violations, nil
+}
+
+func findComplexityViolations(file *sourceFile, limits complexityLimits) []Violation {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip {
+		return nil
+	}
+
+	var violations []Violation
+
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || hasFuncSkipDirective(fn) {
+			continue
+		}
+
+		metrics := []struct {
+			limit      int
+			value      int
+			message    string
+			suggestion string
+		}{
+			{
+				limit:      limits.cyclomatic,
+				value:      cyclomaticComplexity(fn.Body),
+				message:    "cyclomatic complexity %d",
+				suggestion: "split the function into smaller functions",
+			},
+			{
+				limit:      limits.cognitive,
+				value:      cognitiveComplexity(fn),
+				message:    "cognitive complexity %d",
+				suggestion: "split the function or flatten its control flow",
+			},
+			{
+				limit:      limits.funcLines,
+				value:      countCodeLines(file.fset, file.src, fn.Body.Lbrace, fn.Body.Rbrace),
+				message:    "%d lines",
+				suggestion: "split the function into smaller functions",
+			},
+			{
+				limit:      limits.nesting,
+				value:      nestingDepth(fn.Body),
+				message:    "nesting depth %d",
+				suggestion: "return early or move nested blocks into functions",
+			},
+		}
+
+		pos := file.fset.Position(fn.Pos())
+
+		for _, metric := range metrics {
+			if metric.limit <= 0 || metric.value <= metric.limit {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Rule:       RuleComplexity,
+				File:       file.path,
+				Line:       pos.Line,
+				Column:     pos.Column,
+				Severity:   SeverityError,
+				Message:    fmt.Sprintf("function '%s' has %s (maximum %d)", fn.Name.Name, fmt.Sprintf(metric.message, metric.value), metric.limit),
+				Suggestion: metric.suggestion,
+			})
+		}
+	}
+
+	return violations
+}
+
+// cyclomaticComplexity counts the independent paths through body: one plus
+// each branch point and each && or || operator, as gocyclo does.
+func cyclomaticComplexity(body *ast.BlockStmt) int {
+	complexity := 1
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+
+		return true
+	})
+
+	return complexity
+}
+
+// cognitiveComplexity scores how hard fn is to follow, after the cognitive
+// complexity specification: every break in the linear flow adds one, plus its
+// nesting level for structures that nest; each run of like logical operators,
+// labelled jumps and direct recursion add one.
+func cognitiveComplexity(fn *ast.FuncDecl) int {
+	c := &cognitiveCounter{
+		elseIfs:  make(map[*ast.IfStmt]bool),
+		logicals: make(map[*ast.BinaryExpr]bool),
+	}
+
+	if fn.Recv == nil {
+		c.name = fn.Name.Name
+	}
+
+	ast.Walk(c, fn.Body)
+
+	return c.complexity
+}
+
+type cognitiveCounter struct {
+	// name is the function name for recursive calls; empty for methods.
+	name       string
+	complexity int
+	nesting    int
+	elseIfs    map[*ast.IfStmt]bool
+	logicals   map[*ast.BinaryExpr]bool
+}
+
+func (c *cognitiveCounter) Visit(n ast.Node) ast.Visitor {
+	switch n := n.(type) {
+	case *ast.IfStmt:
+		c.visitIf(n)
+		return nil
+	case *ast.ForStmt:
+		c.structure(n.Body, n.Init, n.Cond, n.Post)
+		return nil
+	case *ast.RangeStmt:
+		c.structure(n.Body, n.Key, n.Value, n.X)
+		return nil
+	case *ast.SwitchStmt:
+		c.structure(n.Body, n.Init, n.Tag)
+		return nil
+	case *ast.TypeSwitchStmt:
+		c.structure(n.Body, n.Init, n.Assign)
+		return nil
+	case *ast.SelectStmt:
+		c.structure(n.Body)
+		return nil
+	case *ast.FuncLit:
+		c.nested(n.Body)
+		return nil
+	case *ast.BranchStmt:
+		if n.Label != nil || n.Tok == token.GOTO {
+			c.complexity++
+		}
+	case *ast.BinaryExpr:
+		c.visitLogical(n)
+	case *ast.CallExpr:
+		if ident, ok := n.Fun.(*ast.Ident); ok && c.name != "" && ident.Name == c.name {
+			c.complexity++
+		}
+	}
+
+	return c
+}
+
+func (c *cognitiveCounter) visitIf(n *ast.IfStmt) {
+	if c.elseIfs[n] {
+		c.complexity++
+	} else {
+		c.complexity += 1 + c.nesting
+	}
+
+	c.walk(n.Init, n.Cond)
+	c.nested(n.Body)
+
+	switch els := n.Else.(type) {
+	case *ast.IfStmt:
+		c.elseIfs[els] = true
+		ast.Walk(c, els)
+	case *ast.BlockStmt:
+		c.complexity++
+		c.nested(els)
+	}
+}
+
+// structure scores a nesting structure, walking its header at the current
+// level and its body one level deeper.
+func (c *cognitiveCounter) structure(body *ast.BlockStmt, header ...ast.Node) {
+	c.complexity += 1 + c.nesting
+
+	c.walk(header...)
+	c.nested(body)
+}
+
+func (c *cognitiveCounter) nested(body *ast.BlockStmt) {
+	c.nesting++
+	c.walk(body)
+	c.nesting--
+}
+
+func (c *cognitiveCounter) walk(nodes ...ast.Node) {
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+
+		ast.Walk(c, n)
+	}
+}
+
+// visitLogical adds one per run of like operators in the outermost && / ||
+// expression, so "a && b && c" scores 1 and "a && b || c" scores 2.
+func (c *cognitiveCounter) visitLogical(n *ast.BinaryExpr) {
+	if c.logicals[n] || (n.Op != token.LAND && n.Op != token.LOR) {
+		return
+	}
+
+	var last token.Token
+
+	for _, op := range c.logicalOps(n) {
+		if op != last {
+			c.complexity++
+		}
+
+		last = op
+	}
+}
+
+// logicalOps lists the operators of a logical expression from left to right,
+// marking the nested expressions as counted.
+func (c *cognitiveCounter) logicalOps(expr ast.Expr) []token.Token {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return c.logicalOps(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.LAND && e.Op != token.LOR {
+			return nil
+		}
+
+		c.logicals[e] = true
+
+		ops := c.logicalOps(e.X)
+		ops = append(ops, e.Op)
+
+		return append(ops, c.logicalOps(e.Y)...)
+	default:
+		return nil
+	}
+}
+
+// nestingDepth returns the deepest nesting of control structures in body;
+// an else-if chain stays at the level of its first if.
+func nestingDepth(body *ast.BlockStmt) int {
+	c := &nestingCounter{}
+	ast.Walk(c, body)
+
+	return c.max
+}
+
+type nestingCounter struct {
+	depth int
+	max   int
+}
+
+func (c *nestingCounter) Visit(n ast.Node) ast.Visitor {
+	switch n := n.(type) {
+	case *ast.IfStmt:
+		c.enter(n.Body)
+
+		if n.Else != nil {
+			if _, ok := n.Else.(*ast.IfStmt); ok {
+				ast.Walk(c, n.Else)
+			} else {
+				c.enter(n.Else)
+			}
+		}
+
+		return nil
+	case *ast.ForStmt:
+		c.enter(n.Body)
+		return nil
+	case *ast.RangeStmt:
+		c.enter(n.Body)
+		return nil
+	case *ast.SwitchStmt:
+		c.enter(n.Body)
+		return nil
+	case *ast.TypeSwitchStmt:
+		c.enter(n.Body)
+		return nil
+	case *ast.SelectStmt:
+		c.enter(n.Body)
+		return nil
+	}
+
+	return c
+}
+
+func (c *nestingCounter) enter(body ast.Node) {
+	c.depth++
+	c.max = max(c.max, c.depth)
+
+	ast.Walk(c, body)
+
+	c.depth--
+}
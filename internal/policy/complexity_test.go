@@ -0,0 +1,185 @@
+package policy
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+)
+
+const complexitySource = `package calc
+
+func sumOfPrimes(max int) int {
+	total := 0
+OUT:
+	for i := 1; i <= max; i++ {
+		for j := 2; j < i; j++ {
+			if i%j == 0 {
+				continue OUT
+			}
+		}
+		total += i
+	}
+	return total
+}
+
+func branches(a, b, c bool) {
+	if a && b || c {
+		return
+	} else if a {
+		_ = b
+	} else {
+		_ = c
+	}
+
+	go func() {
+		if b {
+			_ = a
+		}
+	}()
+
+	branches(a, b, c)
+}
+
+func kind(v any) string {
+	switch v.(type) {
+	case int:
+		return "int"
+	case string:
+		return "string"
+	default:
+		return "other"
+	}
+}
+`
+
+func complexityFuncs(t *testing.T) map[string]*ast.FuncDecl {
+	t.Helper()
+
+	file := parseSourceFile(token.NewFileSet(), "calc.go", []byte(complexitySource))
+	require.NoError(t, file.parseErr)
+
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.node.Decls {
+		fn := decl.(*ast.FuncDecl)
+		funcs[fn.Name.Name] = fn
+	}
+
+	return funcs
+}
+
+func Test_complexityMetrics(t *testing.T) {
+	funcs := complexityFuncs(t)
+
+	tests := []struct {
+		name       string
+		cyclomatic int
+		cognitive  int
+		nesting    int
+	}{
+		{"sumOfPrimes", 4, 7, 3},
+		{"branches", 6, 8, 1},
+		{"kind", 3, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := funcs[tt.name]
+
+			assert.Equal(t, tt.cyclomatic, cyclomaticComplexity(fn.Body), "cyclomatic")
+			assert.Equal(t, tt.cognitive, cognitiveComplexity(fn), "cognitive")
+			assert.Equal(t, tt.nesting, nestingDepth(fn.Body), "nesting")
+		})
+	}
+}
+
+func Test_resolveComplexityLimits(t *testing.T) {
+	t.Run("uses defaults without a section", func(t *testing.T) {
+		assert.Equal(t, complexityLimits{
+			cyclomatic: config.DefaultMaxCyclomatic,
+			cognitive:  config.DefaultMaxCognitive,
+			funcLines:  config.DefaultMaxFuncLines,
+			nesting:    config.DefaultMaxNesting,
+		}, resolveComplexityLimits(nil, "a.go"))
+	})
+
+	p := &config.ComplexityPolicy{
+		ComplexityLimits: config.ComplexityLimits{
+			MaxCyclomatic: intPtr(10),
+			MaxNesting:    intPtr(3),
+		},
+		Overrides: []config.ComplexityOverride{
+			{
+				Paths:            []string{"internal/legacy/**"},
+				ComplexityLimits: config.ComplexityLimits{MaxCyclomatic: intPtr(40)},
+			},
+			{
+				Paths:            []string{"internal/**"},
+				ComplexityLimits: config.ComplexityLimits{MaxCyclomatic: intPtr(20)},
+			},
+		},
+	}
+
+	t.Run("applies section limits", func(t *testing.T) {
+		limits := resolveComplexityLimits(p, "cmd/app/main.go")
+
+		assert.Equal(t, 10, limits.cyclomatic)
+		assert.Equal(t, 3, limits.nesting)
+		assert.Equal(t, config.DefaultMaxCognitive, limits.cognitive)
+	})
+
+	t.Run("applies the first matching override", func(t *testing.T) {
+		limits := resolveComplexityLimits(p, "internal/legacy/old.go")
+
+		assert.Equal(t, 40, limits.cyclomatic)
+		assert.Equal(t, 3, limits.nesting)
+		assert.Equal(t, 20, resolveComplexityLimits(p, "internal/app/app.go").cyclomatic)
+	})
+}
+
+func Test_checkComplexity(t *testing.T) {
+	writeFixProject(t, map[string]string{
+		"calc/calc.go":      complexitySource,
+		"calc/calc_test.go": complexitySource,
+	})
+
+	t.Run("reports each exceeded limit with the function name", func(t *testing.T) {
+		violations, err := checkComplexity(testFileIndex(t), &config.ComplexityPolicy{
+			ComplexityLimits: config.ComplexityLimits{
+				MaxCyclomatic: intPtr(5),
+				MaxCognitive:  intPtr(7),
+				MaxFuncLines:  intPtr(0),
+				MaxNesting:    intPtr(2),
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"calc/calc.go:3:1: function 'sumOfPrimes' has nesting depth 3 (maximum 2)",
+			"calc/calc.go:17:1: function 'branches' has cyclomatic complexity 6 (maximum 5)",
+			"calc/calc.go:17:1: function 'branches' has cognitive complexity 8 (maximum 7)",
+		}, violationStrings(violations))
+		assert.Equal(t, RuleComplexity, violations[0].Rule)
+		assert.Equal(t, "return early or move nested blocks into functions", violations[0].Suggestion)
+	})
+
+	t.Run("limits function length", func(t *testing.T) {
+		violations, err := checkComplexity(testFileIndex(t), &config.ComplexityPolicy{
+			ComplexityLimits: config.ComplexityLimits{MaxFuncLines: intPtr(12)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"calc/calc.go:17:1: function 'branches' has 13 lines (maximum 12)",
+		}, violationStrings(violations))
+	})
+
+	t.Run("passes with default limits", func(t *testing.T) {
+		violations, err := checkComplexity(testFileIndex(t), nil)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+}
@@ -0,0 +1,405 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// lockTypes are the sync types that must not be copied after first use.
+var lockTypes = []string{"Mutex", "RWMutex", "WaitGroup", "Once", "Cond", "Map", "Pool"}
+
+// concurrencyOptions holds what the concurrency check needs beyond the file.
+type concurrencyOptions struct {
+	// sharedLoopVars is set for modules before Go 1.22, where all iterations
+	// of a loop share its variables.
+	sharedLoopVars bool
+}
+
+// resolveConcurrencyOptions reads the Go version from go.mod. Without one the
+// per-iteration loop variables of current Go versions are assumed.
+func resolveConcurrencyOptions() concurrencyOptions {
+	data, err := os.ReadFile("go.mod")
+	if err != nil {
+		return concurrencyOptions{}
+	}
+
+	goVersion := parseGoVersion(string(data))
+
+	return concurrencyOptions{
+		sharedLoopVars: goVersion != "" && version.Compare(fmt.Sprintf("go%s", goVersion), "go1.22") < 0,
+	}
+}
+
+// parseGoVersion returns the version of the go directive of a go.mod file.
+func parseGoVersion(goModContent string) string {
+	for line := range strings.Lines(goModContent) {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "go "); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+
+	return ""
+}
+
+func checkConcurrency(idx *fileIndex) ([]Violation, error) {
+	log.Println("Checking goroutines and concurrency...")
+
+	opts := resolveConcurrencyOptions()
+
+	violations := idx.collect(idx.files, func(file *sourceFile) []Violation {
+		return findConcurrencyViolations(file, opts)
+	})
+
+	return violations, nil
+}
+
+// findConcurrencyViolations reports goroutines nothing waits for or stops,
+// time.Sleep in tests, value receivers copying a lock, defer inside loops
+// and, before Go 1.22, loop variables captured by goroutines and deferred
+// functions.
+func findConcurrencyViolations(file *sourceFile, opts concurrencyOptions) []Violation {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip {
+		return nil
+	}
+
+	c := &concurrencyChecker{
+		file: file,
+		sync: importedName(node, "sync"),
+		time: importedName(node, "time"),
+		opts: opts,
+	}
+
+	c.locks = c.lockFields(node)
+
+	for _, decl := range node.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			if hasFuncSkipDirective(fn) {
+				continue
+			}
+
+			c.checkReceiver(fn)
+		}
+
+		if gd, ok := decl.(*ast.GenDecl); ok && hasGenDeclSkipDirective(gd) {
+			continue
+		}
+
+		c.stack = c.stack[:0]
+		ast.Inspect(decl, c.visit)
+	}
+
+	return c.violations
+}
+
+type concurrencyChecker struct {
+	file *sourceFile
+	// sync and time are the names of the imported packages, if any.
+	sync string
+	time string
+	opts concurrencyOptions
+	// locks maps the struct types of the file to the lock they hold by value.
+	locks map[string]string
+	// stack holds the nodes enclosing the one being visited.
+	stack      []ast.Node
+	violations []Violation
+}
+
+func (c *concurrencyChecker) report(n ast.Node, message, suggestion string) {
+	pos := c.file.fset.Position(n.Pos())
+	c.violations = append(c.violations, Violation{
+		Rule:       RuleConcurrency,
+		File:       c.file.path,
+		Line:       pos.Line,
+		Column:     pos.Column,
+		Severity:   SeverityError,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+func (c *concurrencyChecker) visit(n ast.Node) bool {
+	if n == nil {
+		c.stack = c.stack[:len(c.stack)-1]
+		return true
+	}
+
+	switch n := n.(type) {
+	case *ast.GoStmt:
+		if !c.file.isTest() && !c.isTracked(n.Call) {
+			c.report(n, "goroutine started without a WaitGroup, errgroup, context or channel to wait for or stop it", "track the goroutine with a sync.WaitGroup or errgroup.Group, or stop it through a ctx")
+		}
+
+		c.checkCapture(n.Call, "goroutine")
+	case *ast.DeferStmt:
+		if c.inLoop() {
+			c.report(n, "defer inside a loop runs only when the function returns", "move the loop body into a function or release the resource explicitly")
+		}
+
+		c.checkCapture(n.Call, "deferred function")
+	case *ast.CallExpr:
+		if c.file.isTest() && isCall(n, c.time, "Sleep") {
+			c.report(n, fmt.Sprintf("%s in a test synchronizes by timing", types.ExprString(n.Fun)), "wait on a channel or WaitGroup, or poll with assert.Eventually")
+		}
+	}
+
+	c.stack = append(c.stack, n)
+
+	return true
+}
+
+// inLoop reports whether the visited node runs once per iteration of a loop
+// of its own function.
+func (c *concurrencyChecker) inLoop() bool {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		switch c.stack[i].(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return true
+		case *ast.FuncLit, *ast.FuncDecl:
+			return false
+		}
+	}
+
+	return false
+}
+
+// loopVars returns the variables declared by the loops of the visited
+// node's function, by name.
+func (c *concurrencyChecker) loopVars() map[string]*ast.Ident {
+	vars := make(map[string]*ast.Ident)
+
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		var idents []ast.Expr
+
+		switch loop := c.stack[i].(type) {
+		case *ast.RangeStmt:
+			if loop.Tok == token.DEFINE {
+				idents = []ast.Expr{loop.Key, loop.Value}
+			}
+		case *ast.ForStmt:
+			if init, ok := loop.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				idents = init.Lhs
+			}
+		case *ast.FuncLit, *ast.FuncDecl:
+			return vars
+		}
+
+		for _, expr := range idents {
+			if ident, ok := expr.(*ast.Ident); ok && ident.Name != "_" {
+				if _, shadowed := vars[ident.Name]; !shadowed {
+					vars[ident.Name] = ident
+				}
+			}
+		}
+	}
+
+	return vars
+}
+
+// refersTo reports whether ident uses the loop variable decl. Without type
+// information any identifier of the same name does.
+func (c *concurrencyChecker) refersTo(ident, decl *ast.Ident) bool {
+	if ident.Name != decl.Name {
+		return false
+	}
+
+	if c.file.typed != nil {
+		obj := c.file.typed.info.Uses[ident]
+		return obj != nil && obj == c.file.typed.info.Defs[decl]
+	}
+
+	return true
+}
+
+// checkCapture reports loop variables used by a function literal started as
+// a goroutine or deferred, for Go versions sharing them across iterations.
+func (c *concurrencyChecker) checkCapture(call *ast.CallExpr, kind string) {
+	lit, ok := call.Fun.(*ast.FuncLit)
+	if !ok || !c.opts.sharedLoopVars {
+		return
+	}
+
+	vars := c.loopVars()
+	reported := make(map[string]bool)
+
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || reported[ident.Name] {
+			return true
+		}
+
+		if decl, ok := vars[ident.Name]; ok && c.refersTo(ident, decl) {
+			reported[ident.Name] = true
+			c.report(ident, fmt.Sprintf("%s captures loop variable '%s'", kind, ident.Name), fmt.Sprintf("pass '%s' as an argument or copy it inside the loop", ident.Name))
+		}
+
+		return true
+	})
+}
+
+// isTracked reports whether a goroutine refers to something that waits for
+// or stops it: a WaitGroup, an errgroup, a context or a channel it signals
+// on. Without type information contexts are recognized by the ctx name and
+// the others by Done and Add calls, channel sends and close.
+func (c *concurrencyChecker) isTracked(call *ast.CallExpr) bool {
+	tracked := false
+
+	ast.Inspect(call, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SendStmt:
+			tracked = true
+		case *ast.Ident:
+			tracked = tracked || c.isTrackingValue(n)
+		case *ast.CallExpr:
+			if ident, ok := n.Fun.(*ast.Ident); ok && ident.Name == "close" {
+				tracked = true
+			}
+
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && c.file.typed == nil {
+				tracked = tracked || sel.Sel.Name == "Done" || sel.Sel.Name == "Add"
+			}
+		}
+
+		return !tracked
+	})
+
+	return tracked
+}
+
+func (c *concurrencyChecker) isTrackingValue(ident *ast.Ident) bool {
+	if c.file.typed == nil {
+		return ident.Name == "ctx"
+	}
+
+	obj, ok := c.file.typed.info.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+
+	typ := obj.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+
+	if _, ok := typ.Underlying().(*types.Chan); ok {
+		return true
+	}
+
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+
+	switch fmt.Sprintf("%s.%s", named.Obj().Pkg().Path(), named.Obj().Name()) {
+	case "context.Context", "sync.WaitGroup", "golang.org/x/sync/errgroup.Group":
+		return true
+	}
+
+	return false
+}
+
+// lockFields maps the struct types declared in the file to the first sync
+// lock they hold by value.
+func (c *concurrencyChecker) lockFields(node *ast.File) map[string]string {
+	locks := make(map[string]string)
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		for _, field := range st.Fields.List {
+			if sel, ok := field.Type.(*ast.SelectorExpr); ok && isPackageSelector(sel, c.sync) && slices.Contains(lockTypes, sel.Sel.Name) {
+				locks[spec.Name.Name] = types.ExprString(sel)
+				break
+			}
+		}
+
+		return true
+	})
+
+	return locks
+}
+
+// isPackageSelector reports whether sel selects from the package imported as pkg.
+func isPackageSelector(sel *ast.SelectorExpr, pkg string) bool {
+	ident, ok := sel.X.(*ast.Ident)
+
+	return ok && pkg != "" && ident.Name == pkg
+}
+
+// checkReceiver reports methods with a value receiver on a type holding a
+// lock, which copy the lock on every call. With type information locks are
+// found in any struct type, nested fields included; without it only in the
+// struct types of the same file.
+func (c *concurrencyChecker) checkReceiver(fn *ast.FuncDecl) {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return
+	}
+
+	expr := fn.Recv.List[0].Type
+	if index, ok := expr.(*ast.IndexExpr); ok {
+		expr = index.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	lock := c.locks[ident.Name]
+	if c.file.typed != nil {
+		lock = ""
+		if tv, ok := c.file.typed.info.Types[fn.Recv.List[0].Type]; ok {
+			lock = heldLock(tv.Type, nil)
+		}
+	}
+
+	if lock != "" {
+		c.report(fn.Recv.List[0], fmt.Sprintf("method '%s' has a value receiver and copies the %s in '%s'", fn.Name.Name, lock, ident.Name), "use a pointer receiver")
+	}
+}
+
+// heldLock returns the sync lock typ holds by value, directly or in nested
+// structs and arrays, or an empty string.
+func heldLock(typ types.Type, seen []types.Type) string {
+	if slices.ContainsFunc(seen, func(t types.Type) bool { return types.Identical(t, typ) }) {
+		return ""
+	}
+
+	seen = append(seen, typ)
+
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "sync" && slices.Contains(lockTypes, named.Obj().Name()) {
+		return fmt.Sprintf("sync.%s", named.Obj().Name())
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Struct:
+		for field := range u.Fields() {
+			if lock := heldLock(field.Type(), seen); lock != "" {
+				return lock
+			}
+		}
+	case *types.Array:
+		return heldLock(u.Elem(), seen)
+	}
+
+	return ""
+}
@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"go/token"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const concurrencySource = `package worker
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+type Pool struct {
+	mu    sync.Mutex
+	items []string
+}
+
+func (p Pool) Len() int { return len(p.items) }
+
+func (p *Pool) Add(item string) { p.items = append(p.items, item) }
+
+func Start(ctx context.Context, items []string, results chan<- string) {
+	var wg sync.WaitGroup
+
+	for _, item := range items {
+		item := item
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- item
+		}()
+
+		go func() {
+			os.Remove(item)
+		}()
+
+		go watch(ctx)
+	}
+
+	for i := 0; i < len(items); i++ {
+		f, _ := os.Open(items[i])
+		defer f.Close()
+
+		defer func() {
+			os.Remove(items[i])
+		}()
+	}
+
+	wg.Wait()
+}
+
+func watch(ctx context.Context) { <-ctx.Done() }
+`
+
+func Test_findConcurrencyViolations(t *testing.T) {
+	file := parseSourceFile(token.NewFileSet(), "worker.go", []byte(concurrencySource))
+	require.NoError(t, file.parseErr)
+
+	t.Run("reports concurrency mistakes without type information", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"worker.go:14:7: method 'Len' has a value receiver and copies the sync.Mutex in 'Pool'",
+			"worker.go:30:3: goroutine started without a WaitGroup, errgroup, context or channel to wait for or stop it",
+			"worker.go:39:3: defer inside a loop runs only when the function returns",
+			"worker.go:41:3: defer inside a loop runs only when the function returns",
+		}, violationStrings(findConcurrencyViolations(file, concurrencyOptions{})))
+	})
+
+	t.Run("reports loop variable captures before go 1.22", func(t *testing.T) {
+		violations := violationStrings(findConcurrencyViolations(file, concurrencyOptions{sharedLoopVars: true}))
+
+		assert.Contains(t, violations, "worker.go:27:15: goroutine captures loop variable 'item'")
+		assert.Contains(t, violations, "worker.go:31:14: goroutine captures loop variable 'item'")
+		assert.Contains(t, violations, "worker.go:42:20: deferred function captures loop variable 'i'")
+	})
+
+	t.Run("reports time.Sleep in tests", func(t *testing.T) {
+		test := parseSourceFile(token.NewFileSet(), "worker_test.go", []byte("package worker\n\nimport \"time\"\n\nfunc wait() {\n\tgo wait()\n\ttime.Sleep(time.Second)\n}\n"))
+		require.NoError(t, test.parseErr)
+
+		assert.Equal(t, []string{
+			"worker_test.go:7:2: time.Sleep in a test synchronizes by timing",
+		}, violationStrings(findConcurrencyViolations(test, concurrencyOptions{})))
+	})
+
+	t.Run("uses types for locks and trackers", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"worker/worker.go": `package worker
+
+import (
+	"sync"
+
+	"example.com/fx/state"
+)
+
+type Cache struct {
+	state state.State
+}
+
+func (c Cache) Get() int { return 0 }
+
+type Runner struct {
+	wg sync.WaitGroup
+}
+
+func (r *Runner) Run(ctx string, done chan struct{}) {
+	r.wg.Add(1)
+	go func() { r.wg.Done() }()
+	go func() { close(done) }()
+	go func() { _ = ctx }()
+}
+`,
+			"state/state.go": "package state\n\nimport \"sync\"\n\ntype State struct {\n\tlocks [2]sync.RWMutex\n}\n",
+		})
+
+		files := loadTypedFiles(t, nil)
+		require.NotNil(t, files["worker/worker.go"].typed)
+
+		assert.Equal(t, []string{
+			"worker/worker.go:13:7: method 'Get' has a value receiver and copies the sync.RWMutex in 'Cache'",
+			"worker/worker.go:23:2: goroutine started without a WaitGroup, errgroup, context or channel to wait for or stop it",
+		}, violationStrings(findConcurrencyViolations(files["worker/worker.go"], concurrencyOptions{})))
+	})
+}
+
+func Test_resolveConcurrencyOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	assert.Equal(t, concurrencyOptions{}, resolveConcurrencyOptions())
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/old\n\ngo 1.21\n"), 0644))
+	assert.Equal(t, concurrencyOptions{sharedLoopVars: true}, resolveConcurrencyOptions())
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/new\n\ngo 1.22.0\n"), 0644))
+	assert.Equal(t, concurrencyOptions{}, resolveConcurrencyOptions())
+}
@@ -0,0 +1,230 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"log"
+)
+
+// contextVariants maps standard library functions to their variant taking a
+// context, for syntax-only runs. With type information any function or
+// method with a Context or WithContext sibling is reported.
+var contextVariants = map[string]map[string]string{
+	"os/exec": {
+		"Command": "CommandContext",
+	},
+	"net/http": {
+		"NewRequest": "NewRequestWithContext",
+		"Get":        "NewRequestWithContext",
+		"Head":       "NewRequestWithContext",
+		"Post":       "NewRequestWithContext",
+		"PostForm":   "NewRequestWithContext",
+	},
+	"net": {
+		"Dial":        "Dialer.DialContext",
+		"DialTimeout": "Dialer.DialContext",
+	},
+}
+
+func checkContext(idx *fileIndex) ([]Violation, error) {
+	log.Println("Checking context propagation...")
+
+	violations := idx.collect(idx.sources(), findContextViolations)
+
+	return violations, nil
+}
+
+// findContextViolations reports context parameters that are not the first
+// one or not named ctx, contexts stored in struct fields, new root contexts
+// outside package main and calls of functions that have a context variant.
+func findContextViolations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip {
+		return nil
+	}
+
+	c := &contextChecker{
+		file:    file,
+		context: importedName(node, "context"),
+		main:    node.Name.Name == "main",
+	}
+
+	for _, decl := range node.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			if hasFuncSkipDirective(fn) {
+				continue
+			}
+
+			c.checkParams(fn)
+		}
+
+		ast.Inspect(decl, c.visit)
+	}
+
+	return c.violations
+}
+
+type contextChecker struct {
+	file *sourceFile
+	// context is the name of the imported context package, if any.
+	context    string
+	main       bool
+	violations []Violation
+}
+
+func (c *contextChecker) report(n ast.Node, message, suggestion string) {
+	pos := c.file.fset.Position(n.Pos())
+	c.violations = append(c.violations, Violation{
+		Rule:       RuleContext,
+		File:       c.file.path,
+		Line:       pos.Line,
+		Column:     pos.Column,
+		Severity:   SeverityError,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+func (c *contextChecker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.StructType:
+		c.checkFields(n)
+	case *ast.CallExpr:
+		c.checkCall(n)
+	}
+
+	return true
+}
+
+func (c *contextChecker) isContextType(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || c.context == "" || sel.Sel.Name != "Context" {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+
+	return ok && ident.Name == c.context
+}
+
+func (c *contextChecker) checkParams(fn *ast.FuncDecl) {
+	position := 0
+	seen := false
+
+	for _, field := range fn.Type.Params.List {
+		names := max(len(field.Names), 1)
+
+		if c.isContextType(field.Type) {
+			if position > 0 && !seen {
+				c.report(field, fmt.Sprintf("context.Context is parameter %d of '%s' instead of the first", position+1, fn.Name.Name), "take ctx as the first parameter")
+			}
+
+			for _, name := range field.Names {
+				if name.Name != "ctx" && name.Name != "_" {
+					c.report(name, fmt.Sprintf("context parameter '%s' of '%s' should be named 'ctx'", name.Name, fn.Name.Name), "name the context parameter ctx")
+				}
+			}
+
+			seen = true
+		}
+
+		position += names
+	}
+}
+
+func (c *contextChecker) checkFields(st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		if !c.isContextType(field.Type) {
+			continue
+		}
+
+		name := "context.Context"
+		if len(field.Names) > 0 {
+			name = field.Names[0].Name
+		}
+
+		c.report(field, fmt.Sprintf("struct field '%s' stores a context.Context", name), "pass ctx to the functions that need it instead")
+	}
+}
+
+func (c *contextChecker) checkCall(call *ast.CallExpr) {
+	if !c.main && (isCall(call, c.context, "Background") || isCall(call, c.context, "TODO")) {
+		c.report(call, fmt.Sprintf("%s outside package main", types.ExprString(call)), "accept a ctx parameter and pass it on")
+		return
+	}
+
+	if name, variant := c.contextVariant(call); variant != "" {
+		c.report(call, fmt.Sprintf("'%s' ignores cancellation", name), fmt.Sprintf("use %s with a ctx", variant))
+	}
+}
+
+// contextVariant returns the name of the called function and of its variant
+// taking a context, or empty strings when it has none.
+func (c *contextChecker) contextVariant(call *ast.CallExpr) (string, string) {
+	var variant string
+
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		if c.file.typed != nil {
+			variant = c.typedVariant(fun)
+		}
+
+		if variant != "" {
+			return fun.Name, variant
+		}
+	case *ast.SelectorExpr:
+		if c.file.typed != nil {
+			variant = c.typedVariant(fun.Sel)
+		} else if ident, ok := fun.X.(*ast.Ident); ok {
+			for importPath, variants := range contextVariants {
+				if importedName(c.file.node, importPath) == ident.Name {
+					variant = variants[fun.Sel.Name]
+				}
+			}
+		}
+
+		if variant != "" {
+			return types.ExprString(fun), fmt.Sprintf("%s.%s", types.ExprString(fun.X), variant)
+		}
+	}
+
+	return "", ""
+}
+
+// typedVariant returns the context variant of the function name refers to,
+// from the table or from a sibling declaration.
+func (c *contextChecker) typedVariant(name *ast.Ident) string {
+	fn, ok := c.file.typed.info.Uses[name].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+
+	if variant := contextVariants[fn.Pkg().Path()][fn.Name()]; variant != "" {
+		return variant
+	}
+
+	return contextSibling(fn)
+}
+
+// contextSibling returns the name of the function or method declared next to
+// fn with a Context or WithContext suffix, or an empty string.
+func contextSibling(fn *types.Func) string {
+	for _, suffix := range []string{"Context", "WithContext"} {
+		name := fmt.Sprintf("%s%s", fn.Name(), suffix)
+
+		if recv := fn.Signature().Recv(); recv != nil {
+			if obj, _, _ := types.LookupFieldOrMethod(recv.Type(), true, fn.Pkg(), name); obj != nil {
+				return name
+			}
+		} else if fn.Pkg().Scope().Lookup(name) != nil {
+			return name
+		}
+	}
+
+	return ""
+}
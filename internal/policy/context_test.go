@@ -0,0 +1,107 @@
+package policy
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contextSource = `package client
+
+import (
+	stdctx "context"
+	"net/http"
+	"os/exec"
+)
+
+type Client struct {
+	ctx  stdctx.Context
+	name string
+}
+
+func Fetch(url string, ctx stdctx.Context) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	_ = req
+	return err
+}
+
+func Run(c stdctx.Context, _ stdctx.Context) error {
+	return exec.Command("true").Run()
+}
+
+func Start() {
+	Run(stdctx.Background(), stdctx.TODO())
+}
+
+//yake:skip-test
+func Legacy(name string, ctx stdctx.Context) {}
+`
+
+func Test_findContextViolations(t *testing.T) {
+	t.Run("reports context mistakes without type information", func(t *testing.T) {
+		file := parseSourceFile(token.NewFileSet(), "client.go", []byte(contextSource))
+		require.NoError(t, file.parseErr)
+
+		assert.Equal(t, []string{
+			"client.go:10:2: struct field 'ctx' stores a context.Context",
+			"client.go:14:24: context.Context is parameter 2 of 'Fetch' instead of the first",
+			"client.go:15:14: 'http.NewRequest' ignores cancellation",
+			"client.go:20:10: context parameter 'c' of 'Run' should be named 'ctx'",
+			"client.go:21:9: 'exec.Command' ignores cancellation",
+			"client.go:25:6: stdctx.Background() outside package main",
+			"client.go:25:27: stdctx.TODO() outside package main",
+		}, violationStrings(findContextViolations(file)))
+	})
+
+	t.Run("allows root contexts in package main", func(t *testing.T) {
+		file := parseSourceFile(token.NewFileSet(), "main.go", []byte("package main\n\nimport \"context\"\n\nfunc main() {\n\t_ = context.Background()\n}\n"))
+		require.NoError(t, file.parseErr)
+
+		assert.Empty(t, findContextViolations(file))
+	})
+
+	t.Run("finds context variants by type", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"store/store.go": `package store
+
+import (
+	"context"
+	"os/exec"
+)
+
+type DB struct{}
+
+func (DB) Query(q string) error { return nil }
+
+func (DB) QueryContext(ctx context.Context, q string) error { return nil }
+
+func Load(q string) error { return nil }
+
+func LoadWithContext(ctx context.Context, q string) error { return nil }
+
+func Use(ctx context.Context, db DB) error {
+	run := exec.Command
+	_ = run
+	_ = exec.CommandContext(ctx, "true")
+	_ = Load("x")
+
+	return db.Query("x")
+}
+`,
+		})
+
+		files := loadTypedFiles(t, nil)
+		require.NotNil(t, files["store/store.go"].typed)
+
+		violations := findContextViolations(files["store/store.go"])
+
+		assert.Equal(t, []string{
+			"store/store.go:22:6: 'Load' ignores cancellation",
+			"store/store.go:24:9: 'db.Query' ignores cancellation",
+		}, violationStrings(violations))
+		require.Len(t, violations, 2)
+		assert.Equal(t, "use db.QueryContext with a ctx", violations[1].Suggestion)
+	})
+}
@@ -0,0 +1,378 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+// CoverageFile is the default location of the recorded coverage floors.
+const CoverageFile = ".yake-coverage.json"
+
+const coverageFloorsVersion = 1
+
+// CoverageFloors is the last accepted coverage of each package, keyed by its
+// directory relative to the module root. The ratchet fails when a package
+// falls below its floor.
+type CoverageFloors struct {
+	Version  int                `json:"version"`
+	Packages map[string]float64 `json:"packages"`
+}
+
+// NewCoverageFloors returns empty floors.
+func NewCoverageFloors() *CoverageFloors {
+	return &CoverageFloors{
+		Version:  coverageFloorsVersion,
+		Packages: make(map[string]float64),
+	}
+}
+
+// LoadCoverageFloors reads a coverage floors file. A missing file is
+// reported with an error wrapping os.ErrNotExist.
+func LoadCoverageFloors(path string) (*CoverageFloors, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage floors: %w", err)
+	}
+
+	var floors CoverageFloors
+	if err := json.Unmarshal(data, &floors); err != nil {
+		return nil, fmt.Errorf("failed to parse coverage floors %s: %w", path, err)
+	}
+
+	if floors.Version != coverageFloorsVersion {
+		return nil, fmt.Errorf("unsupported coverage floors version %d in %s", floors.Version, path)
+	}
+
+	if floors.Packages == nil {
+		floors.Packages = make(map[string]float64)
+	}
+
+	return &floors, nil
+}
+
+// Save writes the floors as indented JSON.
+func (f *CoverageFloors) Save(path string) error {
+	if err := tools.WriteJSONFile(path, f); err != nil {
+		return fmt.Errorf("failed to write coverage floors: %w", err)
+	}
+
+	return nil
+}
+
+// Raise records measured coverage. A floor only ever rises, packages new to
+// the run are added at their coverage and packages no longer measured are
+// dropped. It returns the sorted packages whose floor changed.
+func (f *CoverageFloors) Raise(measured map[string]float64) []string {
+	var changed []string
+
+	for pkg := range f.Packages {
+		if _, ok := measured[pkg]; !ok {
+			delete(f.Packages, pkg)
+			changed = append(changed, pkg)
+		}
+	}
+
+	for pkg, coverage := range measured {
+		if floor, ok := f.Packages[pkg]; ok && floor >= coverage {
+			continue
+		}
+
+		f.Packages[pkg] = coverage
+		changed = append(changed, pkg)
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+// UpdateCoverageFloors raises the floors in path to the coverage of a test
+// run, merged across its passes and the configured coverage directories,
+// creating the file when it does not exist. A failing run records nothing,
+// as its coverage is incomplete.
+func UpdateCoverageFloors(ctx context.Context, path string, tests *testrun.Result) ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tests.Err(); err != nil {
+		return nil, fmt.Errorf("failed to measure coverage: %w", err)
+	}
+
+	goModData, err := os.ReadFile("go.mod")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	floors, err := LoadCoverageFloors(path)
+	if errors.Is(err, os.ErrNotExist) {
+		floors = NewCoverageFloors()
+	} else if err != nil {
+		return nil, err
+	}
+
+	profile, err := tests.MergedProfile(ctx, resolveCoverDirs(cfg.Policy.Coverage))
+	if err != nil {
+		return nil, err
+	}
+
+	profileData, err := os.ReadFile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+
+	coverage := parseCoverProfile(string(profileData), parseModulePath(string(goModData)))
+	changed := floors.Raise(packageCoverage(coverage, resolveExcludePackages(cfg.Policy.Coverage)))
+
+	if err := floors.Save(path); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// packageCoverage computes the statement coverage of each package from a
+// merged profile, keyed by package directory and rounded as go test prints
+// it. Excluded packages and packages without statements are left out.
+func packageCoverage(coverage map[string][]coverBlock, excludePackages []string) map[string]float64 {
+	total := make(map[string]int)
+	covered := make(map[string]int)
+
+	for file, blocks := range coverage {
+		dir := path.Dir(file)
+		if isDirExcluded(dir, excludePackages) {
+			continue
+		}
+
+		for _, b := range blocks {
+			total[dir] += b.NumStmt
+
+			if b.Count > 0 {
+				covered[dir] += b.NumStmt
+			}
+		}
+	}
+
+	percents := make(map[string]float64, len(total))
+
+	for dir, statements := range total {
+		if statements > 0 {
+			percents[dir] = math.Round(float64(covered[dir])*1000/float64(statements)) / 10
+		}
+	}
+
+	return percents
+}
+
+// checkCoverageRatchet compares measured coverage with the floors recorded in
+// path. Packages without a floor pass; they are recorded by the next update.
+func checkCoverageRatchet(path string, measured map[string]float64, tolerance float64) ([]Violation, error) {
+	log.Printf("Checking coverage ratchet (tolerance %.1f points)...", tolerance)
+
+	floors, err := LoadCoverageFloors(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Violation{{
+			Rule:       RuleCoverage,
+			File:       path,
+			Severity:   SeverityError,
+			Message:    "coverage floors not recorded",
+			Suggestion: "run 'yake policy coverage update' and commit the file",
+		}}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+
+	for _, pkg := range slices.Sorted(maps.Keys(measured)) {
+		floor, ok := floors.Packages[pkg]
+		if !ok || measured[pkg] >= floor-tolerance {
+			continue
+		}
+
+		violations = append(violations, Violation{
+			Rule:       RuleCoverage,
+			File:       pkg,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("coverage dropped to %.1f%% (recorded %.1f%%, tolerance %.1f points)", measured[pkg], floor, tolerance),
+			Suggestion: fmt.Sprintf("add tests, or lower the floor in %s if the drop is intended", path),
+		})
+	}
+
+	return violations, nil
+}
+
+// checkPatchCoverage reports the lines added or changed since the default
+// branch that no test covers, when less than patchMin percent of the changed
+// lines holding statements are covered.
+func checkPatchCoverage(ctx context.Context, profilePath, modulePath string, patchMin float64) ([]Violation, error) {
+	log.Printf("Checking patch coverage (minimum %.0f%% of changed lines)...", patchMin)
+
+	changes, err := patchChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profileData, err := os.ReadFile(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+
+	return patchCoverageViolations(parseCoverProfile(string(profileData), modulePath), changes, patchMin), nil
+}
+
+// patchChanges returns the changes since the default branch, falling back to
+// its origin copy in checkouts without a local branch, as on CI.
+func patchChanges(ctx context.Context) (tools.Changes, error) {
+	branch, err := tools.DetectDefaultBranch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect base branch for patch coverage: %w", err)
+	}
+
+	changes, err := tools.ChangesSince(ctx, branch)
+	if err == nil {
+		return changes, nil
+	}
+
+	if changes, originErr := tools.ChangesSince(ctx, fmt.Sprintf("origin/%s", branch)); originErr == nil {
+		return changes, nil
+	}
+
+	return nil, err
+}
+
+// patchCoverageViolations computes the share of changed lines inside a
+// coverage block that some test reached and, below patchMin, reports the
+// uncovered changed lines file by file. Test files and lines outside every
+// block, such as comments and declarations, do not count.
+func patchCoverageViolations(coverage map[string][]coverBlock, changes tools.Changes, patchMin float64) []Violation {
+	files := make([]string, 0, len(changes))
+	for file := range changes {
+		files = append(files, file)
+	}
+
+	slices.Sort(files)
+
+	var (
+		total     int
+		covered   int
+		uncovered = make(map[string][]int)
+	)
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		for _, r := range changes[file] {
+			for line := r.Start; line <= r.End; line++ {
+				coverable, hit := lineCoverage(coverage[file], line)
+				if !coverable {
+					continue
+				}
+
+				total++
+
+				if hit {
+					covered++
+				} else {
+					uncovered[file] = append(uncovered[file], line)
+				}
+			}
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	percent := float64(covered) * 100 / float64(total)
+	if percent >= patchMin {
+		return nil
+	}
+
+	var violations []Violation
+
+	for _, file := range files {
+		lines := uncovered[file]
+		if len(lines) == 0 {
+			continue
+		}
+
+		noun := "lines"
+		if len(lines) == 1 {
+			noun = "line"
+		}
+
+		violations = append(violations, Violation{
+			Rule:       RuleCoverage,
+			File:       file,
+			Line:       lines[0],
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("changed %s %s not covered (patch coverage %.1f%%, minimum %.0f%%)", noun, formatLines(lines), percent, patchMin),
+			Suggestion: "add tests for the changed code",
+		})
+	}
+
+	return violations
+}
+
+// lineCoverage reports whether a line lies in a coverage block and whether
+// any block holding it ran. Profiles list a block once per test binary, so a
+// line counts as covered when any of them ran it.
+func lineCoverage(blocks []coverBlock, line int) (bool, bool) {
+	coverable := false
+
+	for _, b := range blocks {
+		if line < b.StartLine || line > b.EndLine {
+			continue
+		}
+
+		if b.Count > 0 {
+			return true, true
+		}
+
+		coverable = true
+	}
+
+	return coverable, false
+}
+
+// formatLines joins sorted line numbers, collapsing runs into ranges:
+// "3-5, 9".
+func formatLines(lines []int) string {
+	var parts []string
+
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+
+		if i == j {
+			parts = append(parts, fmt.Sprintf("%d", lines[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+
+		i = j + 1
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,293 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+func Test_patchCoverageViolations(t *testing.T) {
+	coverage := map[string][]coverBlock{
+		"calc/calc.go": {
+			{StartLine: 3, EndLine: 5, Count: 1},
+			{StartLine: 7, EndLine: 9, Count: 0},
+			{StartLine: 11, EndLine: 14, Count: 0},
+			{StartLine: 11, EndLine: 14, Count: 2},
+		},
+		"calc/sub.go": {
+			{StartLine: 1, EndLine: 4, Count: 0},
+		},
+	}
+
+	changes := tools.Changes{
+		"calc/calc.go":      {{Start: 2, End: 12}},
+		"calc/sub.go":       {{Start: 2, End: 2}},
+		"calc/calc_test.go": {{Start: 1, End: 50}},
+		"readme.md":         {{Start: 1, End: 3}},
+	}
+
+	t.Run("lists uncovered changed lines below the minimum", func(t *testing.T) {
+		violations := patchCoverageViolations(coverage, changes, 80)
+
+		assert.Equal(t, []string{
+			"calc/calc.go:7: changed lines 7-9 not covered (patch coverage 55.6%, minimum 80%)",
+			"calc/sub.go:2: changed line 2 not covered (patch coverage 55.6%, minimum 80%)",
+		}, violationStrings(violations))
+		assert.Equal(t, RuleCoverage, violations[0].Rule)
+	})
+
+	t.Run("passes at the minimum", func(t *testing.T) {
+		assert.Empty(t, patchCoverageViolations(coverage, changes, 50))
+	})
+
+	t.Run("passes without coverable changes", func(t *testing.T) {
+		assert.Empty(t, patchCoverageViolations(coverage, tools.Changes{"readme.md": {{Start: 1, End: 3}}}, 100))
+	})
+}
+
+func Test_formatLines(t *testing.T) {
+	assert.Equal(t, "", formatLines(nil))
+	assert.Equal(t, "4", formatLines([]int{4}))
+	assert.Equal(t, "3-5, 9, 11-12", formatLines([]int{3, 4, 5, 9, 11, 12}))
+}
+
+func Test_checkPatchCoverage(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	git := func(args ...string) {
+		t.Helper()
+
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	t.Run("returns error outside a repository", func(t *testing.T) {
+		_, err := checkPatchCoverage(t.Context(), "cover.out", "example.com/fx", 80)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to detect base branch for patch coverage")
+	})
+
+	git("init", "-b", "main")
+	git("config", "core.hooksPath", "/dev/null")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test")
+
+	require.NoError(t, os.WriteFile("calc.go", []byte("package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"), 0644))
+	git("add", "calc.go")
+	git("commit", "-m", "init")
+	git("checkout", "-b", "feature")
+
+	require.NoError(t, os.WriteFile("calc.go", []byte("package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n"), 0644))
+
+	profile := "mode: atomic\nexample.com/fx/calc.go:3.24,5.2 1 1\nexample.com/fx/calc.go:7.24,9.2 1 0\n"
+	require.NoError(t, os.WriteFile("cover.out", []byte(profile), 0644))
+
+	t.Run("reports uncovered lines changed since the default branch", func(t *testing.T) {
+		violations, err := checkPatchCoverage(t.Context(), "cover.out", "example.com/fx", 80)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"calc.go:7: changed lines 7-9 not covered (patch coverage 0.0%, minimum 80%)",
+		}, violationStrings(violations))
+	})
+
+	t.Run("returns error without a profile", func(t *testing.T) {
+		_, err := checkPatchCoverage(t.Context(), "missing.out", "example.com/fx", 80)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read coverage profile")
+	})
+}
+
+// coverageRun builds a test run whose single pass wrote profile.
+func coverageRun(t *testing.T, profile string, err error) *testrun.Result {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cover.out")
+	require.NoError(t, os.WriteFile(path, []byte(profile), 0644))
+
+	tests := &testrun.Result{Passes: []*testrun.Pass{{Profile: path, Err: err}}}
+	t.Cleanup(func() { tests.Close() })
+
+	return tests
+}
+
+const ratchetProfile = `mode: atomic
+example.com/fx/main.go:5.2,9.3 9 1
+example.com/fx/main.go:10.2,10.9 1 0
+example.com/fx/calc/calc.go:3.2,4.3 2 4
+example.com/fx/calc/calc.go:6.2,6.9 1 0
+example.com/fx/store/store.go:3.2,4.3 3 1
+example.com/fx/store/store.go:6.2,7.9 2 0
+example.com/fx/gen/gen.go:3.2,4.3 5 0
+example.com/fx/types/types.go:3.2,4.3 0 0
+`
+
+func Test_packageCoverage(t *testing.T) {
+	assert.Equal(t, map[string]float64{
+		".":     90,
+		"calc":  66.7,
+		"store": 60,
+	}, packageCoverage(parseCoverProfile(ratchetProfile, "example.com/fx"), []string{"gen"}))
+}
+
+func TestCoverageFloorsSaveAndLoad(t *testing.T) {
+	t.Run("round trips floors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), CoverageFile)
+
+		floors := NewCoverageFloors()
+		floors.Packages["calc"] = 80.5
+		require.NoError(t, floors.Save(path))
+
+		loaded, err := LoadCoverageFloors(path)
+		require.NoError(t, err)
+		assert.Equal(t, floors, loaded)
+	})
+
+	t.Run("returns not exist error for missing file", func(t *testing.T) {
+		_, err := LoadCoverageFloors(filepath.Join(t.TempDir(), CoverageFile))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("returns error for invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), CoverageFile)
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+		_, err := LoadCoverageFloors(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse coverage floors")
+	})
+
+	t.Run("returns error for unsupported version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), CoverageFile)
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0644))
+
+		_, err := LoadCoverageFloors(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported coverage floors version 99")
+	})
+
+	t.Run("returns error when file cannot be written", func(t *testing.T) {
+		err := NewCoverageFloors().Save(filepath.Join(t.TempDir(), "missing", CoverageFile))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write coverage floors")
+	})
+}
+
+func TestCoverageFloorsRaise(t *testing.T) {
+	floors := NewCoverageFloors()
+	floors.Packages["calc"] = 80
+	floors.Packages["store"] = 70
+	floors.Packages["old"] = 50
+
+	changed := floors.Raise(map[string]float64{"calc": 85, "store": 65, "api": 40})
+
+	assert.Equal(t, []string{"api", "calc", "old"}, changed)
+	assert.Equal(t, map[string]float64{"api": 40, "calc": 85, "store": 70}, floors.Packages)
+}
+
+func Test_checkCoverageRatchet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CoverageFile)
+
+	t.Run("asks for floors when none are recorded", func(t *testing.T) {
+		violations, err := checkCoverageRatchet(path, map[string]float64{"calc": 80}, 0.5)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, path, violations[0].File)
+		assert.Equal(t, "coverage floors not recorded", violations[0].Message)
+	})
+
+	floors := NewCoverageFloors()
+	floors.Packages["."] = 90
+	floors.Packages["calc"] = 80
+	floors.Packages["store"] = 61
+	require.NoError(t, floors.Save(path))
+
+	t.Run("reports packages below the tolerance", func(t *testing.T) {
+		violations, err := checkCoverageRatchet(path, map[string]float64{".": 90, "calc": 79.6, "store": 60}, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"store: coverage dropped to 60.0% (recorded 61.0%, tolerance 0.5 points)",
+		}, violationStrings(violations))
+	})
+
+	t.Run("passes within a wider tolerance", func(t *testing.T) {
+		violations, err := checkCoverageRatchet(path, map[string]float64{".": 90, "calc": 79.6, "store": 60}, 1)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("returns error for unreadable floors", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), CoverageFile)
+		require.NoError(t, os.WriteFile(invalid, []byte("{"), 0644))
+
+		_, err := checkCoverageRatchet(invalid, nil, 0.5)
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateCoverageFloors(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy:\n  coverage:\n    exclude_packages: [gen]\n"), 0644))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/fx\n\ngo 1.21\n"), 0644))
+
+	t.Run("creates the floors file", func(t *testing.T) {
+		changed, err := UpdateCoverageFloors(t.Context(), CoverageFile, coverageRun(t, ratchetProfile, nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{".", "calc", "store"}, changed)
+
+		floors, err := LoadCoverageFloors(CoverageFile)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{".": 90, "calc": 66.7, "store": 60}, floors.Packages)
+	})
+
+	t.Run("only raises floors", func(t *testing.T) {
+		profile := "mode: atomic\nexample.com/fx/main.go:5.2,9.3 1 0\nexample.com/fx/calc/calc.go:3.2,4.3 2 1\nexample.com/fx/store/store.go:3.2,4.3 3 1\n"
+
+		changed, err := UpdateCoverageFloors(t.Context(), CoverageFile, coverageRun(t, profile, nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"calc", "store"}, changed)
+
+		floors, err := LoadCoverageFloors(CoverageFile)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{".": 90, "calc": 100, "store": 100}, floors.Packages)
+	})
+
+	t.Run("records nothing from a failing run", func(t *testing.T) {
+		_, err := UpdateCoverageFloors(t.Context(), CoverageFile, coverageRun(t, ratchetProfile, errors.New("exit status 1")))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to measure coverage")
+	})
+
+	t.Run("returns error for unreadable floors", func(t *testing.T) {
+		require.NoError(t, os.WriteFile("broken.json", []byte("{"), 0644))
+
+		_, err := UpdateCoverageFloors(t.Context(), "broken.json", coverageRun(t, ratchetProfile, nil))
+		require.Error(t, err)
+	})
+}
+
+func Test_resolveRatchetOptions(t *testing.T) {
+	tolerance := 2.0
+
+	assert.False(t, resolveRatchet(nil))
+	assert.True(t, resolveRatchet(&config.CoveragePolicy{Ratchet: boolPtr(true)}))
+	assert.Equal(t, config.DefaultRatchetTolerance, resolveRatchetTolerance(nil))
+	assert.Equal(t, tolerance, resolveRatchetTolerance(&config.CoveragePolicy{RatchetTolerance: &tolerance}))
+}
@@ -0,0 +1,435 @@
+package policy
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"html/template"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+// lineStatus is the coverage of a source line: outside every block, run by
+// some test, or in a block no test ran.
+type lineStatus int
+
+const (
+	lineNeutral lineStatus = iota
+	lineCovered
+	lineUncovered
+)
+
+// CoverageReport breaks the coverage of a merged profile down by package and
+// function, worst first, for developers looking for what fails the gate.
+type CoverageReport struct {
+	Packages []PackageCoverage
+	files    []fileCoverage
+}
+
+// PackageCoverage is the coverage of one package, keyed by its directory
+// relative to the module root. Functions lists those with uncovered lines.
+type PackageCoverage struct {
+	Dir            string
+	Statements     int
+	Covered        int
+	UncoveredLines int
+	Functions      []FunctionCoverage
+}
+
+// FunctionCoverage is the coverage of one function. Flagged marks a large
+// function without any coverage, which the coverage policy reports.
+type FunctionCoverage struct {
+	File           string
+	Name           string
+	Line           int
+	Statements     int
+	Covered        int
+	UncoveredLines int
+	Flagged        bool
+}
+
+// fileCoverage holds the source lines of a file and the status of each, for
+// the annotated source of the HTML report.
+type fileCoverage struct {
+	path   string
+	lines  []string
+	status []lineStatus
+}
+
+// Percent returns the statement coverage of the package.
+func (p PackageCoverage) Percent() float64 {
+	return coveragePercent(p.Covered, p.Statements)
+}
+
+// Percent returns the statement coverage of the function.
+func (f FunctionCoverage) Percent() float64 {
+	return coveragePercent(f.Covered, f.Statements)
+}
+
+// coveragePercent rounds as go test prints coverage. No statements count as
+// fully covered.
+func coveragePercent(covered, statements int) float64 {
+	if statements == 0 {
+		return 100
+	}
+
+	return math.Round(float64(covered)*1000/float64(statements)) / 10
+}
+
+// NewCoverageReport builds the report for a merged text profile, honouring
+// the exclusions and the large function limit of the coverage section.
+func NewCoverageReport(profile string) (*CoverageReport, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	goModData, err := os.ReadFile("go.mod")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	profileData, err := os.ReadFile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+
+	coverage := parseCoverProfile(string(profileData), parseModulePath(string(goModData)))
+
+	return buildCoverageReport(coverage, resolveExcludePackages(cfg.Policy.Coverage), resolveMaxUncoveredFuncLines(cfg.Policy.Coverage)), nil
+}
+
+func buildCoverageReport(coverage map[string][]coverBlock, excludePackages []string, maxUncoveredFuncLines int) *CoverageReport {
+	report := &CoverageReport{}
+	packages := make(map[string]*PackageCoverage)
+
+	for _, file := range slices.Sorted(maps.Keys(coverage)) {
+		dir := path.Dir(file)
+		if isDirExcluded(dir, excludePackages) {
+			continue
+		}
+
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &PackageCoverage{Dir: dir}
+			packages[dir] = pkg
+		}
+
+		blocks := coverage[file]
+		status := lineStatuses(blocks)
+
+		for _, b := range blocks {
+			pkg.Statements += b.NumStmt
+
+			if b.Count > 0 {
+				pkg.Covered += b.NumStmt
+			}
+		}
+
+		pkg.UncoveredLines += countUncovered(status, 0, len(status)-1)
+
+		fc := fileCoverage{path: file, status: status}
+
+		if source, err := loadSourceFile(token.NewFileSet(), file); err == nil {
+			fc.lines = strings.Split(strings.TrimSuffix(string(source.src), "\n"), "\n")
+
+			if !source.skip {
+				pkg.Functions = append(pkg.Functions, functionCoverage(source, blocks, status, maxUncoveredFuncLines)...)
+			}
+		}
+
+		report.files = append(report.files, fc)
+	}
+
+	for _, pkg := range packages {
+		slices.SortStableFunc(pkg.Functions, func(a, b FunctionCoverage) int {
+			return cmp.Compare(b.UncoveredLines, a.UncoveredLines)
+		})
+
+		report.Packages = append(report.Packages, *pkg)
+	}
+
+	slices.SortFunc(report.Packages, func(a, b PackageCoverage) int {
+		return cmp.Or(cmp.Compare(b.UncoveredLines, a.UncoveredLines), cmp.Compare(a.Dir, b.Dir))
+	})
+
+	return report
+}
+
+// lineStatuses marks every line of a file by the blocks covering it. A line
+// is covered when any block holding it ran, as profiles may list a block
+// once per test binary.
+func lineStatuses(blocks []coverBlock) []lineStatus {
+	last := 0
+	for _, b := range blocks {
+		last = max(last, b.EndLine)
+	}
+
+	status := make([]lineStatus, last+1)
+
+	for _, b := range blocks {
+		for line := b.StartLine; line <= b.EndLine; line++ {
+			switch {
+			case b.Count > 0:
+				status[line] = lineCovered
+			case status[line] != lineCovered:
+				status[line] = lineUncovered
+			}
+		}
+	}
+
+	return status
+}
+
+// countUncovered counts the uncovered lines from start to end inclusive.
+func countUncovered(status []lineStatus, start, end int) int {
+	count := 0
+
+	for line := max(start, 0); line <= end && line < len(status); line++ {
+		if status[line] == lineUncovered {
+			count++
+		}
+	}
+
+	return count
+}
+
+// functionCoverage returns the functions of a file with uncovered lines,
+// flagging those findUncoveredLargeFunctions reports.
+func functionCoverage(file *sourceFile, blocks []coverBlock, status []lineStatus, maxUncoveredFuncLines int) []FunctionCoverage {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	flagged := make(map[int]bool)
+
+	for _, fn := range largeFunctions(file, maxUncoveredFuncLines) {
+		if !isFuncCovered(blocks, fn) {
+			flagged[fn.StartLine] = true
+		}
+	}
+
+	var functions []FunctionCoverage
+
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || hasFuncSkipDirective(fn) {
+			continue
+		}
+
+		start := file.fset.Position(fn.Body.Lbrace).Line
+		end := file.fset.Position(fn.Body.Rbrace).Line
+
+		fc := FunctionCoverage{
+			File:           file.path,
+			Name:           funcName(fn),
+			Line:           start,
+			UncoveredLines: countUncovered(status, start, end),
+			Flagged:        flagged[start],
+		}
+
+		for _, b := range blocks {
+			if b.StartLine >= start && b.EndLine <= end {
+				fc.Statements += b.NumStmt
+
+				if b.Count > 0 {
+					fc.Covered += b.NumStmt
+				}
+			}
+		}
+
+		if fc.UncoveredLines > 0 {
+			functions = append(functions, fc)
+		}
+	}
+
+	return functions
+}
+
+// total returns the statements and covered statements of every package.
+func (r *CoverageReport) total() (int, int) {
+	statements, covered := 0, 0
+
+	for _, pkg := range r.Packages {
+		statements += pkg.Statements
+		covered += pkg.Covered
+	}
+
+	return statements, covered
+}
+
+// hasFlagged reports whether any function is flagged.
+func (r *CoverageReport) hasFlagged() bool {
+	return slices.ContainsFunc(r.Packages, func(pkg PackageCoverage) bool {
+		return slices.ContainsFunc(pkg.Functions, func(fn FunctionCoverage) bool { return fn.Flagged })
+	})
+}
+
+// WriteText renders the report as a table: each package followed by its
+// functions with uncovered lines, flagged functions marked with "!".
+func (r *CoverageReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PACKAGE / FUNCTION\tCOVERAGE\tSTATEMENTS\tUNCOVERED LINES")
+
+	for _, pkg := range r.Packages {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%d/%d\t%d\n", pkg.Dir, pkg.Percent(), pkg.Covered, pkg.Statements, pkg.UncoveredLines)
+
+		for _, fn := range pkg.Functions {
+			marker := " "
+			if fn.Flagged {
+				marker = "!"
+			}
+
+			fmt.Fprintf(tw, "  %s %s:%d %s\t%.1f%%\t%d/%d\t%d\n", marker, path.Base(fn.File), fn.Line, fn.Name, fn.Percent(), fn.Covered, fn.Statements, fn.UncoveredLines)
+		}
+	}
+
+	statements, covered := r.total()
+	fmt.Fprintf(tw, "total\t%.1f%%\t%d/%d\n", coveragePercent(covered, statements), covered, statements)
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if r.hasFlagged() {
+		_, err := fmt.Fprintln(w, "\n! large function without coverage, reported by the coverage policy")
+		return err
+	}
+
+	return nil
+}
+
+// WriteHTML renders the report as a standalone page: the table of the text
+// report, linked to the annotated source of every file.
+func (r *CoverageReport) WriteHTML(w io.Writer) error {
+	statements, covered := r.total()
+
+	fileIDs := make(map[string]int, len(r.files))
+	for i, file := range r.files {
+		fileIDs[file.path] = i
+	}
+
+	data := htmlReport{
+		Total:      coveragePercent(covered, statements),
+		Statements: statements,
+		Covered:    covered,
+		Packages:   r.Packages,
+		Flagged:    r.hasFlagged(),
+	}
+
+	for i, file := range r.files {
+		hf := htmlFile{ID: i, Path: file.path}
+
+		for n, text := range file.lines {
+			status := lineNeutral
+			if n+1 < len(file.status) {
+				status = file.status[n+1]
+			}
+
+			hf.Lines = append(hf.Lines, htmlLine{Number: n + 1, Text: text, Class: lineClasses[status]})
+		}
+
+		data.Files = append(data.Files, hf)
+	}
+
+	funcs := template.FuncMap{
+		"anchor": func(file string, line int) string {
+			return fmt.Sprintf("f%d-L%d", fileIDs[file], line)
+		},
+		"base": path.Base,
+	}
+
+	tmpl, err := template.New("coverage").Funcs(funcs).Parse(coverageHTML)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage template: %w", err)
+	}
+
+	return tmpl.Execute(w, data)
+}
+
+var lineClasses = map[lineStatus]string{
+	lineNeutral:   "neutral",
+	lineCovered:   "covered",
+	lineUncovered: "uncovered",
+}
+
+type htmlReport struct {
+	Total      float64
+	Statements int
+	Covered    int
+	Packages   []PackageCoverage
+	Files      []htmlFile
+	Flagged    bool
+}
+
+type htmlFile struct {
+	ID    int
+	Path  string
+	Lines []htmlLine
+}
+
+type htmlLine struct {
+	Number int
+	Text   string
+	Class  string
+}
+
+const coverageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Coverage report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 0.2em 0.8em; text-align: left; }
+th { border-bottom: 1px solid #999; }
+td.num { text-align: right; }
+tr.package td { font-weight: bold; border-top: 1px solid #ddd; }
+tr.function td:first-child { padding-left: 2em; }
+tr.flagged td { color: #b00; }
+pre { margin: 0; }
+.line { display: block; white-space: pre; font-family: monospace; }
+.line .no { display: inline-block; width: 4em; color: #999; text-align: right; margin-right: 1em; }
+.covered { background: #dfd; }
+.uncovered { background: #fdd; }
+:target { outline: 2px solid #36c; }
+</style>
+</head>
+<body>
+<h1>Coverage report</h1>
+<p>Total coverage {{printf "%.1f" .Total}}% ({{.Covered}}/{{.Statements}} statements).</p>
+<table>
+<tr><th>Package / function</th><th>Coverage</th><th>Statements</th><th>Uncovered lines</th></tr>
+{{- range .Packages}}
+<tr class="package"><td>{{.Dir}}</td><td class="num">{{printf "%.1f" .Percent}}%</td><td class="num">{{.Covered}}/{{.Statements}}</td><td class="num">{{.UncoveredLines}}</td></tr>
+{{- range .Functions}}
+<tr class="function{{if .Flagged}} flagged{{end}}"><td>{{if .Flagged}}! {{end}}<a href="#{{anchor .File .Line}}">{{base .File}}:{{.Line}} {{.Name}}</a></td><td class="num">{{printf "%.1f" .Percent}}%</td><td class="num">{{.Covered}}/{{.Statements}}</td><td class="num">{{.UncoveredLines}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- if .Flagged}}
+<p>! large function without coverage, reported by the coverage policy</p>
+{{- end}}
+{{- range $file := .Files}}
+<h2 id="f{{$file.ID}}">{{$file.Path}}</h2>
+<pre>
+{{- range $file.Lines}}
+<span id="f{{$file.ID}}-L{{.Number}}" class="line {{.Class}}"><span class="no">{{.Number}}</span>{{.Text}}</span>
+{{- end}}
+</pre>
+{{- end}}
+</body>
+</html>
+`
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reportStoreSource = `package store
+
+type Store struct{}
+
+func (s *Store) Open(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	return nil
+}
+
+func Close() {
+	println("a")
+	println("b")
+	println("c")
+}
+`
+
+const reportProfile = `mode: atomic
+example.com/fx/store/store.go:6.2,6.14 1 1
+example.com/fx/store/store.go:7.3,7.13 1 0
+example.com/fx/store/store.go:10.2,10.12 1 1
+example.com/fx/store/store.go:14.2,16.14 3 0
+example.com/fx/calc/calc.go:3.2,3.10 1 1
+example.com/fx/gen/gen.go:3.2,3.10 4 0
+`
+
+// writeReportProject lays out the sources of reportProfile in a temporary
+// module and changes into it.
+func writeReportProject(t *testing.T) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(originalDir) })
+
+	require.NoError(t, os.Chdir(tmpDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/fx\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(".yake.yaml", []byte("policy:\n  coverage:\n    max_uncovered_func_lines: 2\n    exclude_packages: [gen]\n"), 0644))
+	require.NoError(t, os.MkdirAll("store", 0755))
+	require.NoError(t, os.MkdirAll("calc", 0755))
+	require.NoError(t, os.WriteFile("store/store.go", []byte(reportStoreSource), 0644))
+	require.NoError(t, os.WriteFile("calc/calc.go", []byte("package calc\n\nfunc One() int {\n\treturn 1\n}\n"), 0644))
+	require.NoError(t, os.WriteFile("cover.out", []byte(reportProfile), 0644))
+}
+
+func TestNewCoverageReport(t *testing.T) {
+	writeReportProject(t)
+
+	t.Run("breaks coverage down by package and function", func(t *testing.T) {
+		report, err := NewCoverageReport("cover.out")
+		require.NoError(t, err)
+
+		assert.Equal(t, []PackageCoverage{
+			{
+				Dir:            "store",
+				Statements:     6,
+				Covered:        2,
+				UncoveredLines: 4,
+				Functions: []FunctionCoverage{
+					{
+						File:           "store/store.go",
+						Name:           "Close",
+						Line:           13,
+						Statements:     3,
+						UncoveredLines: 3,
+						Flagged:        true,
+					},
+					{
+						File:           "store/store.go",
+						Name:           "Store_Open",
+						Line:           5,
+						Statements:     3,
+						Covered:        2,
+						UncoveredLines: 1,
+					},
+				},
+			},
+			{Dir: "calc", Statements: 1, Covered: 1},
+		}, report.Packages)
+		assert.Equal(t, 33.3, report.Packages[0].Percent())
+	})
+
+	t.Run("returns error without a profile", func(t *testing.T) {
+		_, err := NewCoverageReport("missing.out")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read coverage profile")
+	})
+}
+
+func TestCoverageReportWriteText(t *testing.T) {
+	writeReportProject(t)
+
+	report, err := NewCoverageReport("cover.out")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+
+	assert.Equal(t, `PACKAGE / FUNCTION         COVERAGE  STATEMENTS  UNCOVERED LINES
+store                      33.3%     2/6         4
+  ! store.go:13 Close      0.0%      0/3         3
+    store.go:5 Store_Open  66.7%     2/3         1
+calc                       100.0%    1/1         0
+total                      42.9%     3/7
+
+! large function without coverage, reported by the coverage policy
+`, out.String())
+}
+
+func TestCoverageReportWriteHTML(t *testing.T) {
+	writeReportProject(t)
+
+	report, err := NewCoverageReport("cover.out")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteHTML(&out))
+
+	page := out.String()
+	assert.Contains(t, page, "<p>Total coverage 42.9% (3/7 statements).</p>")
+	assert.Contains(t, page, `<tr class="function flagged"><td>! <a href="#f1-L13">store.go:13 Close</a>`)
+	assert.Contains(t, page, `<span id="f1-L7" class="line uncovered"><span class="no">7</span>		return nil</span>`)
+	assert.Contains(t, page, `<span id="f1-L10" class="line covered"><span class="no">10</span>	return nil</span>`)
+	assert.Contains(t, page, `<span id="f0-L1" class="line neutral"><span class="no">1</span>package calc</span>`)
+	assert.NotContains(t, page, "gen/gen.go")
+}
+
+func Test_lineStatuses(t *testing.T) {
+	status := lineStatuses([]coverBlock{
+		{StartLine: 2, EndLine: 3, Count: 0},
+		{StartLine: 3, EndLine: 4, Count: 1},
+	})
+
+	assert.Equal(t, []lineStatus{lineNeutral, lineNeutral, lineUncovered, lineCovered, lineCovered}, status)
+	assert.Equal(t, 1, countUncovered(status, 0, len(status)-1))
+	assert.Equal(t, 0, countUncovered(status, 3, 10))
+}
+
+func Test_coveragePercent(t *testing.T) {
+	assert.Equal(t, 100.0, coveragePercent(0, 0))
+	assert.Equal(t, 66.7, coveragePercent(2, 3))
+}
@@ -0,0 +1,422 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func checkErrorHandling(idx *fileIndex) ([]Violation, error) {
+	log.Println("Checking error handling...")
+
+	violations := idx.collect(idx.sources(), findErrorHandlingViolations)
+
+	return violations, nil
+}
+
+// findErrorHandlingViolations reports errors formatted instead of wrapped,
+// badly styled error strings, sentinel errors without the Err prefix, errors
+// compared with == and error results discarded with '_'. With type
+// information errors are recognized by type; without it by the usual names,
+// by errors.New and fmt.Errorf calls, and by the functions isErrorResult
+// knows to return an error.
+func findErrorHandlingViolations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip {
+		return nil
+	}
+
+	c := &errorChecker{
+		file:   file,
+		errors: importedName(node, "errors"),
+		fmt:    importedName(node, "fmt"),
+	}
+
+	for _, decl := range node.Decls {
+		c.allowEqual = false
+
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if hasGenDeclSkipDirective(d) {
+				continue
+			}
+
+			c.checkSentinels(d)
+		case *ast.FuncDecl:
+			if hasFuncSkipDirective(d) {
+				continue
+			}
+
+			// Is methods implement errors.Is and compare with == by design.
+			c.allowEqual = d.Recv != nil && d.Name.Name == "Is"
+		}
+
+		ast.Inspect(decl, c.visit)
+	}
+
+	return c.violations
+}
+
+type errorChecker struct {
+	file *sourceFile
+	// errors and fmt are the names of the imported packages, if any.
+	errors     string
+	fmt        string
+	allowEqual bool
+	violations []Violation
+}
+
+func (c *errorChecker) report(n ast.Node, message, suggestion string) {
+	pos := c.file.fset.Position(n.Pos())
+	c.violations = append(c.violations, Violation{
+		Rule:       RuleErrorHandling,
+		File:       c.file.path,
+		Line:       pos.Line,
+		Column:     pos.Column,
+		Severity:   SeverityError,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+func (c *errorChecker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.CallExpr:
+		c.checkCall(n)
+	case *ast.BinaryExpr:
+		c.checkComparison(n)
+	case *ast.AssignStmt:
+		c.checkDiscard(n)
+	}
+
+	return true
+}
+
+// isCall reports whether call invokes pkg.name, pkg being the local name of
+// an imported package.
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || pkg == "" || sel.Sel.Name != name {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+
+	return ok && ident.Name == pkg
+}
+
+func (c *errorChecker) checkCall(call *ast.CallExpr) {
+	isErrorf := isCall(call, c.fmt, "Errorf")
+	if !isErrorf && !isCall(call, c.errors, "New") {
+		return
+	}
+
+	if len(call.Args) == 0 {
+		return
+	}
+
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+
+	message, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+
+	if problem := errorStringProblem(message); problem != "" {
+		c.report(lit, fmt.Sprintf("error string %s %s", lit.Value, problem), "start error strings in lower case and end them without punctuation")
+	}
+
+	if !isErrorf {
+		return
+	}
+
+	verbs, ok := formatVerbs(message)
+	if !ok {
+		return
+	}
+
+	for i, verb := range verbs {
+		if verb != 'v' && verb != 's' || i+1 >= len(call.Args) {
+			continue
+		}
+
+		if arg := call.Args[i+1]; c.isErrorValue(arg) {
+			c.report(arg, fmt.Sprintf("error '%s' formatted with %%%c instead of wrapped", types.ExprString(arg), verb), "use %w so callers can unwrap the error")
+		}
+	}
+}
+
+// errorStringProblem describes why an error string breaks the Go style, or
+// returns an empty string. A capitalized first word is fine when it is an
+// acronym or an identifier with more capitals.
+func errorStringProblem(message string) string {
+	first, _ := utf8.DecodeRuneInString(message)
+	word, _, _ := strings.Cut(message, " ")
+
+	if unicode.IsUpper(first) && strings.IndexFunc(word[utf8.RuneLen(first):], unicode.IsUpper) < 0 {
+		return "starts with a capital letter"
+	}
+
+	if strings.HasSuffix(message, ".") || strings.HasSuffix(message, ":") || strings.HasSuffix(message, "!") || strings.HasSuffix(message, "\n") {
+		return "ends with punctuation"
+	}
+
+	return ""
+}
+
+// formatVerbs returns the verbs of a printf format in argument order, with
+// '*' for widths and precisions taken from arguments. It gives up on
+// explicit argument indexes.
+func formatVerbs(format string) ([]rune, bool) {
+	var verbs []rune
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.*", format[i]) >= 0; i++ {
+			if format[i] == '*' {
+				verbs = append(verbs, '*')
+			}
+		}
+
+		if i >= len(format) || format[i] == '%' {
+			continue
+		}
+
+		if format[i] == '[' {
+			return nil, false
+		}
+
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		verbs = append(verbs, verb)
+		i += size - 1
+	}
+
+	return verbs, true
+}
+
+// isErrorValue reports whether expr holds an error: by type when known,
+// otherwise by the err and fooErr naming convention.
+func (c *errorChecker) isErrorValue(expr ast.Expr) bool {
+	if c.file.typed != nil {
+		return c.file.typed.isError(expr)
+	}
+
+	var name string
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	default:
+		return false
+	}
+
+	return name == "err" || strings.HasSuffix(name, "Err")
+}
+
+// isSentinelName reports whether name follows the ErrXxx convention.
+func isSentinelName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Err")
+	if !ok || rest == "" {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(rest)
+
+	return unicode.IsUpper(first) || unicode.IsDigit(first)
+}
+
+// checkSentinels reports exported package variables holding errors whose
+// name lacks the Err prefix.
+func (c *errorChecker) checkSentinels(decl *ast.GenDecl) {
+	if decl.Tok != token.VAR {
+		return
+	}
+
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		for i, name := range vs.Names {
+			if !name.IsExported() || isSentinelName(name.Name) || !c.holdsError(vs, i) {
+				continue
+			}
+
+			c.report(name, fmt.Sprintf("error variable '%s' should be named 'Err%s'", name.Name, strings.TrimSuffix(name.Name, "Error")), "name sentinel errors ErrXxx")
+		}
+	}
+}
+
+func (c *errorChecker) holdsError(vs *ast.ValueSpec, i int) bool {
+	if c.file.typed != nil {
+		obj := c.file.typed.info.Defs[vs.Names[i]]
+		return obj != nil && implementsError(obj.Type())
+	}
+
+	if i >= len(vs.Values) {
+		return false
+	}
+
+	call, ok := vs.Values[i].(*ast.CallExpr)
+
+	return ok && (isCall(call, c.errors, "New") || isCall(call, c.fmt, "Errorf"))
+}
+
+// checkComparison reports errors compared with == or != against anything but
+// nil, which misses wrapped errors.
+func (c *errorChecker) checkComparison(expr *ast.BinaryExpr) {
+	if c.allowEqual || expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+
+	if isNilIdent(expr.X) || isNilIdent(expr.Y) {
+		return
+	}
+
+	if !c.isComparedError(expr.X) && !c.isComparedError(expr.Y) {
+		return
+	}
+
+	suggestion := "use errors.Is(err, target)"
+	if expr.Op == token.NEQ {
+		suggestion = "use !errors.Is(err, target)"
+	}
+
+	c.report(expr, fmt.Sprintf("error compared with '%s' in '%s'", expr.Op, types.ExprString(expr)), suggestion)
+}
+
+func (c *errorChecker) isComparedError(expr ast.Expr) bool {
+	if c.file.typed != nil {
+		return c.file.typed.isError(expr)
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return isSentinelName(e.Name)
+	case *ast.SelectorExpr:
+		return isSentinelName(e.Sel.Name) || e.Sel.Name == "EOF"
+	}
+
+	return false
+}
+
+func isNilIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+
+	return ok && ident.Name == "nil"
+}
+
+// errorReturningFuncs are standard library functions whose last result is an
+// error, by import path, for discards found without type information.
+var errorReturningFuncs = map[string][]string{
+	"encoding/json": {"Marshal", "MarshalIndent", "Unmarshal"},
+	"fmt":           {"Fprint", "Fprintf", "Fprintln", "Sscan", "Sscanf"},
+	"io":            {"Copy", "CopyN", "ReadAll", "ReadFull", "WriteString"},
+	"net/http":      {"Get", "NewRequest", "NewRequestWithContext", "Post"},
+	"net/url":       {"Parse", "ParseQuery", "PathUnescape", "QueryUnescape"},
+	"os": {
+		"Chdir", "Chmod", "Create", "CreateTemp", "Executable", "Getwd",
+		"Hostname", "Lstat", "Mkdir", "MkdirAll", "MkdirTemp", "Open",
+		"OpenFile", "ReadDir", "ReadFile", "Readlink", "Remove", "RemoveAll",
+		"Rename", "Setenv", "Stat", "Symlink", "Unsetenv", "UserHomeDir",
+		"WriteFile",
+	},
+	"path":          {"Match"},
+	"path/filepath": {"Abs", "EvalSymlinks", "Glob", "Match", "Rel", "Walk", "WalkDir"},
+	"strconv":       {"Atoi", "ParseBool", "ParseFloat", "ParseInt", "ParseUint", "Unquote"},
+	"time":          {"LoadLocation", "Parse", "ParseDuration"},
+}
+
+// checkDiscard reports error results of a call assigned to '_'.
+func (c *errorChecker) checkDiscard(stmt *ast.AssignStmt) {
+	if len(stmt.Rhs) != 1 {
+		return
+	}
+
+	call, ok := stmt.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	for i, lhs := range stmt.Lhs {
+		if ident, ok := lhs.(*ast.Ident); !ok || ident.Name != "_" {
+			continue
+		}
+
+		if c.isErrorResult(call, i, len(stmt.Lhs)) {
+			c.report(lhs, fmt.Sprintf("error result of '%s' discarded with '_'", types.ExprString(call.Fun)), "handle the error or return it")
+		}
+	}
+}
+
+// isErrorResult reports whether result i of call, a call assigned to n
+// values, is an error. Without type information only the last result of
+// functions known to return an error is: those declared in the file with an
+// error result and errorReturningFuncs.
+func (c *errorChecker) isErrorResult(call *ast.CallExpr, i, n int) bool {
+	if c.file.typed != nil {
+		return c.file.typed.resultIsError(call, i, n)
+	}
+
+	if i != n-1 {
+		return false
+	}
+
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		decl, ok := objDecl(fun).(*ast.FuncDecl)
+
+		return ok && decl.Recv == nil && countFields(decl.Type.Results) == n && lastResultIsError(decl.Type.Results)
+	case *ast.SelectorExpr:
+		pkg, ok := fun.X.(*ast.Ident)
+		if !ok || pkg.Obj != nil {
+			return false
+		}
+
+		for importPath, names := range errorReturningFuncs {
+			if importedName(c.file.node, importPath) == pkg.Name && slices.Contains(names, fun.Sel.Name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func objDecl(ident *ast.Ident) any {
+	if ident.Obj == nil {
+		return nil
+	}
+
+	return ident.Obj.Decl
+}
+
+func lastResultIsError(results *ast.FieldList) bool {
+	if results == nil || len(results.List) == 0 {
+		return false
+	}
+
+	ident, ok := results.List[len(results.List)-1].Type.(*ast.Ident)
+
+	return ok && ident.Name == "error"
+}
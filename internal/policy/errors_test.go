@@ -0,0 +1,179 @@
+package policy
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const errorsSource = `package store
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var ErrNotFound = stderrors.New("not found")
+
+var (
+	Closed          = stderrors.New("closed")
+	TimeoutError    = fmt.Errorf("timeout")
+	DefaultName     = "store"
+	errInternal     = stderrors.New("internal")
+)
+
+type codeError struct{ code int }
+
+func (e codeError) Error() string { return strconv.Itoa(e.code) }
+
+func (e codeError) Is(target error) bool { return target == ErrNotFound }
+
+func Load(key string) (int, error) {
+	n, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q: %v", key, err)
+	}
+
+	if err == ErrNotFound || err != io.EOF {
+		return 0, stderrors.New("Failed to load.")
+	}
+
+	v, _ := strconv.Atoi(key)
+	_ = v
+
+	return n, fmt.Errorf("loaded %d%%: %s, %[1]d", n, err)
+}
+`
+
+func Test_findErrorHandlingViolations(t *testing.T) {
+	t.Run("reports error handling mistakes without type information", func(t *testing.T) {
+		file := parseSourceFile(token.NewFileSet(), "store.go", []byte(errorsSource))
+		require.NoError(t, file.parseErr)
+
+		assert.Equal(t, []string{
+			"store.go:13:2: error variable 'Closed' should be named 'ErrClosed'",
+			"store.go:14:2: error variable 'TimeoutError' should be named 'ErrTimeout'",
+			"store.go:28:55: error 'err' formatted with %v instead of wrapped",
+			"store.go:31:5: error compared with '==' in 'err == ErrNotFound'",
+			"store.go:31:27: error compared with '!=' in 'err != io.EOF'",
+			"store.go:32:27: error string \"Failed to load.\" starts with a capital letter",
+			"store.go:35:5: error result of 'strconv.Atoi' discarded with '_'",
+		}, violationStrings(findErrorHandlingViolations(file)))
+	})
+
+	t.Run("reports discards of known error results without type information", func(t *testing.T) {
+		file := parseSourceFile(token.NewFileSet(), "discard.go", []byte(`package store
+
+import (
+	"os"
+	"strings"
+)
+
+func save() error { return nil }
+
+func lookup(key string) (string, bool) { return key, true }
+
+func Close(path string, store interface{ Save() error }) {
+	_ = save()
+	_ = os.Remove(path)
+	_, _ = os.ReadFile(path)
+	_ = store.Save()
+	_, _ = lookup(path)
+	_, _, _ = strings.Cut(path, "/")
+
+	os := struct{ Remove func(string) error }{}
+	_ = os.Remove(path)
+}
+`))
+		require.NoError(t, file.parseErr)
+
+		assert.Equal(t, []string{
+			"discard.go:13:2: error result of 'save' discarded with '_'",
+			"discard.go:14:2: error result of 'os.Remove' discarded with '_'",
+			"discard.go:15:5: error result of 'os.ReadFile' discarded with '_'",
+		}, violationStrings(findErrorHandlingViolations(file)))
+	})
+
+	t.Run("allows == only inside Is methods", func(t *testing.T) {
+		file := parseSourceFile(token.NewFileSet(), "match.go", []byte(`package store
+
+func (e codeError) Is(target error) bool { return target == ErrNotFound }
+
+var matched = ErrNotFound == ErrClosed
+`))
+		require.NoError(t, file.parseErr)
+
+		assert.Equal(t, []string{
+			"match.go:5:15: error compared with '==' in 'ErrNotFound == ErrClosed'",
+		}, violationStrings(findErrorHandlingViolations(file)))
+	})
+
+	t.Run("recognizes errors and discards by type", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"store/store.go": errorsSource,
+			"store/typed.go": `package store
+
+import "fmt"
+
+var Fallback error = codeError{}
+
+func Parse(cause codeError, code int) error {
+	_, _ = Load("1")
+	if cause == (codeError{}) {
+		return fmt.Errorf("parse: %v", cause)
+	}
+
+	return fmt.Errorf("code %v", code)
+}
+`,
+		})
+
+		files := loadTypedFiles(t, nil)
+		require.NotNil(t, files["store/typed.go"].typed)
+
+		assert.Equal(t, []string{
+			"store/typed.go:5:5: error variable 'Fallback' should be named 'ErrFallback'",
+			"store/typed.go:8:5: error result of 'Load' discarded with '_'",
+			"store/typed.go:9:5: error compared with '==' in 'cause == (codeError{})'",
+			"store/typed.go:10:34: error 'cause' formatted with %v instead of wrapped",
+		}, violationStrings(findErrorHandlingViolations(files["store/typed.go"])))
+
+		assert.Contains(t, violationStrings(findErrorHandlingViolations(files["store/store.go"])),
+			"store/store.go:35:5: error result of 'strconv.Atoi' discarded with '_'")
+	})
+}
+
+func Test_errorStringProblem(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"failed to load", ""},
+		{"", ""},
+		{"HTTP request failed", ""},
+		{"GetName failed", ""},
+		{"Failed to load", "starts with a capital letter"},
+		{"failed to load.", "ends with punctuation"},
+		{"failed:", "ends with punctuation"},
+		{"failed\n", "ends with punctuation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorStringProblem(tt.message))
+		})
+	}
+}
+
+func Test_formatVerbs(t *testing.T) {
+	verbs, ok := formatVerbs("%d%% %-5s %*d %.2f %w")
+	require.True(t, ok)
+	assert.Equal(t, []rune{'d', 's', '*', 'd', 'f', 'w'}, verbs)
+
+	_, ok = formatVerbs("%[2]s %[1]s")
+	assert.False(t, ok)
+}
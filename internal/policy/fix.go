@@ -0,0 +1,1253 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+// maxFixRounds bounds how often the checks are rerun over fixed sources.
+// Fixes that overlap an earlier fix in the same round wait for the next one.
+const maxFixRounds = 5
+
+// fixableRules are the rules with a mechanical rewrite, in the order their
+// fixes are applied. Renames go first since they must land as a whole.
+var fixableRules = []string{
+	RuleGetterNaming,
+	RuleStuttering,
+	RuleStringConcat,
+	RuleCompositeLiteral,
+}
+
+// FixOptions tunes a fix run.
+type FixOptions struct {
+	// Jobs bounds how many checks and files are analyzed at once. Zero or
+	// less falls back to DefaultJobs.
+	Jobs int
+}
+
+// FixedFile is a file rewritten by FixGolang.
+type FixedFile struct {
+	Path     string
+	Original []byte
+	Fixed    []byte
+}
+
+// Diff returns the change as a unified diff.
+func (f FixedFile) Diff() string {
+	name := filepath.ToSlash(f.Path)
+
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(f.Original),
+		B:        diffLines(f.Fixed),
+		FromFile: fmt.Sprintf("a/%s", name),
+		ToFile:   fmt.Sprintf("b/%s", name),
+		Context:  3,
+	})
+
+	return diff
+}
+
+// diffLines splits src into lines that keep their line breaks.
+func diffLines(src []byte) []string {
+	lines := strings.SplitAfter(string(src), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// Write stores the fixed contents, keeping the file mode.
+func (f FixedFile) Write() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Path, err)
+	}
+
+	if err := os.WriteFile(f.Path, f.Fixed, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Path, err)
+	}
+
+	return nil
+}
+
+// fixEdit replaces src[start:end] of a file with text. An empty range is an
+// insertion.
+type fixEdit struct {
+	path  string
+	start int
+	end   int
+	text  string
+	// addImport is an import path the edited file needs afterwards.
+	addImport string
+}
+
+func (e fixEdit) overlaps(other fixEdit) bool {
+	return e.path == other.path && e.start < other.end && other.start < e.end
+}
+
+// fix is a single rewrite. Its edits are applied together or not at all, so
+// a rename never leaves call sites behind.
+type fix struct {
+	rule  string
+	edits []fixEdit
+}
+
+// violationKey identifies a reported violation by its position.
+type violationKey struct {
+	rule   string
+	file   string
+	line   int
+	column int
+}
+
+func keyOf(v Violation) violationKey {
+	return violationKey{rule: v.Rule, file: v.File, line: v.Line, column: v.Column}
+}
+
+// positionKey keys a violation at pos in file. The file path is the one the
+// checks report, whatever name the file set holds.
+func positionKey(rule string, file *sourceFile, pos token.Pos) violationKey {
+	position := file.fset.Position(pos)
+
+	return violationKey{rule: rule, file: file.path, line: position.Line, column: position.Column}
+}
+
+// FixGolang rewrites the violations of the enabled fixable rules and returns
+// the files that changed without writing them. Violations that are
+// suppressed or out of scope are left alone; those without a safe rewrite
+// are left as well and logged as not fixable.
+func FixGolang(opts FixOptions) ([]FixedFile, error) {
+	log.Println("Fixing Go policy violations...")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = DefaultJobs()
+	}
+
+	// Excluded files are indexed as well so renames also update references
+	// inside them; their own violations are dropped by filterScoped.
+	idx, err := buildFileIndex(".", jobs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	modulePath := ""
+	if data, err := os.ReadFile("go.mod"); err == nil {
+		modulePath = parseModulePath(string(data))
+	}
+
+	original := make(map[string][]byte, len(idx.files))
+	for _, file := range idx.files {
+		original[file.path] = file.src
+	}
+
+	applied := make(map[string]int)
+	typeCheck := resolveTypeCheck(cfg.Policy)
+
+	var remaining []Violation
+
+	for round := 0; ; round++ {
+		if typeCheck {
+			attachTypes(idx, cfg.Tests.Tags)
+		}
+
+		violations := fixableViolations(idx, cfg)
+
+		remaining = violations
+		if len(violations) == 0 || round == maxFixRounds {
+			break
+		}
+
+		fixes := collectFixes(idx, cfg, modulePath, violations)
+
+		contents, counts, err := applyFixes(idx, pickFixes(fixes))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(counts) == 0 {
+			break
+		}
+
+		for rule, count := range counts {
+			applied[rule] += count
+		}
+
+		idx = reindex(idx, contents)
+	}
+
+	for _, rule := range fixableRules {
+		if applied[rule] > 0 {
+			log.Printf("Applied %d %s fixes", applied[rule], rule)
+		}
+	}
+
+	sortViolations(remaining)
+
+	for _, v := range remaining {
+		log.Printf("No safe fix for %s", v)
+	}
+
+	var fixed []FixedFile
+
+	for _, file := range idx.files {
+		if !bytes.Equal(original[file.path], file.src) {
+			fixed = append(fixed, FixedFile{
+				Path:     file.path,
+				Original: original[file.path],
+				Fixed:    file.src,
+			})
+		}
+	}
+
+	return fixed, nil
+}
+
+// fixableViolations runs the enabled fixable checks and keeps the violations
+// that survive //yake:ignore directives and path scopes.
+func fixableViolations(idx *fileIndex, cfg *config.Config) []Violation {
+	var violations []Violation
+
+	for _, policyCheck := range golangPolicyChecks(cfg) {
+		if !slices.Contains(fixableRules, policyCheck.rule) || !policyCheck.active() {
+			continue
+		}
+
+		found, err := policyCheck.run(idx)
+		if err != nil {
+			continue
+		}
+
+		violations = append(violations, found...)
+	}
+
+	violations = applySuppressions(idx, violations, suppressionOptions{})
+
+	return filterScoped(violations, cfg.Policy)
+}
+
+func collectFixes(idx *fileIndex, cfg *config.Config, modulePath string, violations []Violation) []fix {
+	flagged := make(map[violationKey]bool, len(violations))
+	for _, v := range violations {
+		flagged[keyOf(v)] = true
+	}
+
+	var fixes []fix
+
+	fixes = append(fixes, getterFixes(idx, flagged)...)
+	fixes = append(fixes, stutteringFixes(idx, flagged, modulePath)...)
+	fixes = append(fixes, stringConcatFixes(idx, flagged)...)
+	fixes = append(fixes, compositeLiteralFixes(idx, flagged, resolveMaxSingleLineFields(cfg.Policy.CompositeLiteral))...)
+
+	return fixes
+}
+
+// pickFixes keeps the fixes whose edits do not overlap an already picked fix.
+// Identical edits, such as a rename reached twice, are merged.
+func pickFixes(fixes []fix) []fix {
+	var (
+		picked []fix
+		edits  []fixEdit
+	)
+
+	for _, candidate := range fixes {
+		var fresh []fixEdit
+
+		conflict := false
+
+		for _, edit := range candidate.edits {
+			if slices.Contains(edits, edit) || slices.Contains(fresh, edit) {
+				continue
+			}
+
+			if slices.ContainsFunc(edits, edit.overlaps) || slices.ContainsFunc(fresh, edit.overlaps) {
+				conflict = true
+
+				break
+			}
+
+			fresh = append(fresh, edit)
+		}
+
+		if conflict {
+			continue
+		}
+
+		edits = append(edits, fresh...)
+		picked = append(picked, fix{rule: candidate.rule, edits: fresh})
+	}
+
+	return picked
+}
+
+// applyFixes applies the edits to the indexed sources and formats every
+// edited file. It returns the new contents of every file and the number of
+// fixes applied per rule.
+func applyFixes(idx *fileIndex, fixes []fix) (map[string][]byte, map[string]int, error) {
+	byFile := make(map[string][]fixEdit)
+	counts := make(map[string]int)
+
+	for _, f := range fixes {
+		for _, edit := range f.edits {
+			byFile[edit.path] = append(byFile[edit.path], edit)
+		}
+
+		counts[f.rule]++
+	}
+
+	contents := make(map[string][]byte, len(idx.files))
+
+	for _, file := range idx.files {
+		edits, ok := byFile[file.path]
+		if !ok {
+			contents[file.path] = file.src
+			continue
+		}
+
+		src, err := applyEdits(file.path, file.src, edits)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		contents[file.path] = src
+	}
+
+	return contents, counts, nil
+}
+
+func applyEdits(path string, src []byte, edits []fixEdit) ([]byte, error) {
+	slices.SortStableFunc(edits, func(a, b fixEdit) int {
+		return b.start - a.start
+	})
+
+	out := slices.Clone(src)
+
+	var imports []string
+
+	for _, edit := range edits {
+		out = slices.Concat(out[:edit.start], []byte(edit.text), out[edit.end:])
+
+		if edit.addImport != "" && !slices.Contains(imports, edit.addImport) {
+			imports = append(imports, edit.addImport)
+		}
+	}
+
+	if len(imports) > 0 {
+		fset := token.NewFileSet()
+
+		node, err := parseFixed(fset, path, out)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, importPath := range imports {
+			astutil.AddImport(fset, node, importPath)
+		}
+
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, node); err != nil {
+			return nil, fmt.Errorf("failed to format %s after fixes: %w", path, err)
+		}
+
+		out = buf.Bytes()
+	}
+
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s after fixes: %w", path, err)
+	}
+
+	return formatted, nil
+}
+
+func parseFixed(fset *token.FileSet, path string, src []byte) (*ast.File, error) {
+	file := parseSourceFile(fset, path, src)
+	if file.parseErr != nil {
+		return nil, fmt.Errorf("failed to format %s after fixes: %w", path, file.parseErr)
+	}
+
+	return file.node, nil
+}
+
+// reindex parses new contents for the files of idx.
+func reindex(idx *fileIndex, contents map[string][]byte) *fileIndex {
+	next := &fileIndex{
+		fset: token.NewFileSet(),
+		pool: idx.pool,
+	}
+
+	next.files = parallelMap(idx.pool, idx.files, func(file *sourceFile) *sourceFile {
+		return parseSourceFile(next.fset, file.path, contents[file.path])
+	})
+
+	return next
+}
+
+// offset returns the byte offset of pos within its file.
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
+
+func identEdit(file *sourceFile, ident *ast.Ident, name string) fixEdit {
+	return fixEdit{
+		path:  file.path,
+		start: offset(file.fset, ident.Pos()),
+		end:   offset(file.fset, ident.End()),
+		text:  name,
+	}
+}
+
+// docRenameEdit renames the leading identifier of a doc comment, keeping
+// godoc sentences such as "GetName returns ..." in step with the code.
+func docRenameEdit(file *sourceFile, doc *ast.CommentGroup, oldName, newName string) (fixEdit, bool) {
+	if doc == nil || len(doc.List) == 0 {
+		return fixEdit{}, false
+	}
+
+	first := doc.List[0]
+
+	rest, ok := strings.CutPrefix(first.Text, "// ")
+	if !ok || !strings.HasPrefix(rest, oldName) {
+		return fixEdit{}, false
+	}
+
+	if tail := rest[len(oldName):]; tail != "" && tail[0] != ' ' {
+		return fixEdit{}, false
+	}
+
+	start := offset(file.fset, first.Slash) + len("// ")
+
+	return fixEdit{
+		path:  file.path,
+		start: start,
+		end:   start + len(oldName),
+		text:  newName,
+	}, true
+}
+
+// packageFiles returns the files of the package declared in dir, including
+// its in-package tests.
+func packageFiles(idx *fileIndex, dir, pkgName string) []*sourceFile {
+	var files []*sourceFile
+
+	for _, file := range idx.files {
+		if filepath.Dir(file.path) == dir && file.packageName() == pkgName && file.parsed() != nil {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// receiverName returns the base type name of a method receiver.
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := fn.Recv.List[0].Type
+
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// getterFixes renames flagged GetX methods to X. A name is only renamed when
+// every method declared with it is flagged, no interface in the project
+// declares it, no receiver already has a member called X, and type
+// information resolves every selector of that name, so fields and methods of
+// the same name on other types, such as http.Request.GetBody, are never
+// touched. Without type information getters are left to be fixed by hand.
+func getterFixes(idx *fileIndex, flagged map[violationKey]bool) []fix {
+	type getter struct {
+		file *sourceFile
+		fn   *ast.FuncDecl
+	}
+
+	declared := make(map[string][]getter)
+	interfaceMethods := make(map[string]bool)
+
+	for _, file := range idx.files {
+		node := file.parsed()
+		if node == nil {
+			continue
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.FuncDecl:
+				if x.Recv != nil {
+					declared[x.Name.Name] = append(declared[x.Name.Name], getter{file: file, fn: x})
+				}
+			case *ast.InterfaceType:
+				for _, method := range x.Methods.List {
+					for _, name := range method.Names {
+						interfaceMethods[name.Name] = true
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	var names []string
+
+	for name, getters := range declared {
+		allFlagged := true
+
+		for _, g := range getters {
+			if !flagged[positionKey(RuleGetterNaming, g.file, g.fn.Pos())] {
+				allFlagged = false
+			}
+		}
+
+		if allFlagged && !interfaceMethods[name] {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	var fixes []fix
+
+	for _, oldName := range names {
+		newName := strings.TrimPrefix(oldName, "Get")
+
+		var edits []fixEdit
+
+		conflict := false
+		methods := make(map[token.Pos]bool, len(declared[oldName]))
+
+		for _, g := range declared[oldName] {
+			methods[g.fn.Name.Pos()] = true
+
+			dir := filepath.Dir(g.file.path)
+			recv := receiverName(g.fn)
+
+			if recv == "" || g.file.typed == nil || hasMember(packageFiles(idx, dir, g.file.packageName()), recv, newName) {
+				conflict = true
+
+				break
+			}
+
+			edits = append(edits, identEdit(g.file, g.fn.Name, newName))
+
+			if edit, ok := docRenameEdit(g.file, g.fn.Doc, oldName, newName); ok {
+				edits = append(edits, edit)
+			}
+		}
+
+		if conflict {
+			continue
+		}
+
+		calls, ok := getterCallEdits(idx, methods, oldName, newName)
+		if !ok {
+			continue
+		}
+
+		edits = append(edits, calls...)
+
+		fixes = append(fixes, fix{rule: RuleGetterNaming, edits: edits})
+	}
+
+	return fixes
+}
+
+// getterCallEdits renames the call sites of the getter methods declared at
+// the given positions. ok is false when a selector of that name cannot be
+// resolved by type, since it may refer to another type's member.
+func getterCallEdits(idx *fileIndex, methods map[token.Pos]bool, oldName, newName string) ([]fixEdit, bool) {
+	var edits []fixEdit
+
+	ok := true
+
+	for _, file := range idx.files {
+		node := file.parsed()
+		if node == nil {
+			continue
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			sel, isSel := n.(*ast.SelectorExpr)
+			if !isSel || sel.Sel.Name != oldName {
+				return true
+			}
+
+			pos := selectedPos(file, sel)
+
+			switch {
+			case !pos.IsValid():
+				ok = false
+			case methods[pos]:
+				edits = append(edits, identEdit(file, sel.Sel, newName))
+			}
+
+			return true
+		})
+	}
+
+	return edits, ok
+}
+
+// selectedPos returns the declaration position of the member or function sel
+// refers to, or token.NoPos when the file has no type information for it.
+func selectedPos(file *sourceFile, sel *ast.SelectorExpr) token.Pos {
+	if file.typed == nil {
+		return token.NoPos
+	}
+
+	if selection, ok := file.typed.info.Selections[sel]; ok {
+		return selection.Obj().Pos()
+	}
+
+	if obj := file.typed.info.Uses[sel.Sel]; obj != nil {
+		return obj.Pos()
+	}
+
+	return token.NoPos
+}
+
+// hasMember reports whether the named type of a package has a method or a
+// struct field called member.
+func hasMember(files []*sourceFile, typeName, member string) bool {
+	for _, file := range files {
+		for _, decl := range file.node.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Name.Name == member && receiverName(d) == typeName {
+					return true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || ts.Name.Name != typeName {
+						continue
+					}
+
+					if st, ok := ts.Type.(*ast.StructType); ok && hasField(st, member) {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func hasField(st *ast.StructType, name string) bool {
+	for _, field := range st.Fields.List {
+		if fieldNamed(field, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldNamed reports whether a struct field, embedded ones included, is
+// called name.
+func fieldNamed(field *ast.Field, name string) bool {
+	if len(field.Names) == 0 {
+		return embeddedName(field.Type) == name
+	}
+
+	for _, ident := range field.Names {
+		if ident.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// stutterTarget is a flagged top-level declaration of a package.
+type stutterTarget struct {
+	file    *sourceFile
+	ident   *ast.Ident
+	doc     *ast.CommentGroup
+	oldName string
+	newName string
+}
+
+// stutteringFixes drops the package name prefix from flagged declarations and
+// updates every reference in the package and in the packages importing it.
+// A rename is skipped when the new name is already used in the package, the
+// old name is shadowed somewhere, a struct field shares the old name, or the
+// package is dot-imported.
+func stutteringFixes(idx *fileIndex, flagged map[violationKey]bool, modulePath string) []fix {
+	var targets []stutterTarget
+
+	for _, file := range idx.sources() {
+		node := file.parsed()
+		if node == nil || file.packageName() == "main" {
+			continue
+		}
+
+		pkgUpper := fmt.Sprintf("%s%s", strings.ToUpper(node.Name.Name[:1]), node.Name.Name[1:])
+
+		add := func(ident *ast.Ident, pos token.Pos, doc *ast.CommentGroup) {
+			if !flagged[positionKey(RuleStuttering, file, pos)] {
+				return
+			}
+
+			targets = append(targets, stutterTarget{
+				file:    file,
+				ident:   ident,
+				doc:     doc,
+				oldName: ident.Name,
+				newName: ident.Name[len(pkgUpper):],
+			})
+		}
+
+		for _, decl := range node.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					add(d.Name, d.Pos(), d.Doc)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						add(s.Name, s.Pos(), specDoc(s.Doc, d))
+					case *ast.ValueSpec:
+						for _, ident := range s.Names {
+							add(ident, ident.Pos(), specDoc(s.Doc, d))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	fieldNames := structFieldNames(idx)
+
+	var fixes []fix
+
+	for _, target := range targets {
+		if fieldNames[target.oldName] {
+			continue
+		}
+
+		if edits, ok := stutteringEdits(idx, target, modulePath); ok {
+			fixes = append(fixes, fix{rule: RuleStuttering, edits: edits})
+		}
+	}
+
+	return fixes
+}
+
+// specDoc returns the spec's own doc comment, or the declaration's doc when
+// the declaration holds a single spec.
+func specDoc(doc *ast.CommentGroup, decl *ast.GenDecl) *ast.CommentGroup {
+	if doc == nil && len(decl.Specs) == 1 {
+		return decl.Doc
+	}
+
+	return doc
+}
+
+func structFieldNames(idx *fileIndex) map[string]bool {
+	names := make(map[string]bool)
+
+	for _, file := range idx.files {
+		node := file.parsed()
+		if node == nil {
+			continue
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			st, ok := n.(*ast.StructType)
+			if !ok {
+				return true
+			}
+
+			for _, field := range st.Fields.List {
+				for _, ident := range field.Names {
+					names[ident.Name] = true
+				}
+
+				if len(field.Names) == 0 {
+					names[embeddedName(field.Type)] = true
+				}
+			}
+
+			return true
+		})
+	}
+
+	return names
+}
+
+func stutteringEdits(idx *fileIndex, target stutterTarget, modulePath string) ([]fixEdit, bool) {
+	dir := filepath.Dir(target.file.path)
+	pkgName := target.file.packageName()
+
+	var edits []fixEdit
+
+	for _, file := range packageFiles(idx, dir, pkgName) {
+		fileEdits, ok := packageRenameEdits(file, target.oldName, target.newName)
+		if !ok {
+			return nil, false
+		}
+
+		edits = append(edits, fileEdits...)
+	}
+
+	if edit, ok := docRenameEdit(target.file, target.doc, target.oldName, target.newName); ok {
+		edits = append(edits, edit)
+	}
+
+	importPath := modulePath
+	if dir != "." {
+		importPath = fmt.Sprintf("%s/%s", modulePath, filepath.ToSlash(dir))
+	}
+
+	for _, file := range idx.files {
+		node := file.parsed()
+		if node == nil || modulePath == "" {
+			continue
+		}
+
+		name, ok := importName(node, importPath, pkgName)
+		if !ok {
+			continue
+		}
+
+		if name == "." {
+			return nil, false
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != target.oldName {
+				return true
+			}
+
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == name && x.Obj == nil {
+				edits = append(edits, identEdit(file, sel.Sel, target.newName))
+			}
+
+			return true
+		})
+	}
+
+	return edits, true
+}
+
+// packageRenameEdits renames the references to a package-level identifier
+// within one file of its package. It fails when the new name is already in
+// use or the old name is shadowed by a local declaration.
+func packageRenameEdits(file *sourceFile, oldName, newName string) ([]fixEdit, bool) {
+	// members are identifiers in another namespace than package-level
+	// names: selected names, method names and labels.
+	members := make(map[*ast.Ident]bool)
+	topLevel := file.node.Scope.Lookup(oldName)
+
+	var edits []fixEdit
+
+	ok := true
+
+	ast.Inspect(file.node, func(n ast.Node) bool {
+		if !ok {
+			return false
+		}
+
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			members[x.Sel] = true
+		case *ast.FuncDecl:
+			if x.Recv != nil {
+				members[x.Name] = true
+			}
+		case *ast.InterfaceType:
+			for _, method := range x.Methods.List {
+				for _, name := range method.Names {
+					members[name] = true
+				}
+			}
+		case *ast.LabeledStmt:
+			members[x.Label] = true
+		case *ast.BranchStmt:
+			if x.Label != nil {
+				members[x.Label] = true
+			}
+		case *ast.Ident:
+			switch {
+			case members[x]:
+			case x.Name == newName:
+				ok = false
+			case x.Name != oldName:
+			case x.Obj != nil && x.Obj != topLevel:
+				ok = false
+			default:
+				edits = append(edits, identEdit(file, x, newName))
+			}
+		}
+
+		return true
+	})
+
+	return edits, ok
+}
+
+// importName returns the name a file uses for an imported package.
+func importName(node *ast.File, importPath, pkgName string) (string, bool) {
+	for _, spec := range node.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err != nil || path != importPath {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name, true
+		}
+
+		return pkgName, true
+	}
+
+	return "", false
+}
+
+// importedName returns the name a file uses for an imported standard library
+// package, or an empty string when the file does not import it.
+func importedName(node *ast.File, importPath string) string {
+	name, _ := importName(node, importPath, path.Base(importPath))
+
+	return name
+}
+
+// stringConcatFixes rewrites flagged '+' chains into one fmt.Sprintf call,
+// or into a single literal when every operand is a literal. Chains in
+// constant declarations are only merged, since fmt.Sprintf is not constant.
+func stringConcatFixes(idx *fileIndex, flagged map[violationKey]bool) []fix {
+	var fixes []fix
+
+	for _, file := range idx.files {
+		node := file.parsed()
+		if node == nil {
+			continue
+		}
+
+		fmtName, addImport, fmtOK := fmtImport(node)
+
+		var constDecls []*ast.GenDecl
+
+		for _, decl := range node.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.CONST {
+				constDecls = append(constDecls, gd)
+			}
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			binExpr, ok := n.(*ast.BinaryExpr)
+			if !ok || binExpr.Op != token.ADD || !isStringConcat(file, binExpr) {
+				return true
+			}
+
+			if !flagged[positionKey(RuleStringConcat, file, binExpr.OpPos)] {
+				return false
+			}
+
+			inConst := slices.ContainsFunc(constDecls, func(gd *ast.GenDecl) bool {
+				return binExpr.Pos() >= gd.Pos() && binExpr.End() <= gd.End()
+			})
+
+			text, sprintf, ok := concatReplacement(file, binExpr, fmtName)
+			if !ok || (sprintf && (inConst || !fmtOK)) {
+				return false
+			}
+
+			edit := fixEdit{
+				path:  file.path,
+				start: offset(file.fset, binExpr.Pos()),
+				end:   offset(file.fset, binExpr.End()),
+				text:  text,
+			}
+
+			if sprintf && addImport {
+				edit.addImport = "fmt"
+			}
+
+			fixes = append(fixes, fix{rule: RuleStringConcat, edits: []fixEdit{edit}})
+
+			return false
+		})
+	}
+
+	return fixes
+}
+
+// fmtImport returns the name to call fmt by and whether the import must be
+// added. ok is false when fmt is blank or dot imported, or when the name is
+// taken by a declaration of the file.
+func fmtImport(node *ast.File) (name string, add bool, ok bool) {
+	name, imported := importName(node, "fmt", "fmt")
+
+	switch {
+	case !imported:
+		return "fmt", true, node.Scope.Lookup("fmt") == nil
+	case name == "_" || name == ".":
+		return "", false, false
+	default:
+		return name, false, true
+	}
+}
+
+// concatReplacement builds the replacement for a '+' chain. sprintf reports
+// whether the result calls fmt.Sprintf.
+func concatReplacement(file *sourceFile, binExpr *ast.BinaryExpr, fmtName string) (text string, sprintf bool, ok bool) {
+	var (
+		literal strings.Builder
+		format  strings.Builder
+		args    []string
+	)
+
+	for _, operand := range concatOperands(binExpr) {
+		if lit, isLit := operand.(*ast.BasicLit); isLit && lit.Kind == token.STRING {
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return "", false, false
+			}
+
+			literal.WriteString(value)
+			format.WriteString(strings.ReplaceAll(value, "%", "%%"))
+
+			continue
+		}
+
+		// %s would print the String or Error method instead of the
+		// underlying string.
+		if file.typed != nil && file.typed.hasStringMethod(operand) {
+			return "", false, false
+		}
+
+		format.WriteString("%s")
+		args = append(args, string(file.src[offset(file.fset, operand.Pos()):offset(file.fset, operand.End())]))
+	}
+
+	if len(args) == 0 {
+		return strconv.Quote(literal.String()), false, true
+	}
+
+	// fmt.Sprintf returns a plain string, which does not fit where the chain
+	// has a named string type.
+	if !isPlainStringConcat(file, binExpr) {
+		return "", false, false
+	}
+
+	return fmt.Sprintf("%s.Sprintf(%s, %s)", fmtName, strconv.Quote(format.String()), strings.Join(args, ", ")), true, true
+}
+
+// isPlainStringConcat reports whether a '+' chain has type string rather than
+// a named string type. Without type information a chain counts as a plain
+// string when one of its operands is declared as one, since Go does not mix
+// typed strings of different types in one expression.
+func isPlainStringConcat(file *sourceFile, binExpr *ast.BinaryExpr) bool {
+	if file.typed != nil {
+		return file.typed.isPlainString(binExpr)
+	}
+
+	return slices.ContainsFunc(concatOperands(binExpr), isDeclaredString)
+}
+
+// isDeclaredString reports whether expr is a variable or parameter declared
+// as a string, by type or by a string literal value, or a conversion to
+// string.
+func isDeclaredString(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return isDeclaredString(e.X)
+	case *ast.CallExpr:
+		return isStringType(e.Fun) && len(e.Args) == 1
+	case *ast.Ident:
+		if e.Obj == nil || e.Obj.Kind != ast.Var {
+			return false
+		}
+
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.Field:
+			return isStringType(decl.Type)
+		case *ast.ValueSpec:
+			if decl.Type != nil {
+				return isStringType(decl.Type)
+			}
+
+			return declaredValueIsString(identsOf(decl.Names), decl.Values, e.Name)
+		case *ast.AssignStmt:
+			return decl.Tok == token.DEFINE && declaredValueIsString(decl.Lhs, decl.Rhs, e.Name)
+		}
+	}
+
+	return false
+}
+
+// declaredValueIsString reports whether the value assigned to name in a
+// declaration without a type is a string literal or a declared string.
+func declaredValueIsString(names, values []ast.Expr, name string) bool {
+	if len(names) != len(values) {
+		return false
+	}
+
+	for i, lhs := range names {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+			if lit, ok := values[i].(*ast.BasicLit); ok {
+				return lit.Kind == token.STRING
+			}
+
+			return isDeclaredString(values[i])
+		}
+	}
+
+	return false
+}
+
+func identsOf(idents []*ast.Ident) []ast.Expr {
+	exprs := make([]ast.Expr, len(idents))
+	for i, ident := range idents {
+		exprs[i] = ident
+	}
+
+	return exprs
+}
+
+// isStringType reports whether expr names the predeclared string type.
+func isStringType(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+
+	return ok && ident.Name == "string" && ident.Obj == nil
+}
+
+// concatOperands flattens a '+' chain into its operands, left to right.
+func concatOperands(expr ast.Expr) []ast.Expr {
+	binExpr, ok := expr.(*ast.BinaryExpr)
+	if !ok || binExpr.Op != token.ADD {
+		return []ast.Expr{expr}
+	}
+
+	return slices.Concat(concatOperands(binExpr.X), concatOperands(binExpr.Y))
+}
+
+// compositeLiteralFixes puts every element of a flagged composite literal on
+// its own line.
+func compositeLiteralFixes(idx *fileIndex, flagged map[violationKey]bool, maxSingleLineFields int) []fix {
+	var fixes []fix
+
+	for _, file := range idx.files {
+		node := file.parsed()
+		if node == nil || file.skip {
+			continue
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			comp, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+
+			for line := range crowdedLines(file.fset, comp, maxSingleLineFields) {
+				key := violationKey{rule: RuleCompositeLiteral, file: file.path, line: line}
+				if flagged[key] {
+					fixes = append(fixes, fix{rule: RuleCompositeLiteral, edits: splitLiteralEdits(file, comp)})
+
+					break
+				}
+			}
+
+			return true
+		})
+	}
+
+	return fixes
+}
+
+// splitLiteralEdits inserts line breaks before every element that shares a
+// line with the previous one, and before the closing brace.
+func splitLiteralEdits(file *sourceFile, comp *ast.CompositeLit) []fixEdit {
+	line := func(pos token.Pos) int {
+		return file.fset.Position(pos).Line
+	}
+
+	insert := func(pos token.Pos, text string) fixEdit {
+		at := offset(file.fset, pos)
+		return fixEdit{path: file.path, start: at, end: at, text: text}
+	}
+
+	var edits []fixEdit
+
+	prevLine := line(comp.Lbrace)
+
+	for _, elt := range comp.Elts {
+		if line(elt.Pos()) == prevLine {
+			edits = append(edits, insert(elt.Pos(), "\n"))
+		}
+
+		prevLine = line(elt.End())
+	}
+
+	if last := comp.Elts[len(comp.Elts)-1]; line(last.End()) == line(comp.Rbrace) {
+		between := file.src[offset(file.fset, last.End()):offset(file.fset, comp.Rbrace)]
+
+		text := ",\n"
+		if bytes.Contains(between, []byte(",")) {
+			text = "\n"
+		}
+
+		edits = append(edits, insert(comp.Rbrace, text))
+	}
+
+	return edits
+}
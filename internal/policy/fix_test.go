@@ -0,0 +1,485 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFixProject creates a module in a temporary directory, changes into it
+// and writes the given files.
+func writeFixProject(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(originalDir) })
+
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/fx\n\ngo 1.21\n"), 0644))
+
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// fixedContents runs FixGolang and returns the fixed contents by path.
+func fixedContents(t *testing.T) map[string]string {
+	t.Helper()
+
+	fixed, err := FixGolang(FixOptions{Jobs: 2})
+	require.NoError(t, err)
+
+	contents := make(map[string]string, len(fixed))
+	for _, file := range fixed {
+		contents[filepath.ToSlash(file.Path)] = string(file.Fixed)
+	}
+
+	return contents
+}
+
+func TestFixGolang(t *testing.T) {
+	t.Run("renames stuttering declarations and getters across packages", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  type_check: true\n",
+			"store/store.go": `package store
+
+// StoreItem is an item.
+type StoreItem struct {
+	Name string
+}
+
+type Repo struct{ name string }
+
+// GetName returns the name.
+func (r *Repo) GetName() string { return r.name }
+
+func Describe(r *Repo, item StoreItem) StoreItem {
+	return StoreItem{Name: r.GetName()}
+}
+`,
+			"app/app.go": `package app
+
+import s "example.com/fx/store"
+
+func Use() string {
+	return s.Describe(&s.Repo{}, s.StoreItem{}).Name
+}
+`,
+		})
+
+		contents := fixedContents(t)
+
+		assert.Equal(t, `package store
+
+// Item is an item.
+type Item struct {
+	Name string
+}
+
+type Repo struct{ name string }
+
+// Name returns the name.
+func (r *Repo) Name() string { return r.name }
+
+func Describe(r *Repo, item Item) Item {
+	return Item{Name: r.Name()}
+}
+`, contents["store/store.go"])
+		assert.Contains(t, contents["app/app.go"], "s.Describe(&s.Repo{}, s.Item{}).Name")
+	})
+
+	t.Run("rewrites concatenation and splits literals", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  composite_literal:\n    max_single_line_fields: 1\n",
+			"main.go": `package main
+
+const greeting = "hello " + "world"
+
+type point struct{ X, Y, Z int }
+
+func main() {
+	name := "x"
+	_ = "100% " + name + "!"
+	_ = point{X: 1, Y: 2,}
+	_ = point{X: 1,
+		Y: 2, Z: 3}
+}
+`,
+		})
+
+		assert.Equal(t, `package main
+
+import "fmt"
+
+const greeting = "hello world"
+
+type point struct{ X, Y, Z int }
+
+func main() {
+	name := "x"
+	_ = fmt.Sprintf("100%% %s!", name)
+	_ = point{
+		X: 1,
+		Y: 2,
+	}
+	_ = point{
+		X: 1,
+		Y: 2,
+		Z: 3,
+	}
+}
+`, fixedContents(t)["main.go"])
+	})
+
+	t.Run("leaves suppressed, disabled and excluded violations alone", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  exclude:\n    - gen\n  composite_literal:\n    enable: false\n",
+			"main.go": `package main
+
+type point struct{ X, Y int }
+
+func main() {
+	name := "x"
+	_ = "a" + name //yake:ignore string_concat -- kept
+	_ = point{X: 1, Y: 2, X: 3, Y: 4, X: 5, Y: 6}
+}
+`,
+			"gen/gen.go": "package gen\n\nvar Name = \"a\" + string(rune(1))\n",
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+
+	t.Run("resolves getter call sites and string types with type information", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  type_check: true\n",
+			"pb/msg.pb.go": `package pb
+
+type Msg struct{ Label string }
+
+func (m *Msg) GetLabel() string { return m.Label }
+`,
+			"svc/svc.go": `package svc
+
+import "example.com/fx/pb"
+
+type Path string
+
+type Service struct{ label string }
+
+func (s *Service) GetLabel() string { return s.label }
+
+func Labels(s *Service, m *pb.Msg) (string, string) {
+	return s.GetLabel(), m.GetLabel()
+}
+
+func Join(base Path) Path {
+	return base + "/x"
+}
+`,
+		})
+
+		contents := fixedContents(t)["svc/svc.go"]
+
+		assert.Contains(t, contents, "func (s *Service) Label() string { return s.label }")
+		assert.Contains(t, contents, "return s.Label(), m.GetLabel()")
+		assert.Contains(t, contents, "return base + \"/x\"")
+	})
+
+	t.Run("returns error for invalid config", func(t *testing.T) {
+		writeFixProject(t, map[string]string{".yake.yaml": "policy: [\n"})
+
+		_, err := FixGolang(FixOptions{})
+		require.Error(t, err)
+	})
+}
+
+func Test_getterFixes(t *testing.T) {
+	t.Run("skips names declared by an interface", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  type_check: true\n",
+			"store/store.go": `package store
+
+type Namer interface{ GetName() string }
+
+type Repo struct{}
+
+func (Repo) GetName() string { return "" }
+`,
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+
+	t.Run("skips receivers that already have the member", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  type_check: true\n",
+			"store/store.go": `package store
+
+type Repo struct{ Name string }
+
+func (r Repo) GetName() string { return r.Name }
+
+type Other struct{}
+
+func (Other) GetID() int { return 1 }
+
+func (Other) ID() int { return 2 }
+`,
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+
+	t.Run("skips names also declared by unflagged methods", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			".yake.yaml": "policy:\n  type_check: true\n",
+			"store/store.go": `package store
+
+type Repo struct{}
+
+func (Repo) GetName() string { return "" }
+
+type Other struct{}
+
+func (Other) GetName(id int) string { return "" }
+`,
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+
+	t.Run("skips getters without type information", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"pb/msg.pb.go": `package pb
+
+type Msg struct{ Label string }
+
+func (m *Msg) GetLabel() string { return m.Label }
+`,
+			"svc/svc.go": `package svc
+
+import "example.com/fx/pb"
+
+type Service struct{ label string }
+
+func (s *Service) GetLabel() string { return s.label }
+
+func Labels(s *Service, m *pb.Msg) (string, string) {
+	return s.GetLabel(), m.GetLabel()
+}
+`,
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+}
+
+func Test_stutteringFixes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "new name already used",
+			files: map[string]string{
+				"store/store.go": "package store\n\ntype StoreItem struct{}\n\ntype Item struct{}\n",
+			},
+		},
+		{
+			name: "old name shadowed locally",
+			files: map[string]string{
+				"store/store.go": "package store\n\nfunc StoreRun() {}\n\nfunc f() {\n\tStoreRun := 1\n\t_ = StoreRun\n}\n",
+			},
+		},
+		{
+			name: "struct field with old name",
+			files: map[string]string{
+				"store/store.go": "package store\n\ntype StoreKind int\n\ntype config struct{ StoreKind StoreKind }\n",
+			},
+		},
+		{
+			name: "dot import",
+			files: map[string]string{
+				"store/store.go": "package store\n\nfunc StoreRun() {}\n",
+				"app/app.go":     "package app\n\nimport . \"example.com/fx/store\"\n\nfunc Use() { StoreRun() }\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("skips rename when %s", tt.name), func(t *testing.T) {
+			writeFixProject(t, tt.files)
+
+			assert.Empty(t, fixedContents(t))
+		})
+	}
+
+	t.Run("keeps methods and labels with the old name", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"store/store.go": `package store
+
+type StoreMode int
+
+type repo struct{}
+
+func (repo) StoreMode() StoreMode { return 0 }
+
+func loop() {
+StoreMode:
+	for {
+		break StoreMode
+	}
+}
+`,
+		})
+
+		assert.Equal(t, `package store
+
+type Mode int
+
+type repo struct{}
+
+func (repo) StoreMode() Mode { return 0 }
+
+func loop() {
+StoreMode:
+	for {
+		break StoreMode
+	}
+}
+`, fixedContents(t)["store/store.go"])
+	})
+}
+
+func Test_stringConcatFixes(t *testing.T) {
+	t.Run("uses an aliased fmt import", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"main.go": "package main\n\nimport f \"fmt\"\n\nfunc main() {\n\tname := \"x\"\n\tf.Println(`a` + name)\n}\n",
+		})
+
+		assert.Contains(t, fixedContents(t)["main.go"], "f.Println(f.Sprintf(\"a%s\", name))")
+	})
+
+	t.Run("skips files with blank fmt import", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"main.go": "package main\n\nimport _ \"fmt\"\n\nfunc main() {\n\tname := \"x\"\n\t_ = \"a\" + name\n}\n",
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+
+	t.Run("skips chains of a named string type", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"main.go": `package main
+
+import "strings"
+
+type Path string
+
+func join(base Path) Path {
+	return base + "/x"
+}
+
+func main() {
+	name := "x"
+	_ = join(Path(name))
+	_ = "a" + name
+	_ = "b" + strings.ToUpper(name)
+}
+`,
+		})
+
+		contents := fixedContents(t)["main.go"]
+
+		assert.Contains(t, contents, "return base + \"/x\"")
+		assert.Contains(t, contents, "_ = fmt.Sprintf(\"a%s\", name)")
+		assert.Contains(t, contents, "_ = \"b\" + strings.ToUpper(name)")
+	})
+
+	t.Run("skips non-literal operands in constants", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"main.go": "package main\n\nconst name = \"x\"\n\nconst greeting = \"a\" + name\n\nfunc main() {}\n",
+		})
+
+		assert.Empty(t, fixedContents(t))
+	})
+}
+
+func Test_pickFixes(t *testing.T) {
+	rename := fix{
+		rule: RuleStuttering,
+		edits: []fixEdit{
+			{path: "a.go", start: 10, end: 15, text: "Item"},
+			{path: "b.go", start: 3, end: 8, text: "Item"},
+		},
+	}
+	concat := fix{rule: RuleStringConcat, edits: []fixEdit{{path: "a.go", start: 5, end: 20, text: "x"}}}
+	insert := fix{rule: RuleCompositeLiteral, edits: []fixEdit{{path: "a.go", start: 15, end: 15, text: "\n"}}}
+	duplicate := fix{rule: RuleStuttering, edits: []fixEdit{{path: "b.go", start: 3, end: 8, text: "Item"}}}
+
+	picked := pickFixes([]fix{rename, concat, insert, duplicate})
+
+	require.Len(t, picked, 3)
+	assert.Equal(t, rename, picked[0])
+	assert.Equal(t, insert, picked[1])
+	assert.Empty(t, picked[2].edits)
+}
+
+func Test_applyEdits(t *testing.T) {
+	t.Run("returns error when the result does not parse", func(t *testing.T) {
+		src := []byte("package main\n\nvar x = 1\n")
+
+		_, err := applyEdits("main.go", src, []fixEdit{{start: 22, end: 23, text: "("}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to format main.go after fixes")
+	})
+
+	t.Run("returns error when an import cannot be added", func(t *testing.T) {
+		src := []byte("package main\n\nvar x = 1\n")
+
+		_, err := applyEdits("main.go", src, []fixEdit{{start: 22, end: 23, text: "(", addImport: "fmt"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to format main.go after fixes")
+	})
+}
+
+func TestFixedFile(t *testing.T) {
+	t.Run("renders a unified diff", func(t *testing.T) {
+		file := FixedFile{
+			Path:     filepath.Join("pkg", "a.go"),
+			Original: []byte("package pkg\n\nvar x = 1\n"),
+			Fixed:    []byte("package pkg\n\nvar x = 2\n"),
+		}
+
+		assert.Equal(t, "--- a/pkg/a.go\n+++ b/pkg/a.go\n@@ -1,3 +1,3 @@\n package pkg\n \n-var x = 1\n+var x = 2\n", file.Diff())
+	})
+
+	t.Run("writes the fixed contents keeping the mode", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "a.go")
+		require.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+
+		require.NoError(t, FixedFile{Path: path, Fixed: []byte("new")}.Write())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("returns error for missing file", func(t *testing.T) {
+		err := FixedFile{Path: filepath.Join(t.TempDir(), "missing.go")}.Write()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write")
+	})
+}
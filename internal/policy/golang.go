@@ -2,23 +2,25 @@ package policy
 
 import (
 	"bufio"
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
+	"github.com/vitalvas/yake/internal/tools"
 )
 
 const (
@@ -28,111 +30,352 @@ const (
 )
 
 type golangPolicyCheck struct {
+	rule    string
 	section any
-	run     func() error
-}
-
-func RunGolangChecks() error {
+	run     func(idx *fileIndex) ([]Violation, error)
+	// runTests replaces run for checks that inspect the instrumented test
+	// run. The run is shared by all of them and started at most once; idx
+	// holds every indexed file of the project.
+	runTests func(ctx context.Context, idx *fileIndex, tests *testrun.Result) ([]Violation, error)
+	// projectWide checks judge the project as a whole, so a diff-aware run
+	// still feeds them every file instead of only the changed ones.
+	projectWide bool
+	// optIn checks run only when their section is configured, so upgrading
+	// does not fail projects that never asked for them.
+	optIn bool
+}
+
+// active reports whether the check runs under its section settings.
+func (c golangPolicyCheck) active() bool {
+	if c.optIn {
+		return optedIn(c.section)
+	}
+
+	return enabled(c.section)
+}
+
+type checkResult struct {
+	rule       string
+	violations []Violation
+	err        error
+}
+
+// RunOptions tunes a policy run.
+type RunOptions struct {
+	// Jobs bounds how many checks and files are analyzed at once. Zero or
+	// less falls back to DefaultJobs.
+	Jobs int
+	// Tests is an instrumented test run to reuse, typically the one made by
+	// "yake tests". When nil, the test-based checks start their own run.
+	Tests *testrun.Result
+	// Changed restricts the per-file checks to these files. Nil checks the
+	// whole project.
+	Changed tools.Changes
+	// ChangedLines further restricts per-file violations to changed lines.
+	ChangedLines bool
+	// SkipProjectRules leaves out project-wide checks such as coverage and
+	// entry_points when Changed is set.
+	SkipProjectRules bool
+}
+
+// RunGolangChecks runs every enabled Go policy check and returns the collected
+// violations sorted by rule and location. The error reports checks that could
+// not run at all; violations from the remaining checks are still returned
+// alongside it.
+func RunGolangChecks(ctx context.Context, opts RunOptions) ([]Violation, error) {
 	log.Println("Running Go policy checks...")
 
 	cfg, err := config.Load()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = DefaultJobs()
+	}
+
+	idx, err := buildFileIndex(".", jobs, cfg.Policy.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolveTypeCheck(cfg.Policy) {
+		attachTypes(idx, cfg.Tests.Tags)
 	}
 
-	var allErrors []string
+	scoped := idx
+	if opts.Changed != nil {
+		scoped = idx.only(opts.Changed)
+	}
+
+	var sourceChecks, testChecks []golangPolicyCheck
+
+	projectRules := make(map[string]bool)
 
 	for _, policyCheck := range golangPolicyChecks(cfg) {
-		if !enabled(policyCheck.section) {
+		switch {
+		case !policyCheck.active():
 			continue
+		case policyCheck.projectWide && opts.Changed != nil && opts.SkipProjectRules:
+			continue
+		case policyCheck.projectWide:
+			projectRules[policyCheck.rule] = true
+		}
+
+		switch {
+		case policyCheck.runTests != nil:
+			testChecks = append(testChecks, policyCheck)
+		default:
+			sourceChecks = append(sourceChecks, policyCheck)
+		}
+	}
+
+	runCheck := func(policyCheck golangPolicyCheck) checkResult {
+		target := scoped
+		if policyCheck.projectWide {
+			target = idx
+		}
+
+		violations, err := policyCheck.run(target)
+
+		return checkResult{
+			rule:       policyCheck.rule,
+			violations: violations,
+			err:        err,
+		}
+	}
+
+	results := parallelMap(idx.pool, sourceChecks, runCheck)
+
+	if len(testChecks) > 0 {
+		results = append(results, runTestChecks(ctx, idx, testChecks, opts.Tests, cfg.Tests.Tags)...)
+	}
+
+	var (
+		violations []Violation
+		errs       []error
+	)
+
+	ran := make(map[string]bool, len(results))
+
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.rule, result.err))
+		} else {
+			ran[result.rule] = true
 		}
 
-		if err := policyCheck.run(); err != nil {
-			allErrors = append(allErrors, err.Error())
+		violations = append(violations, result.violations...)
+	}
+
+	violations = applySuppressions(idx, violations, suppressionOptions{
+		ran:           ran,
+		report:        enabled(cfg.Policy.Suppressions),
+		requireReason: resolveRequireReason(cfg.Policy.Suppressions),
+		changed:       opts.Changed,
+	})
+
+	if opts.Changed != nil && opts.ChangedLines {
+		violations = filterChangedLines(violations, opts.Changed, projectRules)
+	}
+
+	violations = filterScoped(violations, cfg.Policy)
+
+	sortViolations(violations)
+
+	return violations, errors.Join(errs...)
+}
+
+// runTestChecks feeds one instrumented test run to every test-based check,
+// starting the run when the caller did not provide one. Running the tests
+// once keeps them from competing for cores and skewing each other's timings.
+func runTestChecks(ctx context.Context, idx *fileIndex, checks []golangPolicyCheck, tests *testrun.Result, tags []string) []checkResult {
+	results := make([]checkResult, 0, len(checks))
+
+	if tests == nil {
+		run, err := testrun.Run(ctx, testrun.Options{Tags: tags})
+		if err != nil {
+			for _, policyCheck := range checks {
+				results = append(results, checkResult{rule: policyCheck.rule, err: err})
+			}
+
+			return results
 		}
+
+		defer run.Close()
+
+		tests = run
 	}
 
-	if len(allErrors) > 0 {
-		return fmt.Errorf("%s", strings.Join(allErrors, "\n"))
+	for _, policyCheck := range checks {
+		violations, err := policyCheck.runTests(ctx, idx, tests)
+
+		results = append(results, checkResult{
+			rule:       policyCheck.rule,
+			violations: violations,
+			err:        err,
+		})
 	}
 
-	return nil
+	return results
 }
 
+// golangPolicyChecks lists every policy check in report order: the checks on
+// source files first, then those on the test run.
 func golangPolicyChecks(cfg *config.Config) []golangPolicyCheck {
+	return append(sourcePolicyChecks(cfg), testPolicyChecks(cfg)...)
+}
+
+func sourcePolicyChecks(cfg *config.Config) []golangPolicyCheck {
 	return []golangPolicyCheck{
 		{
+			rule:    RuleEntryPoints,
 			section: cfg.Policy.EntryPoints,
-			run: func() error {
-				return checkEntryPoints(resolveMaxMainLines(cfg.Policy.EntryPoints))
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkEntryPoints(idx, resolveMaxMainLines(cfg.Policy.EntryPoints))
 			},
+			projectWide: true,
 		},
 		{
+			rule:    RulePackageNaming,
 			section: cfg.Policy.PackageNaming,
-			run: func() error {
-				return checkPackageNaming(resolvePackageNamingPattern(cfg.Policy.PackageNaming))
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkPackageNaming(idx, resolvePackageNamingPattern(cfg.Policy.PackageNaming))
 			},
 		},
 		{
+			rule:    RuleASCIIOnly,
 			section: cfg.Policy.ASCIIOnly,
 			run:     checkASCIIOnly,
 		},
 		{
+			rule:    RuleStringConcat,
 			section: cfg.Policy.StringConcat,
 			run:     checkStringConcat,
 		},
 		{
+			rule:    RuleStdlibWrappers,
 			section: cfg.Policy.StdlibWrappers,
 			run:     checkStdlibWrappers,
 		},
 		{
+			rule:    RuleFuncSignature,
 			section: cfg.Policy.FuncSignature,
-			run: func() error {
-				return checkFuncSignature(resolveMaxFuncParams(cfg.Policy.FuncSignature), resolveMaxFuncResults(cfg.Policy.FuncSignature))
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkFuncSignature(idx, resolveMaxFuncParams(cfg.Policy.FuncSignature), resolveMaxFuncResults(cfg.Policy.FuncSignature))
+			},
+		},
+		{
+			rule:    RuleComplexity,
+			section: cfg.Policy.Complexity,
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkComplexity(idx, cfg.Policy.Complexity)
 			},
+			optIn: true,
 		},
 		{
+			rule:    RuleCompositeLiteral,
 			section: cfg.Policy.CompositeLiteral,
-			run: func() error {
-				return checkCompositeLiteral(resolveMaxSingleLineFields(cfg.Policy.CompositeLiteral))
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkCompositeLiteral(idx, resolveMaxSingleLineFields(cfg.Policy.CompositeLiteral))
 			},
 		},
 		{
+			rule:    RuleStuttering,
 			section: cfg.Policy.Stuttering,
 			run:     checkStuttering,
 		},
 		{
+			rule:    RuleGetterNaming,
 			section: cfg.Policy.GetterNaming,
 			run:     checkGetterNaming,
 		},
 		{
+			rule:    RulePrivateExportedMethods,
 			section: cfg.Policy.PrivateExportedMethods,
 			run:     checkPrivateExportedMethods,
 		},
 		{
+			rule:    RuleNoInit,
 			section: cfg.Policy.NoInit,
 			run:     checkNoInit,
 		},
 		{
+			rule:    RuleErrorHandling,
+			section: cfg.Policy.ErrorHandling,
+			run:     checkErrorHandling,
+			optIn:   true,
+		},
+		{
+			rule:    RuleContext,
+			section: cfg.Policy.Context,
+			run:     checkContext,
+			optIn:   true,
+		},
+		{
+			rule:    RuleTermination,
+			section: cfg.Policy.Termination,
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkTermination(idx, resolveTerminationOptions(cfg.Policy.Termination))
+			},
+			optIn: true,
+		},
+		{
+			rule:    RuleConcurrency,
+			section: cfg.Policy.Concurrency,
+			run:     checkConcurrency,
+			optIn:   true,
+		},
+		{
+			rule:    RuleImports,
+			section: cfg.Policy.Imports,
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkImports(idx, cfg.Policy.Imports)
+			},
+		},
+		{
+			rule:    RuleTestFileNaming,
 			section: cfg.Policy.TestFileNaming,
 			run:     checkTestFileNaming,
 		},
 		{
+			rule:    RuleTestQuality,
+			section: cfg.Policy.TestQuality,
+			run: func(idx *fileIndex) ([]Violation, error) {
+				return checkTestQuality(idx, resolveTestQualityOptions(cfg.Policy.TestQuality))
+			},
+			optIn: true,
+		},
+	}
+}
+
+func testPolicyChecks(cfg *config.Config) []golangPolicyCheck {
+	return []golangPolicyCheck{
+		{
+			rule:    RuleTestDuration,
 			section: cfg.Policy.TestDuration,
-			run: func() error {
-				return checkTestDuration(resolveMaxTestDuration(cfg.Policy.TestDuration))
+			runTests: func(_ context.Context, idx *fileIndex, tests *testrun.Result) ([]Violation, error) {
+				return checkTestDuration(idx, tests, resolveTestDurationOptions(cfg.Policy.TestDuration))
 			},
+			projectWide: true,
 		},
 		{
+			rule:    RuleCoverage,
 			section: cfg.Policy.Coverage,
-			run: func() error {
-				return checkCoverage(coverageOptions{
+			runTests: func(ctx context.Context, idx *fileIndex, tests *testrun.Result) ([]Violation, error) {
+				return checkCoverage(ctx, idx, tests, coverageOptions{
 					minCoverage:           resolveMinCoverage(cfg.Policy.Coverage),
 					maxUncoveredFuncLines: resolveMaxUncoveredFuncLines(cfg.Policy.Coverage),
 					excludePackages:       resolveExcludePackages(cfg.Policy.Coverage),
 					packageOverrides:      resolvePackageOverrides(cfg.Policy.Coverage),
+					patchMin:              resolvePatchMin(cfg.Policy.Coverage),
+					ratchet:               resolveRatchet(cfg.Policy.Coverage),
+					ratchetTolerance:      resolveRatchetTolerance(cfg.Policy.Coverage),
+					coverDirs:             resolveCoverDirs(cfg.Policy.Coverage),
 				})
 			},
+			projectWide: true,
 		},
 	}
 }
@@ -163,6 +406,22 @@ func enabled(p any) bool {
 		if v != nil {
 			flag = v.Enabled
 		}
+	case *config.TerminationPolicy:
+		if v != nil {
+			flag = v.Enabled
+		}
+	case *config.ImportsPolicy:
+		if v != nil {
+			flag = v.Enabled
+		}
+	case *config.TestQualityPolicy:
+		if v != nil {
+			flag = v.Enabled
+		}
+	case *config.ComplexityPolicy:
+		if v != nil {
+			flag = v.Enabled
+		}
 	case *config.TestDurationPolicy:
 		if v != nil {
 			flag = v.Enabled
@@ -171,11 +430,37 @@ func enabled(p any) bool {
 		if v != nil {
 			flag = v.Enabled
 		}
+	case *config.SuppressionsPolicy:
+		if v != nil {
+			flag = v.Enabled
+		}
 	}
 
 	return flag == nil || *flag
 }
 
+// optedIn reports whether an opt-in policy section is active. The section
+// must be present; a nil enable flag then defaults to enabled.
+func optedIn(p any) bool {
+	if p == nil || reflect.ValueOf(p).IsNil() {
+		return false
+	}
+
+	return enabled(p)
+}
+
+func resolveTypeCheck(p config.PolicyConfig) bool {
+	return p.TypeCheck != nil && *p.TypeCheck
+}
+
+// attachTypes loads type information into idx. A project that cannot be
+// loaded at all is still checked, syntax-only.
+func attachTypes(idx *fileIndex, tags []string) {
+	if err := idx.loadTypes(tags); err != nil {
+		log.Printf("Type information unavailable, falling back to syntax-only checks: %v", err)
+	}
+}
+
 func resolveMaxMainLines(p *config.EntryPointsPolicy) int {
 	if p == nil || p.MaxMainLines == nil {
 		return config.DefaultMaxMainLines
@@ -261,97 +546,105 @@ func resolvePackageOverrides(p *config.CoveragePolicy) map[string]float64 {
 	return p.PackageOverrides
 }
 
-func checkEntryPoints(maxMainLines int) error {
-	log.Println("Checking entry point layout (root main.go vs cmd/**/main.go)...")
+func resolvePatchMin(p *config.CoveragePolicy) float64 {
+	if p == nil || p.PatchMin == nil {
+		return 0
+	}
 
-	hasRootMain := false
+	return *p.PatchMin
+}
 
-	if _, err := os.Stat("main.go"); err == nil {
-		hasRootMain = true
+func resolveCoverDirs(p *config.CoveragePolicy) []string {
+	if p == nil {
+		return nil
 	}
 
-	var cmdMains []string
+	return p.CoverDirs
+}
 
-	_ = filepath.Walk("cmd", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+func resolveRatchet(p *config.CoveragePolicy) bool {
+	return p != nil && p.Ratchet != nil && *p.Ratchet
+}
 
-		if !info.IsDir() && info.Name() == "main.go" {
-			cmdMains = append(cmdMains, path)
-		}
+func resolveRatchetTolerance(p *config.CoveragePolicy) float64 {
+	if p == nil || p.RatchetTolerance == nil {
+		return config.DefaultRatchetTolerance
+	}
 
-		return nil
-	})
+	return *p.RatchetTolerance
+}
+
+func resolveRequireReason(p *config.SuppressionsPolicy) bool {
+	return p != nil && p.RequireReason != nil && *p.RequireReason
+}
+
+func checkEntryPoints(idx *fileIndex, maxMainLines int) ([]Violation, error) {
+	log.Println("Checking entry point layout (root main.go vs cmd/**/main.go)...")
+
+	rootMain := idx.lookup("main.go")
+
+	var cmdMains []*sourceFile
+
+	for _, file := range idx.files {
+		if filepath.Base(file.path) == "main.go" && strings.HasPrefix(filepath.ToSlash(file.path), "cmd/") {
+			cmdMains = append(cmdMains, file)
+		}
+	}
 
-	if hasRootMain && len(cmdMains) > 0 {
-		return fmt.Errorf("entry point violation: found both root main.go and cmd/ entry points; use one layout:\n  - root main.go (single binary)\n  - cmd/*/main.go (multiple binaries)")
+	if rootMain != nil && len(cmdMains) > 0 {
+		return []Violation{{
+			Rule:       RuleEntryPoints,
+			File:       "main.go",
+			Severity:   SeverityError,
+			Message:    "found both root main.go and cmd/ entry points",
+			Suggestion: "use one layout: root main.go (single binary) or cmd/*/main.go (multiple binaries)",
+		}}, nil
 	}
 
-	var mainFiles []string
+	var mainFiles []*sourceFile
 
-	if hasRootMain {
-		mainFiles = append(mainFiles, "main.go")
+	if rootMain != nil {
+		mainFiles = append(mainFiles, rootMain)
 	}
 
 	mainFiles = append(mainFiles, cmdMains...)
 
 	violations := validateMainFiles(mainFiles, maxMainLines)
 
-	nonMainViolations := findNonMainEntryPoints()
+	nonMainViolations := findNonMainEntryPoints(idx)
 	violations = append(violations, nonMainViolations...)
 
-	if len(violations) > 0 {
-		return fmt.Errorf("entry point violations:\n%s", strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findNonMainEntryPoints() []string {
-	var violations []string
-
-	_ = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+func findNonMainEntryPoints(idx *fileIndex) []Violation {
+	var violations []Violation
 
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") {
-			return nil
-		}
-
-		if info.Name() == "main.go" || strings.HasSuffix(info.Name(), "_test.go") {
-			return nil
+	for _, file := range idx.sources() {
+		if filepath.Base(file.path) == "main.go" {
+			continue
 		}
 
-		if extractPackageName(path) == "main" {
-			violations = append(violations,
-				fmt.Sprintf("  - %s: package main only allowed in main.go files", path))
+		if file.packageName() == "main" {
+			violations = append(violations, Violation{
+				Rule:       RuleEntryPoints,
+				File:       file.path,
+				Severity:   SeverityError,
+				Message:    "package main only allowed in main.go files",
+				Suggestion: "move the code into an internal/ or pkg/ package",
+			})
 		}
-
-		return nil
-	})
+	}
 
 	return violations
 }
 
-func validateMainFiles(paths []string, maxMainLines int) []string {
-	var violations []string
+func validateMainFiles(files []*sourceFile, maxMainLines int) []Violation {
+	var violations []Violation
 
-	for _, path := range paths {
-		fset := token.NewFileSet()
-
-		node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
-		if err != nil {
+	for _, file := range files {
+		node := file.parsed()
+		if node == nil {
 			continue
 		}
 
@@ -363,86 +656,69 @@ func validateMainFiles(paths []string, maxMainLines int) []string {
 				continue
 			}
 
+			pos := file.fset.Position(fn.Pos())
+
 			if fn.Name.Name == "main" && fn.Recv == nil {
 				hasMain = true
 
 				if fn.Body != nil {
-					lines := countCodeLines(fset, path, fn.Body.Lbrace, fn.Body.Rbrace)
+					lines := countCodeLines(file.fset, file.src, fn.Body.Lbrace, fn.Body.Rbrace)
 
 					if lines > maxMainLines {
-						violations = append(violations,
-							fmt.Sprintf("  - %s: main() is %d lines (maximum %d); move logic to internal/ or pkg/",
-								path, lines, maxMainLines))
+						violations = append(violations, Violation{
+							Rule:       RuleEntryPoints,
+							File:       file.path,
+							Line:       pos.Line,
+							Column:     pos.Column,
+							Severity:   SeverityError,
+							Message:    fmt.Sprintf("main() is %d lines (maximum %d)", lines, maxMainLines),
+							Suggestion: "move logic to internal/ or pkg/",
+						})
 					}
 				}
 
 				continue
 			}
 
-			violations = append(violations,
-				fmt.Sprintf("  - %s: unexpected function '%s'; only main() is allowed in entry point files",
-					path, fn.Name.Name))
+			violations = append(violations, Violation{
+				Rule:       RuleEntryPoints,
+				File:       file.path,
+				Line:       pos.Line,
+				Column:     pos.Column,
+				Severity:   SeverityError,
+				Message:    fmt.Sprintf("unexpected function '%s'", fn.Name.Name),
+				Suggestion: "only main() is allowed in entry point files",
+			})
 		}
 
 		if !hasMain {
-			violations = append(violations,
-				fmt.Sprintf("  - %s: missing main() function", path))
+			violations = append(violations, Violation{
+				Rule:     RuleEntryPoints,
+				File:     file.path,
+				Severity: SeverityError,
+				Message:  "missing main() function",
+			})
 		}
 	}
 
 	return violations
 }
 
-func checkStringConcat() error {
+func checkStringConcat(idx *fileIndex) ([]Violation, error) {
 	log.Println("Checking for string concatenation with '+'...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") {
-			return nil
-		}
+	violations := idx.collect(idx.files, findStringConcatenations)
 
-		fileViolations := findStringConcatenations(path)
-		violations = append(violations, fileViolations...)
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("string concatenation violations (use fmt.Sprintf or strings.Builder):\n%s",
-			strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findStringConcatenations(filePath string) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findStringConcatenations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
 	ast.Inspect(node, func(n ast.Node) bool {
 		binExpr, ok := n.(*ast.BinaryExpr)
@@ -450,10 +726,17 @@ func findStringConcatenations(filePath string) []string {
 			return true
 		}
 
-		if hasStringLit(binExpr) {
-			pos := fset.Position(binExpr.OpPos)
-			violations = append(violations,
-				fmt.Sprintf("  - %s:%d: string concatenation with '+'", filePath, pos.Line))
+		if isStringConcat(file, binExpr) {
+			pos := file.fset.Position(binExpr.OpPos)
+			violations = append(violations, Violation{
+				Rule:       RuleStringConcat,
+				File:       file.path,
+				Line:       pos.Line,
+				Column:     pos.Column,
+				Severity:   SeverityError,
+				Message:    "string concatenation with '+'",
+				Suggestion: "use fmt.Sprintf or strings.Builder",
+			})
 			return false
 		}
 
@@ -463,6 +746,17 @@ func findStringConcatenations(filePath string) []string {
 	return violations
 }
 
+// isStringConcat reports whether a '+' joins strings. With type information
+// every string addition counts; without it only chains holding a string
+// literal are recognized.
+func isStringConcat(file *sourceFile, binExpr *ast.BinaryExpr) bool {
+	if file.typed != nil {
+		return file.typed.isString(binExpr)
+	}
+
+	return hasStringLit(binExpr)
+}
+
 func hasStringLit(binExpr *ast.BinaryExpr) bool {
 	return containsStringLit(binExpr.X) || containsStringLit(binExpr.Y)
 }
@@ -479,54 +773,18 @@ func containsStringLit(expr ast.Expr) bool {
 	return false
 }
 
-func checkASCIIOnly() error {
+func checkASCIIOnly(idx *fileIndex) ([]Violation, error) {
 	log.Println("Checking non-test Go source for non-ASCII characters...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
+	violations := idx.collect(idx.sources(), findNonASCIIChars)
 
-		fileViolations := findNonASCIIChars(path)
-		violations = append(violations, fileViolations...)
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("ASCII-only violations (use only ASCII characters in non-test Go source):\n%s",
-			strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findNonASCIIChars(filePath string) []string {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil
-	}
+func findNonASCIIChars(file *sourceFile) []Violation {
+	data := file.src
 
-	var violations []string
+	var violations []Violation
 	line := 1
 	column := 1
 
@@ -546,14 +804,20 @@ func findNonASCIIChars(filePath string) []string {
 		}
 
 		r, size := utf8.DecodeRune(data[offset:])
+		message := fmt.Sprintf("non-ASCII character U+%04X", r)
 		if r == utf8.RuneError && size == 1 {
-			violations = append(violations,
-				fmt.Sprintf("  - %s:%d:%d: non-ASCII byte 0x%X", filePath, line, column, b))
-		} else {
-			violations = append(violations,
-				fmt.Sprintf("  - %s:%d:%d: non-ASCII character U+%04X", filePath, line, column, r))
+			message = fmt.Sprintf("non-ASCII byte 0x%X", b)
 		}
 
+		violations = append(violations, Violation{
+			Rule:     RuleASCIIOnly,
+			File:     file.path,
+			Line:     line,
+			Column:   column,
+			Severity: SeverityError,
+			Message:  message,
+		})
+
 		column++
 		offset += size
 	}
@@ -561,52 +825,17 @@ func findNonASCIIChars(filePath string) []string {
 	return violations
 }
 
-func checkStdlibWrappers() error {
+func checkStdlibWrappers(idx *fileIndex) ([]Violation, error) {
 	log.Println("Checking for stdlib wrapper functions...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		fileViolations := findStdlibWrappers(path)
-		violations = append(violations, fileViolations...)
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("stdlib wrapper violations (do not wrap standard library functions):\n%s",
-			strings.Join(violations, "\n"))
-	}
+	violations := idx.collect(idx.sources(), findStdlibWrappers)
 
-	return nil
+	return violations, nil
 }
 
-func findStdlibWrappers(filePath string) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findStdlibWrappers(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
@@ -615,9 +844,9 @@ func findStdlibWrappers(filePath string) []string {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
@@ -650,23 +879,35 @@ func findStdlibWrappers(filePath string) []string {
 			continue
 		}
 
-		parts := strings.SplitN(pkgFunc, ".", 2)
-		if len(parts) != 2 {
-			continue
-		}
+		if file.typed != nil {
+			if !file.typed.isStdlibCall(callExpr) || !file.typed.forwardsParams(fn, callExpr) {
+				continue
+			}
+		} else {
+			parts := strings.SplitN(pkgFunc, ".", 2)
+			if len(parts) != 2 {
+				continue
+			}
 
-		if _, isStdlib := stdlibAliases[parts[0]]; !isStdlib {
-			continue
-		}
+			if _, isStdlib := stdlibAliases[parts[0]]; !isStdlib {
+				continue
+			}
 
-		if !isParamForwarding(fn, callExpr) {
-			continue
+			if !isParamForwarding(fn, callExpr) {
+				continue
+			}
 		}
 
-		pos := fset.Position(fn.Pos())
-		violations = append(violations,
-			fmt.Sprintf("  - %s:%d: function '%s' is a wrapper around '%s'",
-				filePath, pos.Line, fn.Name.Name, pkgFunc))
+		pos := file.fset.Position(fn.Pos())
+		violations = append(violations, Violation{
+			Rule:       RuleStdlibWrappers,
+			File:       file.path,
+			Line:       pos.Line,
+			Column:     pos.Column,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("function '%s' is a wrapper around '%s'", fn.Name.Name, pkgFunc),
+			Suggestion: fmt.Sprintf("call '%s' directly", pkgFunc),
+		})
 	}
 
 	return violations
@@ -750,60 +991,27 @@ func isParamForwarding(fn *ast.FuncDecl, call *ast.CallExpr) bool {
 	return usedParams == len(paramNames)
 }
 
-func checkFuncSignature(maxParams, maxResults int) error {
+func checkFuncSignature(idx *fileIndex, maxParams, maxResults int) ([]Violation, error) {
 	log.Println("Checking function signature complexity...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		fileViolations := findFuncSignatureViolations(path, maxParams, maxResults)
-		violations = append(violations, fileViolations...)
-
-		return nil
+	violations := idx.collect(idx.sources(), func(file *sourceFile) []Violation {
+		return findFuncSignatureViolations(file, maxParams, maxResults)
 	})
 
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("function signature violations (use struct-based config):\n%s",
-			strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findFuncSignatureViolations(filePath string, maxParams, maxResults int) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findFuncSignatureViolations(file *sourceFile, maxParams, maxResults int) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
 	for _, decl := range node.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
@@ -813,78 +1021,57 @@ func findFuncSignatureViolations(filePath string, maxParams, maxResults int) []s
 
 		paramCount := countFields(fn.Type.Params)
 		if paramCount > maxParams {
-			pos := fset.Position(fn.Pos())
-			violations = append(violations,
-				fmt.Sprintf("  - %s:%d: function '%s' has %d parameters (maximum %d)",
-					filePath, pos.Line, fn.Name.Name, paramCount, maxParams))
+			pos := file.fset.Position(fn.Pos())
+			violations = append(violations, Violation{
+				Rule:       RuleFuncSignature,
+				File:       file.path,
+				Line:       pos.Line,
+				Column:     pos.Column,
+				Severity:   SeverityError,
+				Message:    fmt.Sprintf("function '%s' has %d parameters (maximum %d)", fn.Name.Name, paramCount, maxParams),
+				Suggestion: "group parameters into a config struct",
+			})
 		}
 
 		resultCount := countFields(fn.Type.Results)
 		if resultCount > maxResults {
-			pos := fset.Position(fn.Pos())
-			violations = append(violations,
-				fmt.Sprintf("  - %s:%d: function '%s' has %d return values (maximum %d)",
-					filePath, pos.Line, fn.Name.Name, resultCount, maxResults))
+			pos := file.fset.Position(fn.Pos())
+			violations = append(violations, Violation{
+				Rule:       RuleFuncSignature,
+				File:       file.path,
+				Line:       pos.Line,
+				Column:     pos.Column,
+				Severity:   SeverityError,
+				Message:    fmt.Sprintf("function '%s' has %d return values (maximum %d)", fn.Name.Name, resultCount, maxResults),
+				Suggestion: "return a result struct",
+			})
 		}
 	}
 
 	return violations
 }
 
-func checkCompositeLiteral(maxSingleLineFields int) error {
+func checkCompositeLiteral(idx *fileIndex, maxSingleLineFields int) ([]Violation, error) {
 	log.Println("Checking composite literal formatting...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") {
-			return nil
-		}
-
-		fileViolations := findCompositeLiteralViolations(path, maxSingleLineFields)
-		violations = append(violations, fileViolations...)
-
-		return nil
+	violations := idx.collect(idx.files, func(file *sourceFile) []Violation {
+		return findCompositeLiteralViolations(file, maxSingleLineFields)
 	})
 
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("composite literal violations (each field must be on its own line):\n%s",
-			strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findCompositeLiteralViolations(filePath string, maxSingleLineFields int) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findCompositeLiteralViolations(file *sourceFile, maxSingleLineFields int) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
 	ast.Inspect(node, func(n ast.Node) bool {
 		comp, ok := n.(*ast.CompositeLit)
@@ -892,36 +1079,24 @@ func findCompositeLiteralViolations(filePath string, maxSingleLineFields int) []
 			return true
 		}
 
-		kvElts := 0
-		for _, elt := range comp.Elts {
-			if _, ok := elt.(*ast.KeyValueExpr); ok {
-				kvElts++
-			}
-		}
+		lineSet := crowdedLines(file.fset, comp, maxSingleLineFields)
 
-		if kvElts <= 1 {
-			return true
-		}
-
-		openLine := fset.Position(comp.Lbrace).Line
-		closeLine := fset.Position(comp.Rbrace).Line
-
-		if openLine == closeLine && kvElts <= maxSingleLineFields {
-			return true
+		lines := make([]int, 0, len(lineSet))
+		for line := range lineSet {
+			lines = append(lines, line)
 		}
 
-		lineSet := make(map[int]int)
-		for _, elt := range comp.Elts {
-			line := fset.Position(elt.Pos()).Line
-			lineSet[line]++
-		}
+		slices.Sort(lines)
 
-		for line, count := range lineSet {
-			if count > 1 {
-				violations = append(violations,
-					fmt.Sprintf("  - %s:%d: composite literal has %d fields on the same line",
-						filePath, line, count))
-			}
+		for _, line := range lines {
+			violations = append(violations, Violation{
+				Rule:       RuleCompositeLiteral,
+				File:       file.path,
+				Line:       line,
+				Severity:   SeverityError,
+				Message:    fmt.Sprintf("composite literal has %d fields on the same line", lineSet[line]),
+				Suggestion: "put each field on its own line",
+			})
 		}
 
 		return true
@@ -930,6 +1105,42 @@ func findCompositeLiteralViolations(filePath string, maxSingleLineFields int) []
 	return violations
 }
 
+// crowdedLines maps the lines of a keyed composite literal that hold more
+// than one element to their element count. Literals with a single keyed
+// field, or small enough to fit on one line, have none.
+func crowdedLines(fset *token.FileSet, comp *ast.CompositeLit, maxSingleLineFields int) map[int]int {
+	kvElts := 0
+	for _, elt := range comp.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok {
+			kvElts++
+		}
+	}
+
+	if kvElts <= 1 {
+		return nil
+	}
+
+	openLine := fset.Position(comp.Lbrace).Line
+	closeLine := fset.Position(comp.Rbrace).Line
+
+	if openLine == closeLine && kvElts <= maxSingleLineFields {
+		return nil
+	}
+
+	lineSet := make(map[int]int)
+	for _, elt := range comp.Elts {
+		lineSet[fset.Position(elt.Pos()).Line]++
+	}
+
+	for line, count := range lineSet {
+		if count <= 1 {
+			delete(lineSet, line)
+		}
+	}
+
+	return lineSet
+}
+
 func countFields(fields *ast.FieldList) int {
 	if fields == nil {
 		return 0
@@ -948,129 +1159,64 @@ func countFields(fields *ast.FieldList) int {
 	return count
 }
 
-func checkPackageNaming(pattern string) error {
+func checkPackageNaming(idx *fileIndex, pattern string) ([]Violation, error) {
 	log.Println("Checking package naming conventions...")
 
 	pkgNameRegex := regexp.MustCompile(pattern)
 
 	absRoot, err := filepath.Abs(".")
 	if err != nil {
-		return fmt.Errorf("failed to resolve root directory: %w", err)
+		return nil, fmt.Errorf("failed to resolve root directory: %w", err)
 	}
 
 	rootDirName := filepath.Base(absRoot)
 
-	var violations []string
-
-	err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
+	var violations []Violation
 
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, ".pb.go") {
-			return nil
-		}
-
-		pkgName := extractPackageName(path)
+	for _, file := range idx.sources() {
+		pkgName := file.packageName()
 		if pkgName == "" || pkgName == "main" {
-			return nil
+			continue
 		}
 
 		if !pkgNameRegex.MatchString(pkgName) {
-			violations = append(violations,
-				fmt.Sprintf("  - %s: package name '%s' does not match '%s'", path, pkgName, pattern))
+			violations = append(violations, Violation{
+				Rule:     RulePackageNaming,
+				File:     file.path,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("package name '%s' does not match '%s'", pkgName, pattern),
+			})
 		}
 
-		dirName := filepath.Base(filepath.Dir(path))
+		dirName := filepath.Base(filepath.Dir(file.path))
 		if dirName == "." {
 			dirName = rootDirName
 		}
 
 		if dirName != pkgName {
-			violations = append(violations,
-				fmt.Sprintf("  - %s: package name '%s' does not match directory name '%s'", path, pkgName, dirName))
+			violations = append(violations, Violation{
+				Rule:     RulePackageNaming,
+				File:     file.path,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("package name '%s' does not match directory name '%s'", pkgName, dirName),
+			})
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
 	}
 
-	if len(violations) > 0 {
-		return fmt.Errorf("package naming violations:\n%s", strings.Join(violations, "\n"))
-	}
-
-	return nil
-}
-
-func extractPackageName(filePath string) string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.PackageClauseOnly)
-	if err != nil {
-		return ""
-	}
-
-	return node.Name.Name
+	return violations, nil
 }
 
-func checkStuttering() error {
+func checkStuttering(idx *fileIndex) ([]Violation, error) {
 	log.Println("Checking for stuttering in exported identifiers...")
 
-	var violations []string
+	violations := idx.collect(idx.sources(), findStutteringViolations)
 
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		fileViolations := findStutteringViolations(path)
-		violations = append(violations, fileViolations...)
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("stuttering violations (exported names should not repeat the package name):\n%s",
-			strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findStutteringViolations(filePath string) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findStutteringViolations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
@@ -1079,13 +1225,13 @@ func findStutteringViolations(filePath string) []string {
 		return nil
 	}
 
-	pkgUpper := strings.ToUpper(pkgName[:1]) + pkgName[1:]
+	pkgUpper := fmt.Sprintf("%s%s", strings.ToUpper(pkgName[:1]), pkgName[1:])
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
 	for _, decl := range node.Decls {
 		switch d := decl.(type) {
@@ -1096,9 +1242,7 @@ func findStutteringViolations(filePath string) []string {
 			}
 
 			if isStutteringName(name, pkgUpper) {
-				pos := fset.Position(d.Pos())
-				violations = append(violations,
-					fmt.Sprintf("  - %s:%d: function '%s.%s' stutters", filePath, pos.Line, pkgName, name))
+				violations = append(violations, stutteringViolation(file.fset.Position(d.Pos()), "function", pkgName, name, pkgUpper))
 			}
 
 		case *ast.GenDecl:
@@ -1119,9 +1263,7 @@ func findStutteringViolations(filePath string) []string {
 					}
 
 					if isStutteringName(name, pkgUpper) {
-						pos := fset.Position(s.Pos())
-						violations = append(violations,
-							fmt.Sprintf("  - %s:%d: type '%s.%s' stutters", filePath, pos.Line, pkgName, name))
+						violations = append(violations, stutteringViolation(file.fset.Position(s.Pos()), "type", pkgName, name, pkgUpper))
 					}
 
 				case *ast.ValueSpec:
@@ -1131,9 +1273,7 @@ func findStutteringViolations(filePath string) []string {
 						}
 
 						if isStutteringName(ident.Name, pkgUpper) {
-							pos := fset.Position(ident.Pos())
-							violations = append(violations,
-								fmt.Sprintf("  - %s:%d: identifier '%s.%s' stutters", filePath, pos.Line, pkgName, ident.Name))
+							violations = append(violations, stutteringViolation(file.fset.Position(ident.Pos()), "identifier", pkgName, ident.Name, pkgUpper))
 						}
 					}
 				}
@@ -1144,73 +1284,50 @@ func findStutteringViolations(filePath string) []string {
 	return violations
 }
 
-func isStutteringName(name, pkgUpper string) bool {
-	if !strings.HasPrefix(name, pkgUpper) {
-		return false
+func stutteringViolation(pos token.Position, kind, pkgName, name, pkgUpper string) Violation {
+	return Violation{
+		Rule:       RuleStuttering,
+		File:       pos.Filename,
+		Line:       pos.Line,
+		Column:     pos.Column,
+		Severity:   SeverityError,
+		Message:    fmt.Sprintf("%s '%s.%s' stutters", kind, pkgName, name),
+		Suggestion: fmt.Sprintf("rename to '%s.%s'", pkgName, name[len(pkgUpper):]),
 	}
-
-	rest := name[len(pkgUpper):]
-	if len(rest) == 0 {
-		return false
-	}
-
-	return rest[0] >= 'A' && rest[0] <= 'Z'
 }
 
-func checkGetterNaming() error {
-	log.Println("Checking for Get prefix in getter methods...")
-
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		fileViolations := findGetterViolations(path)
-		violations = append(violations, fileViolations...)
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
+func isStutteringName(name, pkgUpper string) bool {
+	if !strings.HasPrefix(name, pkgUpper) {
+		return false
 	}
 
-	if len(violations) > 0 {
-		return fmt.Errorf("getter naming violations (use Name() instead of GetName()):\n%s",
-			strings.Join(violations, "\n"))
+	rest := name[len(pkgUpper):]
+	if len(rest) == 0 {
+		return false
 	}
 
-	return nil
+	return rest[0] >= 'A' && rest[0] <= 'Z'
 }
 
-func findGetterViolations(filePath string) []string {
-	fset := token.NewFileSet()
+func checkGetterNaming(idx *fileIndex) ([]Violation, error) {
+	log.Println("Checking for Get prefix in getter methods...")
 
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+	violations := idx.collect(idx.sources(), findGetterViolations)
+
+	return violations, nil
+}
+
+func findGetterViolations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
 	for _, decl := range node.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
@@ -1231,11 +1348,30 @@ func findGetterViolations(filePath string) []string {
 		paramCount := countFields(fn.Type.Params)
 		resultCount := countFields(fn.Type.Results)
 
-		if paramCount == 0 && resultCount == 1 {
-			pos := fset.Position(fn.Pos())
-			violations = append(violations,
-				fmt.Sprintf("  - %s:%d: method '%s' should be '%s'", filePath, pos.Line, name, afterGet))
+		if paramCount != 0 || resultCount != 1 {
+			continue
+		}
+
+		// A getter required by an interface the type implements cannot be
+		// renamed on its own.
+		if file.typed != nil {
+			if file.typed.implementsMethod(fn) {
+				continue
+			}
+		} else if stdlibInterfaceMethods[name] {
+			continue
 		}
+
+		pos := file.fset.Position(fn.Pos())
+		violations = append(violations, Violation{
+			Rule:       RuleGetterNaming,
+			File:       file.path,
+			Line:       pos.Line,
+			Column:     pos.Column,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("method '%s' should be '%s'", name, afterGet),
+			Suggestion: fmt.Sprintf("rename to '%s'", afterGet),
+		})
 	}
 
 	return violations
@@ -1243,8 +1379,12 @@ func findGetterViolations(filePath string) []string {
 
 // stdlibInterfaceMethods lists exported method names from standard library
 // interfaces. Private structs are allowed to have these methods because they
-// implement interfaces rather than exposing arbitrary public API.
-// Generated from Go stdlib using go/parser to extract all exported interface methods.
+// implement interfaces rather than exposing arbitrary public API, and getters
+// among them keep their Get prefix. The table only backs syntax-only runs; with
+// type information the checks ask whether the type really implements an
+// interface declaring the method.
+// Generated from Go stdlib using go/parser to extract all exported interface
+// methods, plus the golangci-lint plugin interface yake itself implements.
 var stdlibInterfaceMethods = map[string]bool{
 	"Accept":                     true, // net.Listener
 	"Add":                        true, // crypto/elliptic.Curve
@@ -1323,6 +1463,7 @@ var stdlibInterfaceMethods = map[string]bool{
 	"Format":                     true, // fmt.Formatter
 	"Generate":                   true, // testing/quick.Generator
 	"GenerateKey":                true, // crypto/ecdh.Curve
+	"GetLoadMode":                true, // github.com/golangci/plugin-module-register/register.LinterPlugin
 	"Glob":                       true, // io/fs.GlobFS
 	"GoString":                   true, // fmt.GoStringer
 	"GobDecode":                  true, // encoding/gob.GobDecoder
@@ -1503,62 +1644,27 @@ var stdlibInterfaceMethods = map[string]bool{
 	"MarshalLogArray":  true,
 }
 
-func checkPrivateExportedMethods() error {
+func checkPrivateExportedMethods(idx *fileIndex) ([]Violation, error) {
 	log.Println("Checking for exported methods on private structs...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		fileViolations := findPrivateExportedMethodViolations(path)
-		violations = append(violations, fileViolations...)
+	violations := idx.collect(idx.sources(), findPrivateExportedMethodViolations)
 
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("private struct exported method violations (private structs should not have exported methods):\n%s",
-			strings.Join(violations, "\n"))
-	}
-
-	return nil
+	return violations, nil
 }
 
-func findPrivateExportedMethodViolations(filePath string) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findPrivateExportedMethodViolations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
 	privateTypes := collectPrivateTypes(node)
 
-	var violations []string
+	var violations []Violation
 
 	for _, decl := range node.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
@@ -1570,19 +1676,32 @@ func findPrivateExportedMethodViolations(filePath string) []string {
 			continue
 		}
 
-		if stdlibInterfaceMethods[fn.Name.Name] {
+		receiverName := extractReceiverTypeName(fn)
+		if receiverName == "" {
 			continue
 		}
 
-		receiverName := extractReceiverTypeName(fn)
-		if receiverName == "" || !privateTypes[receiverName] {
+		if file.typed != nil {
+			// Receiver types are declared in the same package, so the name
+			// alone tells whether the type is private, whichever file
+			// declares it.
+			if token.IsExported(receiverName) || file.typed.implementsMethod(fn) {
+				continue
+			}
+		} else if !privateTypes[receiverName] || stdlibInterfaceMethods[fn.Name.Name] {
 			continue
 		}
 
-		pos := fset.Position(fn.Pos())
-		violations = append(violations,
-			fmt.Sprintf("  - %s:%d: exported method '%s' on private struct '%s'",
-				filePath, pos.Line, fn.Name.Name, receiverName))
+		pos := file.fset.Position(fn.Pos())
+		violations = append(violations, Violation{
+			Rule:       RulePrivateExportedMethods,
+			File:       file.path,
+			Line:       pos.Line,
+			Column:     pos.Column,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("exported method '%s' on private struct '%s'", fn.Name.Name, receiverName),
+			Suggestion: "unexport the method or export the struct",
+		})
 	}
 
 	return violations
@@ -1640,60 +1759,25 @@ func extractReceiverTypeName(fn *ast.FuncDecl) string {
 	return ""
 }
 
-func checkNoInit() error {
+func checkNoInit(idx *fileIndex) ([]Violation, error) {
 	log.Println("Checking for init() functions...")
 
-	var violations []string
-
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		fileViolations := findInitViolations(path)
-		violations = append(violations, fileViolations...)
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	if len(violations) > 0 {
-		return fmt.Errorf("init() function violations (init() is forbidden; use explicit constructors or wire setup from main()):\n%s",
-			strings.Join(violations, "\n"))
-	}
+	violations := idx.collect(idx.sources(), findInitViolations)
 
-	return nil
+	return violations, nil
 }
 
-func findInitViolations(filePath string) []string {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func findInitViolations(file *sourceFile) []Violation {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
-	if hasSkipDirective(filePath) {
+	if file.skip {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
 	for _, decl := range node.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
@@ -1709,79 +1793,107 @@ func findInitViolations(filePath string) []string {
 			continue
 		}
 
-		pos := fset.Position(fn.Pos())
-		violations = append(violations,
-			fmt.Sprintf("  - %s:%d: init() function is forbidden", filePath, pos.Line))
+		if onlyRegisters(fn) {
+			continue
+		}
+
+		pos := file.fset.Position(fn.Pos())
+		violations = append(violations, Violation{
+			Rule:       RuleNoInit,
+			File:       file.path,
+			Line:       pos.Line,
+			Column:     pos.Column,
+			Severity:   SeverityError,
+			Message:    "init() function is forbidden",
+			Suggestion: "use explicit constructors or wire setup from main()",
+		})
 	}
 
 	return violations
 }
 
-func checkTestFileNaming() error {
-	log.Println("Checking test file naming conventions...")
+// initRegistrations lists the registration calls a package makes from init so
+// that importing it is enough, such as database drivers and golangci-lint
+// module plugins.
+var initRegistrations = map[string]bool{
+	"image.RegisterFormat": true,
+	"register.Plugin":      true, // github.com/golangci/plugin-module-register/register
+	"sql.Register":         true,
+}
 
-	var violations []string
+// onlyRegisters reports whether the body of an init function does nothing but
+// call functions from initRegistrations.
+func onlyRegisters(fn *ast.FuncDecl) bool {
+	if fn.Body == nil || len(fn.Body.List) == 0 {
+		return false
+	}
 
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	for _, stmt := range fn.Body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			return false
 		}
 
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
+		call, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
 
-			return nil
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
 		}
 
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") {
-			return nil
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || !initRegistrations[fmt.Sprintf("%s.%s", pkg.Name, sel.Sel.Name)] {
+			return false
 		}
+	}
 
-		isTestFile := strings.HasSuffix(path, "_test.go")
+	return true
+}
 
-		if isTestFile {
-			fileViolations := validateTestFileName(path)
-			violations = append(violations, fileViolations...)
-		} else {
-			if hasTestingImport(path) {
-				violations = append(violations,
-					fmt.Sprintf("  - %s: file imports 'testing' but is not named '{origin}_test.go' or '{origin}_e2e_test.go'", path))
-			}
+func checkTestFileNaming(idx *fileIndex) ([]Violation, error) {
+	log.Println("Checking test file naming conventions...")
 
-			if !hasSkipDirective(path) && hasSignificantFunctions(path) {
-				fileViolations := validateSourceFile(path)
-				violations = append(violations, fileViolations...)
-			}
+	violations := idx.collect(idx.files, func(file *sourceFile) []Violation {
+		if file.isTest() {
+			return validateTestFileName(file)
 		}
 
-		return nil
-	})
+		var fileViolations []Violation
 
-	if err != nil {
-		return fmt.Errorf("failed to walk directory: %w", err)
-	}
+		if hasTestingImport(file) {
+			fileViolations = append(fileViolations, Violation{
+				Rule:     RuleTestFileNaming,
+				File:     file.path,
+				Severity: SeverityError,
+				Message:  "file imports 'testing' but is not named '{origin}_test.go' or '{origin}_e2e_test.go'",
+			})
+		}
 
-	if len(violations) > 0 {
-		return fmt.Errorf("test file naming violations:\n%s", strings.Join(violations, "\n"))
-	}
+		if !file.skip && hasSignificantFunctions(file) {
+			fileViolations = append(fileViolations, validateSourceFile(file)...)
+		}
 
-	return nil
+		return fileViolations
+	})
+
+	return violations, nil
 }
 
-func validateTestFileName(testPath string) []string {
-	if hasSkipDirective(testPath) {
+func validateTestFileName(file *sourceFile) []Violation {
+	if file.skip {
 		return nil
 	}
 
-	if !hasFunctions(testPath) {
+	if !hasFunctions(file) {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
+	testPath := file.path
 	filename := filepath.Base(testPath)
 	dir := filepath.Dir(testPath)
 
@@ -1804,8 +1916,13 @@ func validateTestFileName(testPath string) []string {
 
 		for _, pattern := range invalidPatterns {
 			if strings.HasSuffix(filename, pattern) {
-				violations = append(violations, fmt.Sprintf("  - %s: invalid naming pattern '%s', use '{origin}_test.go' or '{origin}_e2e_test.go'",
-					testPath, pattern))
+				violations = append(violations, Violation{
+					Rule:       RuleTestFileNaming,
+					File:       testPath,
+					Severity:   SeverityError,
+					Message:    fmt.Sprintf("invalid naming pattern '%s'", pattern),
+					Suggestion: "use '{origin}_test.go' or '{origin}_e2e_test.go'",
+				})
 			}
 		}
 	}
@@ -1814,12 +1931,22 @@ func validateTestFileName(testPath string) []string {
 		sourcePath := filepath.Join(dir, expectedSourceFile)
 
 		if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-			violations = append(violations, fmt.Sprintf("  - %s: missing source file '%s'", testPath, sourcePath))
+			violations = append(violations, Violation{
+				Rule:     RuleTestFileNaming,
+				File:     testPath,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("missing source file '%s'", sourcePath),
+			})
 		}
 	}
 
-	if !isStandardTestFile(filename) && !hasTestingImport(testPath) {
-		violations = append(violations, fmt.Sprintf("  - %s: missing 'testing' package import", testPath))
+	if !isStandardTestFile(filename) && !hasTestingImport(file) {
+		violations = append(violations, Violation{
+			Rule:     RuleTestFileNaming,
+			File:     testPath,
+			Severity: SeverityError,
+			Message:  "missing 'testing' package import",
+		})
 	}
 
 	return violations
@@ -1889,17 +2016,18 @@ func stripPlatformSuffix(base string) (string, bool) {
 	return base, false
 }
 
-func validateSourceFile(sourcePath string) []string {
-	if hasSkipDirective(sourcePath) {
+func validateSourceFile(file *sourceFile) []Violation {
+	if file.skip {
 		return nil
 	}
 
-	var violations []string
+	var violations []Violation
 
+	sourcePath := file.path
 	filename := filepath.Base(sourcePath)
 	dir := filepath.Dir(sourcePath)
 
-	if filename == "main.go" && extractPackageName(sourcePath) == "main" {
+	if filename == "main.go" && file.packageName() == "main" {
 		return violations
 	}
 
@@ -1912,8 +2040,12 @@ func validateSourceFile(sourcePath string) []string {
 	}
 
 	if _, err := os.Stat(testPath); os.IsNotExist(err) {
-		violations = append(violations,
-			fmt.Sprintf("  - %s: missing test file '%s'", sourcePath, testPath))
+		violations = append(violations, Violation{
+			Rule:     RuleTestFileNaming,
+			File:     sourcePath,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("missing test file '%s'", testPath),
+		})
 	}
 
 	return violations
@@ -1928,38 +2060,6 @@ func isStandardTestFile(filename string) bool {
 	return standardTestFiles[filename]
 }
 
-func hasSkipDirective(filePath string) bool {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if line == "" {
-			continue
-		}
-
-		if strings.HasPrefix(line, "//") {
-			if strings.HasPrefix(line, skipDirective) {
-				return true
-			}
-
-			continue
-		}
-
-		if strings.HasPrefix(line, "package ") {
-			break
-		}
-	}
-
-	return false
-}
-
 func hasFuncSkipDirective(fn *ast.FuncDecl) bool {
 	if fn.Doc == nil {
 		return false
@@ -1988,15 +2088,12 @@ func hasGenDeclSkipDirective(decl *ast.GenDecl) bool {
 	return false
 }
 
-func hasTestingImport(filePath string) bool {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ImportsOnly)
-	if err != nil {
+func hasTestingImport(file *sourceFile) bool {
+	if file.node == nil {
 		return false
 	}
 
-	for _, imp := range node.Imports {
+	for _, imp := range file.node.Imports {
 		importPath := strings.Trim(imp.Path.Value, `"`)
 		if importPath == "testing" {
 			return true
@@ -2006,11 +2103,9 @@ func hasTestingImport(filePath string) bool {
 	return false
 }
 
-func hasFunctions(filePath string) bool {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, 0)
-	if err != nil {
+func hasFunctions(file *sourceFile) bool {
+	node := file.parsed()
+	if node == nil {
 		return false
 	}
 
@@ -2023,16 +2118,11 @@ func hasFunctions(filePath string) bool {
 	return false
 }
 
-func countCodeLines(fset *token.FileSet, filePath string, lbrace, rbrace token.Pos) int {
+func countCodeLines(fset *token.FileSet, src []byte, lbrace, rbrace token.Pos) int {
 	startLine := fset.Position(lbrace).Line
 	endLine := fset.Position(rbrace).Line
 
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return endLine - startLine - 1
-	}
-
-	lines := strings.Split(string(data), "\n")
+	lines := strings.Split(string(src), "\n")
 
 	count := 0
 	for i := startLine; i < endLine-1; i++ {
@@ -2044,11 +2134,9 @@ func countCodeLines(fset *token.FileSet, filePath string, lbrace, rbrace token.P
 	return count
 }
 
-func hasSignificantFunctions(filePath string) bool {
-	fset := token.NewFileSet()
-
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+func hasSignificantFunctions(file *sourceFile) bool {
+	node := file.parsed()
+	if node == nil {
 		return false
 	}
 
@@ -2058,7 +2146,7 @@ func hasSignificantFunctions(filePath string) bool {
 				continue
 			}
 
-			lines := countCodeLines(fset, filePath, fn.Body.Lbrace, fn.Body.Rbrace)
+			lines := countCodeLines(file.fset, file.src, fn.Body.Lbrace, fn.Body.Rbrace)
 			if lines > minFunctionLines {
 				return true
 			}
@@ -2078,14 +2166,31 @@ type funcInfo struct {
 type coverBlock struct {
 	StartLine int
 	EndLine   int
+	NumStmt   int
 	Count     int
 }
 
-func largeFunctions(filePath string, maxUncoveredFuncLines int) []funcInfo {
-	fset := token.NewFileSet()
+// funcName names a function in coverage messages, methods as Type_Method.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
 
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-	if err != nil {
+	if ident, ok := recv.(*ast.Ident); ok {
+		return fmt.Sprintf("%s_%s", ident.Name, fn.Name.Name)
+	}
+
+	return fn.Name.Name
+}
+
+func largeFunctions(file *sourceFile, maxUncoveredFuncLines int) []funcInfo {
+	node := file.parsed()
+	if node == nil {
 		return nil
 	}
 
@@ -2097,29 +2202,16 @@ func largeFunctions(filePath string, maxUncoveredFuncLines int) []funcInfo {
 			continue
 		}
 
-		startLine := fset.Position(fn.Body.Lbrace).Line
-		endLine := fset.Position(fn.Body.Rbrace).Line
-		lines := countCodeLines(fset, filePath, fn.Body.Lbrace, fn.Body.Rbrace)
+		startLine := file.fset.Position(fn.Body.Lbrace).Line
+		endLine := file.fset.Position(fn.Body.Rbrace).Line
+		lines := countCodeLines(file.fset, file.src, fn.Body.Lbrace, fn.Body.Rbrace)
 
 		if lines <= maxUncoveredFuncLines {
 			continue
 		}
 
-		name := fn.Name.Name
-
-		if fn.Recv != nil && len(fn.Recv.List) > 0 {
-			recv := fn.Recv.List[0].Type
-			if star, ok := recv.(*ast.StarExpr); ok {
-				recv = star.X
-			}
-
-			if ident, ok := recv.(*ast.Ident); ok {
-				name = fmt.Sprintf("%s_%s", ident.Name, fn.Name.Name)
-			}
-		}
-
 		result = append(result, funcInfo{
-			Name:      name,
+			Name:      funcName(fn),
 			Lines:     lines,
 			StartLine: startLine,
 			EndLine:   endLine,
@@ -2144,7 +2236,7 @@ func parseModulePath(goModContent string) string {
 
 func parseCoverProfile(data, modulePath string) map[string][]coverBlock {
 	result := make(map[string][]coverBlock)
-	lineRegex := regexp.MustCompile(`^(.+):(\d+)\.\d+,(\d+)\.\d+\s+\d+\s+(\d+)$`)
+	lineRegex := regexp.MustCompile(`^(.+):(\d+)\.\d+,(\d+)\.\d+\s+(\d+)\s+(\d+)$`)
 
 	scanner := bufio.NewScanner(strings.NewReader(data))
 
@@ -2156,7 +2248,7 @@ func parseCoverProfile(data, modulePath string) map[string][]coverBlock {
 		}
 
 		matches := lineRegex.FindStringSubmatch(line)
-		if len(matches) != 5 {
+		if len(matches) != 6 {
 			continue
 		}
 
@@ -2177,7 +2269,12 @@ func parseCoverProfile(data, modulePath string) map[string][]coverBlock {
 			continue
 		}
 
-		count, err := strconv.Atoi(matches[4])
+		numStmt, err := strconv.Atoi(matches[4])
+		if err != nil {
+			continue
+		}
+
+		count, err := strconv.Atoi(matches[5])
 		if err != nil {
 			continue
 		}
@@ -2185,6 +2282,7 @@ func parseCoverProfile(data, modulePath string) map[string][]coverBlock {
 		result[filePath] = append(result[filePath], coverBlock{
 			StartLine: startLine,
 			EndLine:   endLine,
+			NumStmt:   numStmt,
 			Count:     count,
 		})
 	}
@@ -2202,7 +2300,7 @@ func isFuncCovered(blocks []coverBlock, fn funcInfo) bool {
 	return false
 }
 
-func findUncoveredLargeFunctions(profilePath string, maxUncoveredFuncLines int, excludePackages []string) ([]string, error) {
+func findUncoveredLargeFunctions(idx *fileIndex, profilePath string, maxUncoveredFuncLines int, excludePackages []string) ([]Violation, error) {
 	goModData, err := os.ReadFile("go.mod")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read go.mod: %w", err)
@@ -2217,118 +2315,84 @@ func findUncoveredLargeFunctions(profilePath string, maxUncoveredFuncLines int,
 
 	coverageMap := parseCoverProfile(string(profileData), modulePath)
 
-	var violations []string
-
-	err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			switch info.Name() {
-			case "vendor", ".git", "test", "tests", "examples":
-				return filepath.SkipDir
-			}
-
-			if isDirExcluded(path, excludePackages) {
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
+	var violations []Violation
 
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, ".pb.go") {
-			return nil
-		}
-
-		if hasSkipDirective(path) {
-			return nil
-		}
-
-		funcs := largeFunctions(path, maxUncoveredFuncLines)
-		if len(funcs) == 0 {
-			return nil
+	for _, file := range idx.sources() {
+		if file.skip || isDirExcluded(filepath.ToSlash(filepath.Dir(file.path)), excludePackages) {
+			continue
 		}
 
-		blocks := coverageMap[path]
+		blocks := coverageMap[file.path]
 
-		for _, fn := range funcs {
+		for _, fn := range largeFunctions(file, maxUncoveredFuncLines) {
 			if !isFuncCovered(blocks, fn) {
-				violations = append(violations,
-					fmt.Sprintf("  - %s: function '%s' (%d lines) has no test coverage", path, fn.Name, fn.Lines))
+				violations = append(violations, Violation{
+					Rule:       RuleCoverage,
+					File:       file.path,
+					Line:       fn.StartLine,
+					Severity:   SeverityError,
+					Message:    fmt.Sprintf("function '%s' (%d lines) has no test coverage", fn.Name, fn.Lines),
+					Suggestion: "add tests that exercise this function",
+				})
 			}
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
 	return violations, nil
 }
 
-func checkTestDuration(maxDuration time.Duration) error {
-	log.Printf("Checking test duration (maximum %s per package)...", maxDuration)
-
-	// Do not pass -timeout=maxDuration here: that kills any package exceeding
-	// maxDuration before it emits a pass/fail event, so its real elapsed time is
-	// never measured and the violation is silently missed. Tests must run to
-	// completion so their actual duration can be compared against maxDuration.
-	cmd := exec.Command("go", "test", "-json", "./...")
-	cmd.Stderr = os.Stderr
+func checkTestDuration(idx *fileIndex, tests *testrun.Result, opts testDurationOptions) ([]Violation, error) {
+	log.Printf("Checking test duration (maximum %s per package)...", opts.limits.maxDuration)
 
-	out, err := cmd.Output()
+	goModData, err := os.ReadFile("go.mod")
 	if err != nil {
-		if len(out) > 0 {
-			violations := parseTestDurationOutput(out, maxDuration)
-			if len(violations) > 0 {
-				return fmt.Errorf("test duration violations (maximum %s per package):\n%s",
-					maxDuration, strings.Join(violations, "\n"))
-			}
-		}
-
-		return fmt.Errorf("failed to run test duration check: %w", err)
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
 	}
 
-	violations := parseTestDurationOutput(out, maxDuration)
-	if len(violations) > 0 {
-		return fmt.Errorf("test duration violations (maximum %s per package):\n%s",
-			maxDuration, strings.Join(violations, "\n"))
-	}
+	modulePath := parseModulePath(string(goModData))
 
-	return nil
-}
+	// Durations come from the untagged pass only; the run has no -timeout so a
+	// slow package still reports its real elapsed time instead of being killed
+	// before its pass/fail event.
+	pass := tests.Untagged()
+	violations := testDurationViolations(pass.Events, modulePath, opts)
+	violations = append(violations, slowTestViolations(idx, pass.Events, modulePath, opts)...)
 
-func parseTestDurationOutput(data []byte, maxDuration time.Duration) []string {
-	type testEvent struct {
-		Action  string  `json:"Action"`
-		Package string  `json:"Package"`
-		Elapsed float64 `json:"Elapsed"`
+	if opts.slowestTests > 0 {
+		logSlowestTests(pass.Events, modulePath, opts.slowestTests)
 	}
 
-	var violations []string
+	if pass.Err != nil && len(violations) == 0 {
+		return nil, fmt.Errorf("failed to run test duration check: %w", pass.Err)
+	}
 
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	for scanner.Scan() {
-		var event testEvent
-		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
-			continue
-		}
+	return violations, nil
+}
 
+// testDurationViolations reports packages whose tests ran longer than their
+// max_duration. Test and subtest events are left to slowTestViolations.
+func testDurationViolations(events []testrun.Event, modulePath string, opts testDurationOptions) []Violation {
+	var violations []Violation
+
+	for _, event := range events {
 		if event.Action != "pass" && event.Action != "fail" {
 			continue
 		}
 
-		if event.Package == "" || event.Elapsed == 0 {
+		if event.Package == "" || event.Test != "" || event.Elapsed == 0 {
 			continue
 		}
 
+		maxDuration := opts.limitsFor(event.Package, modulePath).maxDuration
+
 		elapsed := time.Duration(event.Elapsed * float64(time.Second))
 		if elapsed > maxDuration {
-			violations = append(violations,
-				fmt.Sprintf("  - %s: %s (maximum %s)", event.Package, elapsed, maxDuration))
+			violations = append(violations, Violation{
+				Rule:     RuleTestDuration,
+				File:     packageToDir(event.Package, modulePath),
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("tests took %s (maximum %s)", elapsed, maxDuration),
+			})
 		}
 	}
 
@@ -2340,63 +2404,95 @@ type coverageOptions struct {
 	maxUncoveredFuncLines int
 	excludePackages       []string
 	packageOverrides      map[string]float64
-}
-
-func checkCoverage(opts coverageOptions) error {
+	// patchMin is the minimum coverage of the lines changed since the
+	// default branch; zero turns the patch check off.
+	patchMin float64
+	// ratchet compares each package with its floor in CoverageFile,
+	// allowing drops of up to ratchetTolerance percentage points.
+	ratchet          bool
+	ratchetTolerance float64
+	// coverDirs are GOCOVERDIR directories merged with the test passes.
+	coverDirs []string
+}
+
+func checkCoverage(ctx context.Context, idx *fileIndex, tests *testrun.Result, opts coverageOptions) ([]Violation, error) {
 	log.Printf("Checking code coverage (minimum %.0f%% per package)...", opts.minCoverage)
 
-	tmpFile, err := os.CreateTemp("", "coverage-*.out")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+	if err := tests.Err(); err != nil {
+		return nil, fmt.Errorf("failed to run coverage check: %w", err)
 	}
 
-	tmpFile.Close()
-
-	defer os.Remove(tmpFile.Name())
-
-	cmd := exec.Command("go", "test", fmt.Sprintf("-coverprofile=%s", tmpFile.Name()), "./...")
-
-	var stdout bytes.Buffer
+	goModData, err := os.ReadFile("go.mod")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
 
-	cmd.Stdout = &stdout
-	cmd.Stderr = os.Stderr
+	modulePath := parseModulePath(string(goModData))
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run coverage check: %w", err)
+	profile, err := tests.MergedProfile(ctx, opts.coverDirs)
+	if err != nil {
+		return nil, err
 	}
 
-	goModData, err := os.ReadFile("go.mod")
+	profileData, err := os.ReadFile(profile)
 	if err != nil {
-		return fmt.Errorf("failed to read go.mod: %w", err)
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
 	}
 
-	modulePath := parseModulePath(string(goModData))
+	measured := packageCoverage(parseCoverProfile(string(profileData), modulePath), opts.excludePackages)
 
-	violations, err := parseCoverageOutput(stdout.String(), modulePath, opts)
+	violations, err := parseCoverageOutput(idx, tests.Output(), modulePath, measured, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	funcViolations, err := findUncoveredLargeFunctions(tmpFile.Name(), opts.maxUncoveredFuncLines, opts.excludePackages)
+	funcViolations, err := findUncoveredLargeFunctions(idx, profile, opts.maxUncoveredFuncLines, opts.excludePackages)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	violations = append(violations, funcViolations...)
 
-	if len(violations) > 0 {
-		return fmt.Errorf("coverage violations (minimum %.0f%%):\n%s",
-			opts.minCoverage, strings.Join(violations, "\n"))
+	if opts.patchMin > 0 {
+		patchViolations, err := checkPatchCoverage(ctx, profile, modulePath, opts.patchMin)
+		if err != nil {
+			return nil, err
+		}
+
+		violations = append(violations, patchViolations...)
+	}
+
+	if opts.ratchet {
+		ratchetViolations, err := checkCoverageRatchet(CoverageFile, measured, opts.ratchetTolerance)
+		if err != nil {
+			return nil, err
+		}
+
+		violations = append(violations, ratchetViolations...)
 	}
 
-	return nil
+	return violations, nil
 }
 
-func parseCoverageOutput(output, modulePath string, opts coverageOptions) ([]string, error) {
-	var violations []string
+// coverageRegex matches the package summary go test prints with -cover, and
+// noCoverageRegex a package without test files.
+var (
+	coverageRegex   = regexp.MustCompile(`ok\s+(\S+)\s+(?:[\d.]+s|\(cached\))\s+coverage:\s+([\d.]+)%`)
+	noCoverageRegex = regexp.MustCompile(`\?\s+(\S+)\s+\[no test files\]`)
+)
 
-	coverageRegex := regexp.MustCompile(`ok\s+(\S+)\s+(?:[\d.]+s|\(cached\))\s+coverage:\s+([\d.]+)%`)
-	noCoverageRegex := regexp.MustCompile(`\?\s+(\S+)\s+\[no test files\]`)
+// parseCoverageOutput checks the package summaries go test printed over
+// every pass. measured holds the merged coverage of each package by
+// directory; a package found there is judged by it rather than by the
+// percentage a single pass printed. A package one pass reports without test
+// files passes when another pass tested it; one that no pass tested is
+// judged by its indexed sources.
+func parseCoverageOutput(idx *fileIndex, output, modulePath string, measured map[string]float64, opts coverageOptions) ([]Violation, error) {
+	var (
+		tested   []string
+		untested []string
+		printed  = make(map[string]float64)
+	)
 
 	scanner := bufio.NewScanner(strings.NewReader(output))
 
@@ -2404,41 +2500,70 @@ func parseCoverageOutput(output, modulePath string, opts coverageOptions) ([]str
 		line := scanner.Text()
 
 		if matches := coverageRegex.FindStringSubmatch(line); len(matches) == 3 {
-			pkgName := matches[1]
-
-			if isPackageExcluded(pkgName, modulePath, opts.excludePackages) {
-				continue
-			}
-
 			coverage, err := strconv.ParseFloat(matches[2], 64)
 			if err != nil {
 				continue
 			}
 
-			minCov := packageMinCoverage(pkgName, modulePath, opts.minCoverage, opts.packageOverrides)
-
-			if coverage < minCov {
-				violations = append(violations,
-					fmt.Sprintf("  - %s: %.1f%% coverage (minimum %.0f%%)", pkgName, coverage, minCov))
+			if _, seen := printed[matches[1]]; !seen {
+				tested = append(tested, matches[1])
 			}
+
+			printed[matches[1]] = max(printed[matches[1]], coverage)
 		}
 
-		if matches := noCoverageRegex.FindStringSubmatch(line); len(matches) == 2 {
-			pkgName := matches[1]
+		if matches := noCoverageRegex.FindStringSubmatch(line); len(matches) == 2 && !slices.Contains(untested, matches[1]) {
+			untested = append(untested, matches[1])
+		}
+	}
 
-			if isPackageExcluded(pkgName, modulePath, opts.excludePackages) {
-				continue
-			}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-			dir := packageToDir(pkgName, modulePath)
-			if packageNeedsTests(dir) {
-				violations = append(violations,
-					fmt.Sprintf("  - %s: no test files", pkgName))
-			}
+	var violations []Violation
+
+	for _, pkgName := range tested {
+		if isPackageExcluded(pkgName, modulePath, opts.excludePackages) {
+			continue
+		}
+
+		dir := packageToDir(pkgName, modulePath)
+
+		coverage, ok := measured[dir]
+		if !ok {
+			coverage = printed[pkgName]
+		}
+
+		minCov := packageMinCoverage(pkgName, modulePath, opts.minCoverage, opts.packageOverrides)
+
+		if coverage < minCov {
+			violations = append(violations, Violation{
+				Rule:     RuleCoverage,
+				File:     dir,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%.1f%% coverage (minimum %.0f%%)", coverage, minCov),
+			})
+		}
+	}
+
+	for _, pkgName := range untested {
+		if _, ok := printed[pkgName]; ok || isPackageExcluded(pkgName, modulePath, opts.excludePackages) {
+			continue
+		}
+
+		dir := packageToDir(pkgName, modulePath)
+		if packageNeedsTests(idx, dir) {
+			violations = append(violations, Violation{
+				Rule:     RuleCoverage,
+				File:     dir,
+				Severity: SeverityError,
+				Message:  "no test files",
+			})
 		}
 	}
 
-	return violations, scanner.Err()
+	return violations, nil
 }
 
 func packageToDir(pkgName, modulePath string) string {
@@ -2492,24 +2617,16 @@ func packageMinCoverage(pkgName, modulePath string, defaultMin float64, override
 	return defaultMin
 }
 
-func packageNeedsTests(dir string) bool {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return true
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, ".pb.go") {
+// packageNeedsTests reports whether the indexed sources of dir hold a
+// significant function outside of files marked //yake:skip-test. Generated
+// protobuf and excluded files are not indexed and never need tests.
+func packageNeedsTests(idx *fileIndex, dir string) bool {
+	for _, file := range idx.sources() {
+		if filepath.Dir(file.path) != filepath.FromSlash(dir) || file.skip || file.parsed() == nil {
 			continue
 		}
 
-		path := filepath.Join(dir, name)
-		if !hasSkipDirective(path) && hasSignificantFunctions(path) {
+		if hasSignificantFunctions(file) {
 			return true
 		}
 	}
@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -15,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
 )
 
 func boolPtr(v bool) *bool          { return &v }
@@ -23,6 +25,15 @@ func float64Ptr(v float64) *float64 { return &v }
 func stringPtr(v string) *string    { return &v }
 func nonASCIIFixtureWord() string   { return string([]byte{'c', 'a', 'f', 0xc3, 0xa9}) }
 
+func violationsText(violations []Violation) string {
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, v.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 const validTestFile = `package test
 
 import "testing"
@@ -52,9 +63,10 @@ func Test_checkEntryPoints(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows cmd entry points only", func(t *testing.T) {
@@ -67,9 +79,10 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.MkdirAll("cmd/app1", 0755))
 		require.NoError(t, os.WriteFile("cmd/app1/main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows nested cmd entry points", func(t *testing.T) {
@@ -82,9 +95,10 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.MkdirAll("cmd/tools/migrate", 0755))
 		require.NoError(t, os.WriteFile("cmd/tools/migrate/main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows no main files (library)", func(t *testing.T) {
@@ -97,9 +111,10 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/pkg", 0755))
 		require.NoError(t, os.WriteFile("internal/pkg/lib.go", []byte("package pkg\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("rejects both root and cmd entry points", func(t *testing.T) {
@@ -113,10 +128,11 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.MkdirAll("cmd/app1", 0755))
 		require.NoError(t, os.WriteFile("cmd/app1/main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "entry point violation")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "found both root main.go and cmd/ entry points")
 	})
 
 	t.Run("rejects root and nested cmd entry points", func(t *testing.T) {
@@ -130,10 +146,11 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.MkdirAll("cmd/tools/migrate", 0755))
 		require.NoError(t, os.WriteFile("cmd/tools/migrate/main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "entry point violation")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "found both root main.go and cmd/ entry points")
 	})
 
 	t.Run("rejects package main in non-main.go file", func(t *testing.T) {
@@ -146,11 +163,12 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 		require.NoError(t, os.WriteFile("version.go", []byte("package main\n\nvar version = \"dev\"\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "version.go")
-		assert.Contains(t, err.Error(), "package main only allowed in main.go")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "version.go")
+		assert.Contains(t, violationsText(violations), "package main only allowed in main.go")
 	})
 
 	t.Run("rejects package main in cmd non-main.go file", func(t *testing.T) {
@@ -164,11 +182,12 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.WriteFile("cmd/app/main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 		require.NoError(t, os.WriteFile("cmd/app/config.go", []byte("package main\n\nvar cfg = \"default\"\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "cmd/app/config.go")
-		assert.Contains(t, err.Error(), "package main only allowed in main.go")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "cmd/app/config.go")
+		assert.Contains(t, violationsText(violations), "package main only allowed in main.go")
 	})
 
 	t.Run("allows non-main package files alongside main.go", func(t *testing.T) {
@@ -182,9 +201,10 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 		require.NoError(t, os.WriteFile("internal/pkg/lib.go", []byte("package pkg\n\nvar x = 1\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows test file with package main", func(t *testing.T) {
@@ -197,9 +217,10 @@ func Test_checkEntryPoints(t *testing.T) {
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 		require.NoError(t, os.WriteFile("main_test.go", []byte("package main\n\nimport \"testing\"\n\nfunc TestMain(t *testing.T) {}\n"), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("rejects extra functions in main.go", func(t *testing.T) {
@@ -212,10 +233,11 @@ func Test_checkEntryPoints(t *testing.T) {
 		content := "package main\n\nfunc main() {}\n\nfunc helper() {}\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(content), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "unexpected function 'helper'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "unexpected function 'helper'")
 	})
 
 	t.Run("rejects init function in main.go", func(t *testing.T) {
@@ -228,10 +250,11 @@ func Test_checkEntryPoints(t *testing.T) {
 		content := "package main\n\nfunc init() {}\n\nfunc main() {}\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(content), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "unexpected function 'init'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "unexpected function 'init'")
 	})
 
 	t.Run("rejects oversized main function", func(t *testing.T) {
@@ -250,10 +273,11 @@ func Test_checkEntryPoints(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("main.go", []byte(strings.Join(lines, "\n")), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "main() is 30 lines")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "main() is 30 lines")
 	})
 
 	t.Run("rejects extra functions in cmd main.go", func(t *testing.T) {
@@ -267,11 +291,12 @@ func Test_checkEntryPoints(t *testing.T) {
 		content := "package main\n\nfunc main() {}\n\nfunc run() {}\n"
 		require.NoError(t, os.WriteFile("cmd/app1/main.go", []byte(content), 0644))
 
-		err := checkEntryPoints(config.DefaultMaxMainLines)
+		violations, err := checkEntryPoints(testFileIndex(t), config.DefaultMaxMainLines)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "unexpected function 'run'")
-		assert.Contains(t, err.Error(), "cmd/app1/main.go")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "unexpected function 'run'")
+		assert.Contains(t, violationsText(violations), "cmd/app1/main.go")
 	})
 }
 
@@ -281,7 +306,7 @@ func Test_validateMainFiles(t *testing.T) {
 		path := filepath.Join(tmpDir, "main.go")
 		require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		assert.Empty(t, violations)
 	})
@@ -292,10 +317,10 @@ func Test_validateMainFiles(t *testing.T) {
 		content := "package main\n\nfunc main() {}\n\nfunc setup() {}\n"
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "unexpected function 'setup'")
+		assert.Contains(t, violations[0].String(), "unexpected function 'setup'")
 	})
 
 	t.Run("rejects init function", func(t *testing.T) {
@@ -304,10 +329,10 @@ func Test_validateMainFiles(t *testing.T) {
 		content := "package main\n\nfunc init() {}\n\nfunc main() {}\n"
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "unexpected function 'init'")
+		assert.Contains(t, violations[0].String(), "unexpected function 'init'")
 	})
 
 	t.Run("rejects main exceeding max lines", func(t *testing.T) {
@@ -323,11 +348,11 @@ func Test_validateMainFiles(t *testing.T) {
 
 		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "main() is 30 lines")
-		assert.Contains(t, violations[0], fmt.Sprintf("maximum %d", config.DefaultMaxUncoveredFuncLines))
+		assert.Contains(t, violations[0].String(), "main() is 30 lines")
+		assert.Contains(t, violations[0].String(), fmt.Sprintf("maximum %d", config.DefaultMaxUncoveredFuncLines))
 	})
 
 	t.Run("allows main within max lines", func(t *testing.T) {
@@ -343,7 +368,7 @@ func Test_validateMainFiles(t *testing.T) {
 
 		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		assert.Empty(t, violations)
 	})
@@ -354,7 +379,7 @@ func Test_validateMainFiles(t *testing.T) {
 		content := "package main\n\nfunc init() {}\n\nfunc main() {}\n\nfunc helper() {}\n"
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		assert.Len(t, violations, 2)
 	})
@@ -364,10 +389,10 @@ func Test_validateMainFiles(t *testing.T) {
 		path := filepath.Join(tmpDir, "main.go")
 		require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
 
-		violations := validateMainFiles([]string{path}, config.DefaultMaxMainLines)
+		violations := validateMainFiles([]*sourceFile{testSourceFile(t, path)}, config.DefaultMaxMainLines)
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "missing main()")
+		assert.Contains(t, violations[0].String(), "missing main()")
 	})
 
 	t.Run("empty paths returns no violations", func(t *testing.T) {
@@ -400,9 +425,10 @@ func createFile(name string) error {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects stdlib wrapper", func(t *testing.T) {
@@ -422,10 +448,11 @@ func removeFile(name string) error {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "wrapper around 'os.Remove'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "wrapper around 'os.Remove'")
 	})
 
 	t.Run("detects golang.org/x wrapper", func(t *testing.T) {
@@ -446,9 +473,10 @@ func resetTransformer(t transform.Transformer) {
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
 		// Will not detect since it's a method call on param, not a pkg.Func call
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows third-party wrappers", func(t *testing.T) {
@@ -468,9 +496,10 @@ func wrap(name string) error {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips test files", func(t *testing.T) {
@@ -490,9 +519,10 @@ func removeFile(name string) error {
 `
 		require.NoError(t, os.WriteFile("main_test.go", []byte(code), 0644))
 
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows functions with extra logic", func(t *testing.T) {
@@ -516,9 +546,10 @@ func readFile(name string) ([]byte, error) {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects wrapper with hardcoded extra args", func(t *testing.T) {
@@ -538,10 +569,11 @@ func writeConfig(path string, data []byte) error {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStdlibWrappers()
+		violations, err := checkStdlibWrappers(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "wrapper around 'os.WriteFile'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "wrapper around 'os.WriteFile'")
 	})
 }
 
@@ -559,10 +591,10 @@ func remove(name string) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStdlibWrappers(path)
+		violations := findStdlibWrappers(testSourceFile(t, path))
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "os.Remove")
+		assert.Contains(t, violations[0].String(), "os.Remove")
 	})
 
 	t.Run("ignores methods", func(t *testing.T) {
@@ -580,17 +612,11 @@ func (m *myType) remove(name string) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStdlibWrappers(path)
+		violations := findStdlibWrappers(testSourceFile(t, path))
 
 		assert.Empty(t, violations)
 	})
 
-	t.Run("returns nil for non-existent file", func(t *testing.T) {
-		violations := findStdlibWrappers("/nonexistent/file.go")
-
-		assert.Nil(t, violations)
-	})
-
 	t.Run("ignores multi-statement functions", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		path := filepath.Join(tmpDir, "test.go")
@@ -608,7 +634,7 @@ func remove(name string) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStdlibWrappers(path)
+		violations := findStdlibWrappers(testSourceFile(t, path))
 
 		assert.Empty(t, violations)
 	})
@@ -629,7 +655,7 @@ func IsEncrypted(value string) bool {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStdlibWrappers(path)
+		violations := findStdlibWrappers(testSourceFile(t, path))
 
 		assert.Empty(t, violations)
 	})
@@ -648,7 +674,7 @@ func remove(name string) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStdlibWrappers(path)
+		violations := findStdlibWrappers(testSourceFile(t, path))
 
 		assert.Empty(t, violations)
 	})
@@ -666,10 +692,10 @@ func remove(name string) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStdlibWrappers(path)
+		violations := findStdlibWrappers(testSourceFile(t, path))
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "myos.Remove")
+		assert.Contains(t, violations[0].String(), "myos.Remove")
 	})
 }
 
@@ -785,9 +811,10 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nfunc main() {\n\tx := fmt.Sprintf(\"%s %s\", \"hello\", \"world\")\n\t_ = x\n}\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects string literal concatenation", func(t *testing.T) {
@@ -800,10 +827,11 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nvar x = \"hello\" + \" world\"\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "string concatenation with '+'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "string concatenation with '+'")
 	})
 
 	t.Run("detects variable plus string literal", func(t *testing.T) {
@@ -816,10 +844,11 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nfunc main() {\n\tx := \"prefix\"\n\ty := x + \".go\"\n\t_ = y\n}\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "string concatenation with '+'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "string concatenation with '+'")
 	})
 
 	t.Run("allows += operator", func(t *testing.T) {
@@ -832,9 +861,10 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nfunc main() {\n\tx := \"hello\"\n\tx += \" world\"\n\t_ = x\n}\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows integer addition", func(t *testing.T) {
@@ -847,9 +877,10 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nfunc main() {\n\tx := 1 + 2\n\t_ = x\n}\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("checks test files too", func(t *testing.T) {
@@ -862,10 +893,11 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nimport \"testing\"\n\nfunc TestFoo(t *testing.T) {\n\tx := \"a\" + \"b\"\n\t_ = x\n}\n"
 		require.NoError(t, os.WriteFile("main_test.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "string concatenation with '+'")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "string concatenation with '+'")
 	})
 
 	t.Run("skips vendor directory", func(t *testing.T) {
@@ -879,9 +911,10 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package lib\n\nvar x = \"a\" + \"b\"\n"
 		require.NoError(t, os.WriteFile("vendor/lib/lib.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips .pb.go files", func(t *testing.T) {
@@ -894,9 +927,10 @@ func Test_checkStringConcat(t *testing.T) {
 		code := "package main\n\nvar x = \"a\" + \"b\"\n"
 		require.NoError(t, os.WriteFile("msg.pb.go", []byte(code), 0644))
 
-		err := checkStringConcat()
+		violations, err := checkStringConcat(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
@@ -906,10 +940,10 @@ func Test_findStringConcatenations(t *testing.T) {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte("package main\n\nvar x = \"a\" + \"b\"\n"), 0644))
 
-		violations := findStringConcatenations(path)
+		violations := findStringConcatenations(testSourceFile(t, path))
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "string concatenation with '+'")
+		assert.Contains(t, violations[0].String(), "string concatenation with '+'")
 	})
 
 	t.Run("returns empty for integer addition", func(t *testing.T) {
@@ -917,24 +951,18 @@ func Test_findStringConcatenations(t *testing.T) {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte("package main\n\nvar x = 1 + 2\n"), 0644))
 
-		violations := findStringConcatenations(path)
+		violations := findStringConcatenations(testSourceFile(t, path))
 
 		assert.Empty(t, violations)
 	})
 
-	t.Run("returns nil for non-existent file", func(t *testing.T) {
-		violations := findStringConcatenations("/nonexistent/file.go")
-
-		assert.Nil(t, violations)
-	})
-
 	t.Run("detects multiple concatenations", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		path := filepath.Join(tmpDir, "test.go")
 		code := "package main\n\nvar x = \"a\" + \"b\"\nvar y = \"c\" + \"d\"\n"
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStringConcatenations(path)
+		violations := findStringConcatenations(testSourceFile(t, path))
 
 		assert.Len(t, violations, 2)
 	})
@@ -945,7 +973,7 @@ func Test_findStringConcatenations(t *testing.T) {
 		code := "package main\n\nvar x = \"a\" + \"b\" + \"c\"\n"
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findStringConcatenations(path)
+		violations := findStringConcatenations(testSourceFile(t, path))
 
 		assert.Len(t, violations, 1)
 	})
@@ -962,9 +990,10 @@ func Test_checkASCIIOnly(t *testing.T) {
 		code := "package main\n\nconst greeting = \"hello\"\n"
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkASCIIOnly()
+		violations, err := checkASCIIOnly(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects non-ASCII characters", func(t *testing.T) {
@@ -977,12 +1006,13 @@ func Test_checkASCIIOnly(t *testing.T) {
 		code := fmt.Sprintf("package main\n\nconst greeting = %q\n", nonASCIIFixtureWord())
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkASCIIOnly()
+		violations, err := checkASCIIOnly(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "ASCII-only violations")
-		assert.Contains(t, err.Error(), "main.go:3:22")
-		assert.Contains(t, err.Error(), "U+00E9")
+		require.NotEmpty(t, violations)
+		assert.Equal(t, RuleASCIIOnly, violations[0].Rule)
+		assert.Contains(t, violationsText(violations), "main.go:3:22")
+		assert.Contains(t, violationsText(violations), "U+00E9")
 	})
 
 	t.Run("allows non-ASCII characters in test files", func(t *testing.T) {
@@ -995,9 +1025,10 @@ func Test_checkASCIIOnly(t *testing.T) {
 		code := fmt.Sprintf("package main\n\nfunc TestCafe() { _ = %q }\n", nonASCIIFixtureWord())
 		require.NoError(t, os.WriteFile("main_test.go", []byte(code), 0644))
 
-		err := checkASCIIOnly()
+		violations, err := checkASCIIOnly(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips vendor directory", func(t *testing.T) {
@@ -1011,9 +1042,10 @@ func Test_checkASCIIOnly(t *testing.T) {
 		code := fmt.Sprintf("package lib\n\nconst greeting = %q\n", nonASCIIFixtureWord())
 		require.NoError(t, os.WriteFile("vendor/lib/lib.go", []byte(code), 0644))
 
-		err := checkASCIIOnly()
+		violations, err := checkASCIIOnly(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips .pb.go files", func(t *testing.T) {
@@ -1026,9 +1058,10 @@ func Test_checkASCIIOnly(t *testing.T) {
 		code := fmt.Sprintf("package main\n\nconst greeting = %q\n", nonASCIIFixtureWord())
 		require.NoError(t, os.WriteFile("msg.pb.go", []byte(code), 0644))
 
-		err := checkASCIIOnly()
+		violations, err := checkASCIIOnly(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
@@ -1038,7 +1071,7 @@ func Test_findNonASCIIChars(t *testing.T) {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte("package main\n\nconst value = \"ascii\"\n"), 0644))
 
-		violations := findNonASCIIChars(path)
+		violations := findNonASCIIChars(testSourceFile(t, path))
 
 		assert.Empty(t, violations)
 	})
@@ -1049,11 +1082,11 @@ func Test_findNonASCIIChars(t *testing.T) {
 		code := fmt.Sprintf("package main\n\nconst value = %q\n", nonASCIIFixtureWord())
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findNonASCIIChars(path)
+		violations := findNonASCIIChars(testSourceFile(t, path))
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "test.go:3:19")
-		assert.Contains(t, violations[0], "U+00E9")
+		assert.Contains(t, violations[0].String(), "test.go:3:19")
+		assert.Contains(t, violations[0].String(), "U+00E9")
 	})
 
 	t.Run("reports invalid non-ASCII bytes", func(t *testing.T) {
@@ -1063,17 +1096,11 @@ func Test_findNonASCIIChars(t *testing.T) {
 		data = append(data, []byte("\"\n")...)
 		require.NoError(t, os.WriteFile(path, data, 0644))
 
-		violations := findNonASCIIChars(path)
+		violations := findNonASCIIChars(testSourceFile(t, path))
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "test.go:3:14")
-		assert.Contains(t, violations[0], "0xFF")
-	})
-
-	t.Run("returns nil for non-existent file", func(t *testing.T) {
-		violations := findNonASCIIChars("/nonexistent/file.go")
-
-		assert.Nil(t, violations)
+		assert.Contains(t, violations[0].String(), "test.go:3:14")
+		assert.Contains(t, violations[0].String(), "0xFF")
 	})
 }
 
@@ -1146,7 +1173,7 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package test"), 0644))
 		require.NoError(t, os.WriteFile("service_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("service_test.go")
+		violations := validateTestFileName(testSourceFile(t, "service_test.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1160,7 +1187,7 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package test"), 0644))
 		require.NoError(t, os.WriteFile("service_e2e_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("service_e2e_test.go")
+		violations := validateTestFileName(testSourceFile(t, "service_e2e_test.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1173,9 +1200,9 @@ func Test_validateTestFileName(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("orphan_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("orphan_test.go")
+		violations := validateTestFileName(testSourceFile(t, "orphan_test.go"))
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "missing source file")
+		assert.Contains(t, violations[0].String(), "missing source file")
 	})
 
 	t.Run("test file missing testing import", func(t *testing.T) {
@@ -1188,9 +1215,9 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package test"), 0644))
 		require.NoError(t, os.WriteFile("service_test.go", []byte(testFileWithoutTestingImport), 0644))
 
-		violations := validateTestFileName("service_test.go")
+		violations := validateTestFileName(testSourceFile(t, "service_test.go"))
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "missing 'testing' package import")
+		assert.Contains(t, violations[0].String(), "missing 'testing' package import")
 	})
 
 	t.Run("skips test file without functions", func(t *testing.T) {
@@ -1202,7 +1229,7 @@ func Test_validateTestFileName(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("empty_test.go", []byte(testFileWithoutFunctions), 0644))
 
-		violations := validateTestFileName("empty_test.go")
+		violations := validateTestFileName(testSourceFile(t, "empty_test.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1216,9 +1243,9 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package test"), 0644))
 		require.NoError(t, os.WriteFile("service_unit_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("service_unit_test.go")
+		violations := validateTestFileName(testSourceFile(t, "service_unit_test.go"))
 		require.NotEmpty(t, violations)
-		assert.Contains(t, violations[0], "invalid naming pattern")
+		assert.Contains(t, violations[0].String(), "invalid naming pattern")
 	})
 
 	t.Run("invalid bench test naming pattern", func(t *testing.T) {
@@ -1231,9 +1258,9 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package test"), 0644))
 		require.NoError(t, os.WriteFile("service_bench_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("service_bench_test.go")
+		violations := validateTestFileName(testSourceFile(t, "service_bench_test.go"))
 		require.NotEmpty(t, violations)
-		assert.Contains(t, violations[0], "invalid naming pattern")
+		assert.Contains(t, violations[0].String(), "invalid naming pattern")
 	})
 
 	t.Run("invalid integration test naming pattern", func(t *testing.T) {
@@ -1246,9 +1273,9 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package test"), 0644))
 		require.NoError(t, os.WriteFile("service_integration_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("service_integration_test.go")
+		violations := validateTestFileName(testSourceFile(t, "service_integration_test.go"))
 		require.NotEmpty(t, violations)
-		assert.Contains(t, violations[0], "invalid naming pattern")
+		assert.Contains(t, violations[0].String(), "invalid naming pattern")
 	})
 
 	t.Run("allows example_test.go without source file", func(t *testing.T) {
@@ -1260,7 +1287,7 @@ func Test_validateTestFileName(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("example_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("example_test.go")
+		violations := validateTestFileName(testSourceFile(t, "example_test.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1274,7 +1301,7 @@ func Test_validateTestFileName(t *testing.T) {
 		exampleFile := "package test\n\nimport \"fmt\"\n\nfunc ExampleHello() {\n\tfmt.Println(\"hello\")\n\t// Output: hello\n}\n"
 		require.NoError(t, os.WriteFile("example_test.go", []byte(exampleFile), 0644))
 
-		violations := validateTestFileName("example_test.go")
+		violations := validateTestFileName(testSourceFile(t, "example_test.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1287,7 +1314,7 @@ func Test_validateTestFileName(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("external_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName("external_test.go")
+		violations := validateTestFileName(testSourceFile(t, "external_test.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1302,7 +1329,7 @@ func Test_validateTestFileName(t *testing.T) {
 		require.NoError(t, os.WriteFile("pkg/service/handler.go", []byte("package service"), 0644))
 		require.NoError(t, os.WriteFile("pkg/service/handler_test.go", []byte(validTestFile), 0644))
 
-		violations := validateTestFileName(filepath.Join("pkg", "service", "handler_test.go"))
+		violations := validateTestFileName(testSourceFile(t, filepath.Join("pkg", "service", "handler_test.go")))
 		assert.Empty(t, violations)
 	})
 
@@ -1315,7 +1342,7 @@ func Test_validateTestFileName(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("orphan_test.go", []byte(testFileWithoutTestingImport), 0644))
 
-		violations := validateTestFileName("orphan_test.go")
+		violations := validateTestFileName(testSourceFile(t, "orphan_test.go"))
 		require.Len(t, violations, 2)
 	})
 }
@@ -1331,7 +1358,7 @@ func Test_validateSourceFile(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package main\nfunc Foo() {}"), 0644))
 		require.NoError(t, os.WriteFile("service_test.go", []byte(validTestFile), 0644))
 
-		violations := validateSourceFile("service.go")
+		violations := validateSourceFile(testSourceFile(t, "service.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1344,7 +1371,7 @@ func Test_validateSourceFile(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
 
-		violations := validateSourceFile("main.go")
+		violations := validateSourceFile(testSourceFile(t, "main.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1357,9 +1384,9 @@ func Test_validateSourceFile(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("main.go", []byte("package qwerty\n\nfunc Foo() {}\n"), 0644))
 
-		violations := validateSourceFile("main.go")
+		violations := validateSourceFile(testSourceFile(t, "main.go"))
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "missing test file")
+		assert.Contains(t, violations[0].String(), "missing test file")
 	})
 
 	t.Run("source file missing test", func(t *testing.T) {
@@ -1371,10 +1398,10 @@ func Test_validateSourceFile(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("auth_cache.go", []byte("package main\nfunc Foo() {}"), 0644))
 
-		violations := validateSourceFile("auth_cache.go")
+		violations := validateSourceFile(testSourceFile(t, "auth_cache.go"))
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "missing test file")
-		assert.Contains(t, violations[0], "auth_cache_test.go")
+		assert.Contains(t, violations[0].String(), "missing test file")
+		assert.Contains(t, violations[0].String(), "auth_cache_test.go")
 	})
 
 	t.Run("source file with skip directive bypasses test requirement", func(t *testing.T) {
@@ -1391,7 +1418,7 @@ func Foo() {}
 `
 		require.NoError(t, os.WriteFile("skipped.go", []byte(code), 0644))
 
-		violations := validateSourceFile("skipped.go")
+		violations := validateSourceFile(testSourceFile(t, "skipped.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1404,7 +1431,7 @@ func Foo() {}
 
 		require.NoError(t, os.WriteFile("pty_darwin.go", []byte("package main\nfunc Foo() {}"), 0644))
 
-		violations := validateSourceFile("pty_darwin.go")
+		violations := validateSourceFile(testSourceFile(t, "pty_darwin.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1417,7 +1444,7 @@ func Foo() {}
 
 		require.NoError(t, os.WriteFile("pty_amd64.go", []byte("package main\nfunc Foo() {}"), 0644))
 
-		violations := validateSourceFile("pty_amd64.go")
+		violations := validateSourceFile(testSourceFile(t, "pty_amd64.go"))
 		assert.Empty(t, violations)
 	})
 
@@ -1430,7 +1457,7 @@ func Foo() {}
 
 		require.NoError(t, os.WriteFile("pty_linux_amd64.go", []byte("package main\nfunc Foo() {}"), 0644))
 
-		violations := validateSourceFile("pty_linux_amd64.go")
+		violations := validateSourceFile(testSourceFile(t, "pty_linux_amd64.go"))
 		assert.Empty(t, violations)
 	})
 }
@@ -1477,13 +1504,9 @@ func Test_hasTestingImport(t *testing.T) {
 			tmpDir := t.TempDir()
 			filePath := filepath.Join(tmpDir, "example.go")
 			require.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0644))
-			assert.Equal(t, tt.expected, hasTestingImport(filePath))
+			assert.Equal(t, tt.expected, hasTestingImport(testSourceFile(t, filePath)))
 		})
 	}
-
-	t.Run("returns false for non-existent file", func(t *testing.T) {
-		assert.False(t, hasTestingImport("/non/existent/file.go"))
-	})
 }
 
 func Test_hasFunctions(t *testing.T) {
@@ -1502,91 +1525,9 @@ func Test_hasFunctions(t *testing.T) {
 			tmpDir := t.TempDir()
 			filePath := filepath.Join(tmpDir, "example.go")
 			require.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0644))
-			assert.Equal(t, tt.expected, hasFunctions(filePath))
+			assert.Equal(t, tt.expected, hasFunctions(testSourceFile(t, filePath)))
 		})
 	}
-
-	t.Run("returns false for non-existent file", func(t *testing.T) {
-		assert.False(t, hasFunctions("/non/existent/file.go"))
-	})
-}
-
-func Test_hasSkipDirective(t *testing.T) {
-	t.Run("returns true when skip directive present", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "main.go")
-
-		code := `//yake:skip-test
-package main
-
-func main() {
-	Execute()
-}
-`
-		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
-
-		assert.True(t, hasSkipDirective(filePath))
-	})
-
-	t.Run("returns true when skip directive after other comments", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "main.go")
-
-		code := `// Some comment
-//yake:skip-test
-package main
-
-func main() {}
-`
-		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
-
-		assert.True(t, hasSkipDirective(filePath))
-	})
-
-	t.Run("returns false when skip directive after package", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "main.go")
-
-		code := `package main
-
-//yake:skip-test
-func main() {}
-`
-		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
-
-		assert.False(t, hasSkipDirective(filePath))
-	})
-
-	t.Run("returns false when no skip directive", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "main.go")
-
-		code := `package main
-
-func main() {}
-`
-		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
-
-		assert.False(t, hasSkipDirective(filePath))
-	})
-
-	t.Run("returns false when directive has space after slashes", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "main.go")
-
-		code := `// yake:skip-test
-package main
-
-func main() {}
-`
-		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
-
-		assert.False(t, hasSkipDirective(filePath))
-	})
-
-	t.Run("returns false for non-existent file", func(t *testing.T) {
-		assert.False(t, hasSkipDirective("/non/existent/file.go"))
-	})
 }
 
 func Test_hasFuncSkipDirective(t *testing.T) {
@@ -1666,7 +1607,7 @@ func Process() error {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.True(t, hasSignificantFunctions(filePath))
+		assert.True(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 
 	t.Run("returns false for function with 5 or less lines", func(t *testing.T) {
@@ -1681,7 +1622,7 @@ func main() {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.False(t, hasSignificantFunctions(filePath))
+		assert.False(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 
 	t.Run("returns false for file with only structs", func(t *testing.T) {
@@ -1696,7 +1637,7 @@ type User struct {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.False(t, hasSignificantFunctions(filePath))
+		assert.False(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 
 	t.Run("returns false when significant function has skip directive", func(t *testing.T) {
@@ -1717,7 +1658,7 @@ func Process() error {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.False(t, hasSignificantFunctions(filePath))
+		assert.False(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 
 	t.Run("returns false when blank lines inflate count to boundary", func(t *testing.T) {
@@ -1737,7 +1678,7 @@ func main() {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.False(t, hasSignificantFunctions(filePath))
+		assert.False(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 
 	t.Run("returns true when code lines exceed threshold despite blanks", func(t *testing.T) {
@@ -1763,7 +1704,7 @@ func Process() error {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.True(t, hasSignificantFunctions(filePath))
+		assert.True(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 
 	t.Run("returns false for exactly 5 code lines with blanks", func(t *testing.T) {
@@ -1785,11 +1726,7 @@ func main() {
 `
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		assert.False(t, hasSignificantFunctions(filePath))
-	})
-
-	t.Run("returns false for non-existent file", func(t *testing.T) {
-		assert.False(t, hasSignificantFunctions("/non/existent/file.go"))
+		assert.False(t, hasSignificantFunctions(testSourceFile(t, filePath)))
 	})
 }
 
@@ -1815,7 +1752,7 @@ func foo() {
 		require.NoError(t, err)
 
 		fn := node.Decls[0].(*ast.FuncDecl)
-		lines := countCodeLines(fset, filePath, fn.Body.Lbrace, fn.Body.Rbrace)
+		lines := countCodeLines(fset, []byte(code), fn.Body.Lbrace, fn.Body.Rbrace)
 		assert.Equal(t, 3, lines)
 	})
 
@@ -1838,7 +1775,7 @@ func foo() {
 		require.NoError(t, err)
 
 		fn := node.Decls[0].(*ast.FuncDecl)
-		lines := countCodeLines(fset, filePath, fn.Body.Lbrace, fn.Body.Rbrace)
+		lines := countCodeLines(fset, []byte(code), fn.Body.Lbrace, fn.Body.Rbrace)
 		assert.Equal(t, 3, lines)
 	})
 
@@ -1858,7 +1795,7 @@ func foo() {
 		require.NoError(t, err)
 
 		fn := node.Decls[0].(*ast.FuncDecl)
-		lines := countCodeLines(fset, filePath, fn.Body.Lbrace, fn.Body.Rbrace)
+		lines := countCodeLines(fset, []byte(code), fn.Body.Lbrace, fn.Body.Rbrace)
 		assert.Equal(t, 0, lines)
 	})
 
@@ -1874,7 +1811,7 @@ func foo() {
 		require.NoError(t, err)
 
 		fn := node.Decls[0].(*ast.FuncDecl)
-		lines := countCodeLines(fset, filePath, fn.Body.Lbrace, fn.Body.Rbrace)
+		lines := countCodeLines(fset, []byte(code), fn.Body.Lbrace, fn.Body.Rbrace)
 		assert.Equal(t, 0, lines)
 	})
 }
@@ -1915,7 +1852,7 @@ func Test_largeFunctions(t *testing.T) {
 		code := fmt.Sprintf("package main\n\n%s", generateLargeFunc("ProcessData", 30))
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		result := largeFunctions(filePath, config.DefaultMaxUncoveredFuncLines)
+		result := largeFunctions(testSourceFile(t, filePath), config.DefaultMaxUncoveredFuncLines)
 		require.Len(t, result, 1)
 		assert.Equal(t, "ProcessData", result[0].Name)
 		assert.Equal(t, 30, result[0].Lines)
@@ -1930,7 +1867,7 @@ func Test_largeFunctions(t *testing.T) {
 		code := fmt.Sprintf("package main\n\n%s", generateLargeFunc("SmallFunc", 25))
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		result := largeFunctions(filePath, config.DefaultMaxUncoveredFuncLines)
+		result := largeFunctions(testSourceFile(t, filePath), config.DefaultMaxUncoveredFuncLines)
 		assert.Empty(t, result)
 	})
 
@@ -1941,7 +1878,7 @@ func Test_largeFunctions(t *testing.T) {
 		code := fmt.Sprintf("package main\n\ntype Service struct{}\n\n%s", generateLargeMethod("Service", "Handle", 30))
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		result := largeFunctions(filePath, config.DefaultMaxUncoveredFuncLines)
+		result := largeFunctions(testSourceFile(t, filePath), config.DefaultMaxUncoveredFuncLines)
 		require.Len(t, result, 1)
 		assert.Equal(t, "Service_Handle", result[0].Name)
 		assert.Equal(t, 30, result[0].Lines)
@@ -1954,7 +1891,7 @@ func Test_largeFunctions(t *testing.T) {
 		code := fmt.Sprintf("package main\n\n%s\n%s", generateLargeFunc("FuncA", 30), generateLargeFunc("FuncB", 30))
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		result := largeFunctions(filePath, config.DefaultMaxUncoveredFuncLines)
+		result := largeFunctions(testSourceFile(t, filePath), config.DefaultMaxUncoveredFuncLines)
 		require.Len(t, result, 2)
 		assert.Equal(t, "FuncA", result[0].Name)
 		assert.Equal(t, "FuncB", result[1].Name)
@@ -1967,15 +1904,10 @@ func Test_largeFunctions(t *testing.T) {
 		code := fmt.Sprintf("package main\n\n//yake:skip-test\n%s\n%s", generateLargeFunc("SkippedFunc", 30), generateLargeFunc("IncludedFunc", 30))
 		require.NoError(t, os.WriteFile(filePath, []byte(code), 0644))
 
-		result := largeFunctions(filePath, config.DefaultMaxUncoveredFuncLines)
+		result := largeFunctions(testSourceFile(t, filePath), config.DefaultMaxUncoveredFuncLines)
 		require.Len(t, result, 1)
 		assert.Equal(t, "IncludedFunc", result[0].Name)
 	})
-
-	t.Run("returns nil for non-existent file", func(t *testing.T) {
-		result := largeFunctions("/non/existent/file.go", config.DefaultMaxUncoveredFuncLines)
-		assert.Nil(t, result)
-	})
 }
 
 func Test_parseCoverProfile(t *testing.T) {
@@ -1991,6 +1923,7 @@ github.com/example/app/main.go:5.15,10.2 3 1
 		require.Len(t, result["internal/service/handler.go"], 2)
 		assert.Equal(t, 10, result["internal/service/handler.go"][0].StartLine)
 		assert.Equal(t, 20, result["internal/service/handler.go"][0].EndLine)
+		assert.Equal(t, 5, result["internal/service/handler.go"][0].NumStmt)
 		assert.Equal(t, 1, result["internal/service/handler.go"][0].Count)
 		assert.Equal(t, 0, result["internal/service/handler.go"][1].Count)
 		require.Len(t, result["main.go"], 1)
@@ -2091,11 +2024,11 @@ func Test_findUncoveredLargeFunctions(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, nil)
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, nil)
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "BigProcess")
-		assert.Contains(t, violations[0], "no test coverage")
+		assert.Contains(t, violations[0].String(), "BigProcess")
+		assert.Contains(t, violations[0].String(), "no test coverage")
 	})
 
 	t.Run("passes when large function is covered", func(t *testing.T) {
@@ -2117,7 +2050,7 @@ func Test_findUncoveredLargeFunctions(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, nil)
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, nil)
 		require.NoError(t, err)
 		assert.Empty(t, violations)
 	})
@@ -2139,7 +2072,7 @@ func Test_findUncoveredLargeFunctions(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, nil)
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, nil)
 		require.NoError(t, err)
 		assert.Empty(t, violations)
 	})
@@ -2161,7 +2094,7 @@ func Test_findUncoveredLargeFunctions(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, nil)
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, nil)
 		require.NoError(t, err)
 		assert.Empty(t, violations)
 	})
@@ -2183,18 +2116,20 @@ func Test_findUncoveredLargeFunctions(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, nil)
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, nil)
 		require.NoError(t, err)
 		assert.Empty(t, violations)
 	})
 }
 
-func Test_parseTestDurationOutput(t *testing.T) {
+func Test_testDurationViolations(t *testing.T) {
+	defaultDurationOptions := resolveTestDurationOptions(nil)
+
 	t.Run("no violations when all packages within limit", func(t *testing.T) {
 		data := `{"Action":"pass","Package":"github.com/example/pkg1","Elapsed":2.5}
 {"Action":"pass","Package":"github.com/example/pkg2","Elapsed":5.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		assert.Empty(t, violations)
 	})
 
@@ -2202,17 +2137,17 @@ func Test_parseTestDurationOutput(t *testing.T) {
 		data := `{"Action":"pass","Package":"github.com/example/fast","Elapsed":1.0}
 {"Action":"pass","Package":"github.com/example/slow","Elapsed":15.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "github.com/example/slow")
+		assert.Contains(t, violations[0].String(), "github.com/example/slow")
 	})
 
 	t.Run("detects failed package exceeding duration", func(t *testing.T) {
 		data := `{"Action":"fail","Package":"github.com/example/slow","Elapsed":12.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "github.com/example/slow")
+		assert.Contains(t, violations[0].String(), "github.com/example/slow")
 	})
 
 	t.Run("ignores non-pass/fail actions", func(t *testing.T) {
@@ -2220,12 +2155,12 @@ func Test_parseTestDurationOutput(t *testing.T) {
 {"Action":"output","Package":"github.com/example/pkg","Output":"ok\n"}
 {"Action":"pass","Package":"github.com/example/pkg","Elapsed":2.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		assert.Empty(t, violations)
 	})
 
 	t.Run("handles empty input", func(t *testing.T) {
-		violations := parseTestDurationOutput([]byte{}, config.DefaultMaxTestDuration)
+		violations := testDurationViolations(nil, "", defaultDurationOptions)
 		assert.Empty(t, violations)
 	})
 
@@ -2233,22 +2168,41 @@ func Test_parseTestDurationOutput(t *testing.T) {
 		data := `not json
 {"Action":"pass","Package":"github.com/example/pkg","Elapsed":2.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		assert.Empty(t, violations)
 	})
 
 	t.Run("detects exactly at boundary", func(t *testing.T) {
 		data := `{"Action":"pass","Package":"github.com/example/pkg","Elapsed":10.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("leaves test events to the per-test check", func(t *testing.T) {
+		data := `{"Action":"pass","Package":"github.com/example/pkg","Test":"TestSlow","Elapsed":12.0}
+{"Action":"pass","Package":"github.com/example/pkg","Elapsed":2.0}
+`
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		assert.Empty(t, violations)
 	})
 
+	t.Run("applies package overrides", func(t *testing.T) {
+		data := `{"Action":"pass","Package":"github.com/example/app/db","Elapsed":20.0}
+{"Action":"pass","Package":"github.com/example/app/api","Elapsed":20.0}
+`
+		opts := defaultDurationOptions
+		opts.overrides = map[string]durationLimits{"db": {maxDuration: 30 * time.Second}}
+
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "github.com/example/app", opts)
+		assert.Equal(t, []string{"api: tests took 20s (maximum 10s)"}, violationStrings(violations))
+	})
+
 	t.Run("detects multiple slow packages", func(t *testing.T) {
 		data := `{"Action":"pass","Package":"github.com/example/slow1","Elapsed":11.0}
 {"Action":"pass","Package":"github.com/example/slow2","Elapsed":20.0}
 `
-		violations := parseTestDurationOutput([]byte(data), config.DefaultMaxTestDuration)
+		violations := testDurationViolations(testrun.ParseEvents([]byte(data)), "", defaultDurationOptions)
 		assert.Len(t, violations, 2)
 	})
 }
@@ -2257,7 +2211,7 @@ func Test_parseCoverageOutput(t *testing.T) {
 	t.Run("parses coverage above threshold", func(t *testing.T) {
 		output := "ok  \tgithub.com/example/pkg\t0.005s\tcoverage: 85.0% of statements"
 
-		violations, err := parseCoverageOutput(output, "", coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(&fileIndex{}, output, "", nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		assert.Empty(t, violations)
@@ -2266,23 +2220,23 @@ func Test_parseCoverageOutput(t *testing.T) {
 	t.Run("detects coverage below threshold", func(t *testing.T) {
 		output := "ok  \tgithub.com/example/pkg\t0.005s\tcoverage: 50.0% of statements"
 
-		violations, err := parseCoverageOutput(output, "", coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(&fileIndex{}, output, "", nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "50.0%")
-		assert.Contains(t, violations[0], "github.com/example/pkg")
+		assert.Contains(t, violations[0].String(), "50.0%")
+		assert.Contains(t, violations[0].String(), "github.com/example/pkg")
 	})
 
 	t.Run("detects no test files", func(t *testing.T) {
 		output := "?\tgithub.com/example/nopkg\t[no test files]"
 
-		violations, err := parseCoverageOutput(output, "", coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(sourceIndex(map[string]string{"github.com/example/nopkg/big.go": significantSource}), output, "", nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "no test files")
-		assert.Contains(t, violations[0], "github.com/example/nopkg")
+		assert.Contains(t, violations[0].String(), "no test files")
+		assert.Contains(t, violations[0].String(), "github.com/example/nopkg")
 	})
 
 	t.Run("handles multiple packages", func(t *testing.T) {
@@ -2291,14 +2245,14 @@ ok  	github.com/example/pkg2	0.003s	coverage: 75.0% of statements
 ?	github.com/example/pkg3	[no test files]
 ok  	github.com/example/pkg4	0.002s	coverage: 100.0% of statements`
 
-		violations, err := parseCoverageOutput(output, "", coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(sourceIndex(map[string]string{"github.com/example/pkg3/big.go": significantSource}), output, "", nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		assert.Len(t, violations, 2)
 	})
 
 	t.Run("handles empty output", func(t *testing.T) {
-		violations, err := parseCoverageOutput("", "", coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(&fileIndex{}, "", "", nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		assert.Empty(t, violations)
@@ -2307,11 +2261,25 @@ ok  	github.com/example/pkg4	0.002s	coverage: 100.0% of statements`
 	t.Run("handles cached coverage output", func(t *testing.T) {
 		output := "ok  \tgithub.com/example/pkg\t(cached)\tcoverage: 75.0% of statements"
 
-		violations, err := parseCoverageOutput(output, "", coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(&fileIndex{}, output, "", nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "75.0%")
+		assert.Contains(t, violations[0].String(), "75.0%")
+	})
+
+	t.Run("judges packages by merged coverage", func(t *testing.T) {
+		output := `ok  	github.com/example/app/store	0.005s	coverage: 50.0% of statements
+ok  	github.com/example/app/calc	0.005s	coverage: 90.0% of statements
+?	github.com/example/app/api	[no test files]
+ok  	github.com/example/app/api	0.005s	coverage: 85.0% of statements`
+
+		measured := map[string]float64{"store": 82.5, "calc": 60}
+
+		violations, err := parseCoverageOutput(&fileIndex{}, output, "github.com/example/app", measured, coverageOptions{minCoverage: config.DefaultMinCoverage})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"calc: 60.0% coverage (minimum 80%)"}, violationStrings(violations))
 	})
 
 	t.Run("skips no test files for embed-only package", func(t *testing.T) {
@@ -2336,7 +2304,7 @@ var FS embed.FS
 
 		os.Chdir(tmpDir)
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(testFileIndex(t), output, modulePath, nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		assert.Empty(t, violations)
@@ -2370,7 +2338,7 @@ func LargeFunction() string {
 
 		os.Chdir(tmpDir)
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{minCoverage: config.DefaultMinCoverage})
+		violations, err := parseCoverageOutput(testFileIndex(t), output, modulePath, nil, coverageOptions{minCoverage: config.DefaultMinCoverage})
 
 		require.NoError(t, err)
 		assert.Empty(t, violations)
@@ -2469,7 +2437,7 @@ func Test_parseCoverageOutput_excludePackages(t *testing.T) {
 	t.Run("excludes package from coverage check", func(t *testing.T) {
 		output := "ok  \tgithub.com/example/myapp/internal/cmd\t0.005s\tcoverage: 42.0% of statements"
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{
+		violations, err := parseCoverageOutput(&fileIndex{}, output, modulePath, nil, coverageOptions{
 			minCoverage:     config.DefaultMinCoverage,
 			excludePackages: []string{"internal/cmd"},
 		})
@@ -2481,7 +2449,7 @@ func Test_parseCoverageOutput_excludePackages(t *testing.T) {
 	t.Run("excludes package from no test files check", func(t *testing.T) {
 		output := "?\tgithub.com/example/myapp/internal/cmd\t[no test files]"
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{
+		violations, err := parseCoverageOutput(&fileIndex{}, output, modulePath, nil, coverageOptions{
 			minCoverage:     config.DefaultMinCoverage,
 			excludePackages: []string{"internal/cmd"},
 		})
@@ -2494,20 +2462,20 @@ func Test_parseCoverageOutput_excludePackages(t *testing.T) {
 		output := `ok  	github.com/example/myapp/internal/cmd	0.005s	coverage: 42.0% of statements
 ok  	github.com/example/myapp/internal/service	0.003s	coverage: 50.0% of statements`
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{
+		violations, err := parseCoverageOutput(&fileIndex{}, output, modulePath, nil, coverageOptions{
 			minCoverage:     config.DefaultMinCoverage,
 			excludePackages: []string{"internal/cmd"},
 		})
 
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "internal/service")
+		assert.Contains(t, violations[0].String(), "internal/service")
 	})
 
 	t.Run("uses package override for specific package", func(t *testing.T) {
 		output := "ok  \tgithub.com/example/myapp/internal/cmd\t0.005s\tcoverage: 42.0% of statements"
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{
+		violations, err := parseCoverageOutput(&fileIndex{}, output, modulePath, nil, coverageOptions{
 			minCoverage:      config.DefaultMinCoverage,
 			packageOverrides: map[string]float64{"internal/cmd": 40.0},
 		})
@@ -2519,29 +2487,29 @@ ok  	github.com/example/myapp/internal/service	0.003s	coverage: 50.0% of stateme
 	t.Run("package override below coverage reports violation", func(t *testing.T) {
 		output := "ok  \tgithub.com/example/myapp/internal/cmd\t0.005s\tcoverage: 42.0% of statements"
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{
+		violations, err := parseCoverageOutput(&fileIndex{}, output, modulePath, nil, coverageOptions{
 			minCoverage:      config.DefaultMinCoverage,
 			packageOverrides: map[string]float64{"internal/cmd": 50.0},
 		})
 
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "42.0%")
-		assert.Contains(t, violations[0], "minimum 50%")
+		assert.Contains(t, violations[0].String(), "42.0%")
+		assert.Contains(t, violations[0].String(), "minimum 50%")
 	})
 
 	t.Run("non-overridden package uses default minimum", func(t *testing.T) {
 		output := `ok  	github.com/example/myapp/internal/cmd	0.005s	coverage: 42.0% of statements
 ok  	github.com/example/myapp/internal/service	0.003s	coverage: 50.0% of statements`
 
-		violations, err := parseCoverageOutput(output, modulePath, coverageOptions{
+		violations, err := parseCoverageOutput(&fileIndex{}, output, modulePath, nil, coverageOptions{
 			minCoverage:      config.DefaultMinCoverage,
 			packageOverrides: map[string]float64{"internal/cmd": 40.0},
 		})
 
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "internal/service")
+		assert.Contains(t, violations[0].String(), "internal/service")
 	})
 }
 
@@ -2566,7 +2534,7 @@ func Test_findUncoveredLargeFunctions_excludePackages(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, []string{"internal/cmd"})
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, []string{"internal/cmd"})
 		require.NoError(t, err)
 		assert.Empty(t, violations)
 	})
@@ -2591,10 +2559,32 @@ func Test_findUncoveredLargeFunctions_excludePackages(t *testing.T) {
 		profilePath := filepath.Join(tmpDir, "cover.out")
 		require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0644))
 
-		violations, err := findUncoveredLargeFunctions(profilePath, config.DefaultMaxUncoveredFuncLines, nil)
+		violations, err := findUncoveredLargeFunctions(testFileIndex(t), profilePath, config.DefaultMaxUncoveredFuncLines, nil)
 		require.NoError(t, err)
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "BigProcess")
+		assert.Contains(t, violations[0].String(), "BigProcess")
+	})
+
+	t.Run("skips files left out of the index", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.MkdirAll("gen", 0755))
+
+		code := fmt.Sprintf("package gen\n\n%s", generateLargeFunc("BigProcess", 30))
+		require.NoError(t, os.WriteFile(filepath.Join("gen", "service.go"), []byte(code), 0644))
+		require.NoError(t, os.WriteFile("cover.out", []byte("mode: set\n"), 0644))
+
+		idx, err := buildFileIndex(".", 1, []string{"gen"})
+		require.NoError(t, err)
+
+		violations, err := findUncoveredLargeFunctions(idx, "cover.out", config.DefaultMaxUncoveredFuncLines, nil)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
@@ -2615,10 +2605,33 @@ func Test_packageToDir(t *testing.T) {
 	})
 }
 
+// significantSource declares a function large enough to need tests.
+const significantSource = `package mypkg
+
+func BigFunction() string {
+	a := "hello"
+	b := "world"
+	c := a + b
+	d := c + "!"
+	e := d + "?"
+	f := e + "."
+	return f
+}
+`
+
+// sourceIndex indexes the given sources by path without touching the disk.
+func sourceIndex(files map[string]string) *fileIndex {
+	idx := &fileIndex{fset: token.NewFileSet()}
+
+	for path, src := range files {
+		idx.files = append(idx.files, parseSourceFile(idx.fset, path, []byte(src)))
+	}
+
+	return idx
+}
+
 func Test_packageNeedsTests(t *testing.T) {
 	t.Run("returns false for embed-only package", func(t *testing.T) {
-		tmpDir := t.TempDir()
-
 		embedFile := `package static
 
 import "embed"
@@ -2626,54 +2639,31 @@ import "embed"
 //go:embed data.html
 var FS embed.FS
 `
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "embed.go"), []byte(embedFile), 0644))
 
-		assert.False(t, packageNeedsTests(tmpDir))
+		assert.False(t, packageNeedsTests(sourceIndex(map[string]string{"static/embed.go": embedFile}), "static"))
 	})
 
 	t.Run("returns true for package with significant functions", func(t *testing.T) {
-		tmpDir := t.TempDir()
-
-		goFile := `package mypkg
-
-func BigFunction() string {
-	a := "hello"
-	b := "world"
-	c := a + b
-	d := c + "!"
-	e := d + "?"
-	f := e + "."
-	return f
-}
-`
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "mypkg.go"), []byte(goFile), 0644))
-
-		assert.True(t, packageNeedsTests(tmpDir))
+		assert.True(t, packageNeedsTests(sourceIndex(map[string]string{"mypkg/mypkg.go": significantSource}), "mypkg"))
 	})
 
 	t.Run("returns false for package with skip directive", func(t *testing.T) {
-		tmpDir := t.TempDir()
+		idx := sourceIndex(map[string]string{"mypkg/mypkg.go": fmt.Sprintf("//yake:skip-test\n%s", significantSource)})
 
-		goFile := `//yake:skip-test
-package mypkg
+		assert.False(t, packageNeedsTests(idx, "mypkg"))
+	})
 
-func BigFunction() string {
-	a := "hello"
-	b := "world"
-	c := a + b
-	d := c + "!"
-	e := d + "?"
-	f := e + "."
-	return f
-}
-`
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "mypkg.go"), []byte(goFile), 0644))
+	t.Run("ignores test files and other directories", func(t *testing.T) {
+		idx := sourceIndex(map[string]string{
+			"mypkg/mypkg_test.go": significantSource,
+			"other/other.go":      significantSource,
+		})
 
-		assert.False(t, packageNeedsTests(tmpDir))
+		assert.False(t, packageNeedsTests(idx, "mypkg"))
 	})
 
-	t.Run("returns true for non-existent directory", func(t *testing.T) {
-		assert.True(t, packageNeedsTests("/nonexistent/path"))
+	t.Run("returns false for directories without indexed sources", func(t *testing.T) {
+		assert.False(t, packageNeedsTests(&fileIndex{}, "gen"))
 	})
 }
 
@@ -2690,8 +2680,9 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.WriteFile("handler.go", []byte("package main"), 0644))
 		require.NoError(t, os.WriteFile("handler_e2e_test.go", []byte(validTestFile), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("fails with orphan test file", func(t *testing.T) {
@@ -2703,9 +2694,10 @@ func Test_checkTestFileNaming(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("orphan_test.go", []byte(validTestFile), 0644))
 
-		err := checkTestFileNaming()
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "missing source file")
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "missing source file")
 	})
 
 	t.Run("fails with test file missing testing import", func(t *testing.T) {
@@ -2718,9 +2710,10 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package main"), 0644))
 		require.NoError(t, os.WriteFile("service_test.go", []byte(testFileWithoutTestingImport), 0644))
 
-		err := checkTestFileNaming()
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "missing 'testing' package import")
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "missing 'testing' package import")
 	})
 
 	t.Run("skips vendor directory", func(t *testing.T) {
@@ -2733,9 +2726,10 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("vendor/pkg", 0755))
 		require.NoError(t, os.WriteFile("vendor/pkg/orphan_test.go", []byte("package pkg"), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
-	})
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
 
 	t.Run("skips .git directory", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -2747,8 +2741,9 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll(".git/hooks", 0755))
 		require.NoError(t, os.WriteFile(".git/hooks/orphan_test.go", []byte("package hooks"), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips test directory", func(t *testing.T) {
@@ -2761,8 +2756,9 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("test", 0755))
 		require.NoError(t, os.WriteFile("test/orphan_test.go", []byte(validTestFile), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips tests directory", func(t *testing.T) {
@@ -2775,8 +2771,9 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("tests", 0755))
 		require.NoError(t, os.WriteFile("tests/orphan_test.go", []byte(validTestFile), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips non-go files", func(t *testing.T) {
@@ -2789,8 +2786,9 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.WriteFile("readme.md", []byte("# Test"), 0644))
 		require.NoError(t, os.WriteFile("config.yaml", []byte("key: value"), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("fails with invalid naming pattern", func(t *testing.T) {
@@ -2803,9 +2801,10 @@ func Test_checkTestFileNaming(t *testing.T) {
 		require.NoError(t, os.WriteFile("service.go", []byte("package main"), 0644))
 		require.NoError(t, os.WriteFile("service_unit_test.go", []byte(validTestFile), 0644))
 
-		err := checkTestFileNaming()
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid naming pattern")
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "invalid naming pattern")
 	})
 
 	t.Run("fails when non-test file imports testing", func(t *testing.T) {
@@ -2817,9 +2816,10 @@ func Test_checkTestFileNaming(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("tests.go", []byte(validTestFile), 0644))
 
-		err := checkTestFileNaming()
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "imports 'testing' but is not named '{origin}_test.go' or '{origin}_e2e_test.go'")
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "imports 'testing' but is not named '{origin}_test.go' or '{origin}_e2e_test.go'")
 	})
 
 	t.Run("fails when source file with functions missing test file", func(t *testing.T) {
@@ -2843,10 +2843,11 @@ func AuthCache() error {
 `
 		require.NoError(t, os.WriteFile("auth_cache.go", []byte(sourceCode), 0644))
 
-		err := checkTestFileNaming()
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "missing test file")
-		assert.Contains(t, err.Error(), "auth_cache_test.go")
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "missing test file")
+		assert.Contains(t, violationsText(violations), "auth_cache_test.go")
 	})
 
 	t.Run("skips source file with only structs no functions", func(t *testing.T) {
@@ -2870,8 +2871,9 @@ type Config struct {
 `
 		require.NoError(t, os.WriteFile("models.go", []byte(sourceCode), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips source file with small functions less than 5 lines", func(t *testing.T) {
@@ -2889,8 +2891,9 @@ func main() {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(sourceCode), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips source file with skip directive", func(t *testing.T) {
@@ -2915,32 +2918,11 @@ func Process() error {
 `
 		require.NoError(t, os.WriteFile("processor.go", []byte(sourceCode), 0644))
 
-		err := checkTestFileNaming()
-		assert.NoError(t, err)
-	})
-
-}
-
-func Test_extractPackageName(t *testing.T) {
-	t.Run("extracts package name", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "service.go")
-		require.NoError(t, os.WriteFile(filePath, []byte("package service\n"), 0644))
-
-		assert.Equal(t, "service", extractPackageName(filePath))
-	})
-
-	t.Run("returns empty for invalid go file", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "bad.go")
-		require.NoError(t, os.WriteFile(filePath, []byte("not valid go {{{"), 0644))
-
-		assert.Equal(t, "", extractPackageName(filePath))
+		violations, err := checkTestFileNaming(testFileIndex(t))
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
-	t.Run("returns empty for non-existent file", func(t *testing.T) {
-		assert.Equal(t, "", extractPackageName("/non/existent/file.go"))
-	})
 }
 
 func Test_checkPackageNaming(t *testing.T) {
@@ -2954,8 +2936,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/service", 0755))
 		require.NoError(t, os.WriteFile("internal/service/handler.go", []byte("package service\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("passes with numeric characters in package name", func(t *testing.T) {
@@ -2968,8 +2951,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/api2", 0755))
 		require.NoError(t, os.WriteFile("internal/api2/handler.go", []byte("package api2\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("fails when package name too short", func(t *testing.T) {
@@ -2982,9 +2966,10 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/db", 0755))
 		require.NoError(t, os.WriteFile("internal/db/store.go", []byte("package db\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match")
 	})
 
 	t.Run("fails when package name contains uppercase", func(t *testing.T) {
@@ -2997,9 +2982,10 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/myService", 0755))
 		require.NoError(t, os.WriteFile("internal/myService/handler.go", []byte("package myService\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match")
 	})
 
 	t.Run("fails when package name contains underscore", func(t *testing.T) {
@@ -3012,9 +2998,10 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/my_service", 0755))
 		require.NoError(t, os.WriteFile("internal/my_service/handler.go", []byte("package my_service\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match")
 	})
 
 	t.Run("fails when package name contains hyphen", func(t *testing.T) {
@@ -3028,9 +3015,10 @@ func Test_checkPackageNaming(t *testing.T) {
 		// Go doesn't allow hyphens in package names, so this uses underscore in package but hyphen in dir
 		require.NoError(t, os.WriteFile("internal/myservice/handler.go", []byte("package my_service\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match")
 	})
 
 	t.Run("fails when package name too long", func(t *testing.T) {
@@ -3044,9 +3032,10 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll(filepath.Join("internal", longName), 0755))
 		require.NoError(t, os.WriteFile(filepath.Join("internal", longName, "handler.go"), fmt.Appendf(nil, "package %s\n", longName), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match")
 	})
 
 	t.Run("fails when directory name does not match package name", func(t *testing.T) {
@@ -3059,9 +3048,10 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/handler", 0755))
 		require.NoError(t, os.WriteFile("internal/handler/handler.go", []byte("package service\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match directory name 'handler'")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match directory name 'handler'")
 	})
 
 	t.Run("skips main package", func(t *testing.T) {
@@ -3073,8 +3063,9 @@ func Test_checkPackageNaming(t *testing.T) {
 
 		require.NoError(t, os.WriteFile("main.go", []byte("package main\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips test files", func(t *testing.T) {
@@ -3088,8 +3079,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.WriteFile("internal/service/handler.go", []byte("package service\n"), 0644))
 		require.NoError(t, os.WriteFile("internal/service/handler_test.go", []byte("package service_test\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips vendor directory", func(t *testing.T) {
@@ -3102,8 +3094,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("vendor/badpkg", 0755))
 		require.NoError(t, os.WriteFile("vendor/badpkg/file.go", []byte("package BADPKG\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips pb.go files", func(t *testing.T) {
@@ -3116,8 +3109,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/proto", 0755))
 		require.NoError(t, os.WriteFile("internal/proto/message.pb.go", []byte("package proto\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("passes with 3 character package name", func(t *testing.T) {
@@ -3130,8 +3124,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("internal/api", 0755))
 		require.NoError(t, os.WriteFile("internal/api/handler.go", []byte("package api\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("passes with 32 character package name", func(t *testing.T) {
@@ -3145,8 +3140,9 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll(filepath.Join("internal", name32), 0755))
 		require.NoError(t, os.WriteFile(filepath.Join("internal", name32, "handler.go"), fmt.Appendf(nil, "package %s\n", name32), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("passes when root package name matches directory name", func(t *testing.T) {
@@ -3160,8 +3156,9 @@ func Test_checkPackageNaming(t *testing.T) {
 
 		os.Chdir(pkgDir)
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("fails when root package name does not match directory name", func(t *testing.T) {
@@ -3175,9 +3172,10 @@ func Test_checkPackageNaming(t *testing.T) {
 
 		os.Chdir(pkgDir)
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "does not match directory name 'wrongname'")
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "does not match directory name 'wrongname'")
 	})
 
 	t.Run("skips examples directory", func(t *testing.T) {
@@ -3190,37 +3188,71 @@ func Test_checkPackageNaming(t *testing.T) {
 		require.NoError(t, os.MkdirAll("examples/demo", 0755))
 		require.NoError(t, os.WriteFile("examples/demo/main.go", []byte("package AB\n"), 0644))
 
-		err := checkPackageNaming(config.DefaultPackageNamingPattern)
-		assert.NoError(t, err)
+		violations, err := checkPackageNaming(testFileIndex(t), config.DefaultPackageNamingPattern)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
 func TestRunGolangChecks(t *testing.T) {
-	t.Run("passes with valid go project", func(t *testing.T) {
+	t.Run("fails with low coverage", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		originalDir, _ := os.Getwd()
 		defer os.Chdir(originalDir)
 
 		os.Chdir(tmpDir)
 
-		createTestGoProject(t, tmpDir, 100)
+		createTestGoProject(t, tmpDir, 50)
 
-		err := RunGolangChecks()
-		assert.NoError(t, err)
+		violations, err := RunGolangChecks(t.Context(), RunOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+
+		for _, v := range violations {
+			assert.Equal(t, RuleCoverage, v.Rule)
+			assert.Equal(t, SeverityError, v.Severity)
+		}
+
+		assert.Contains(t, ViolationsError(violations).Error(), "coverage violations")
 	})
 
-	t.Run("fails with low coverage", func(t *testing.T) {
+	t.Run("passes with valid go project and reuses the provided test run", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		originalDir, _ := os.Getwd()
 		defer os.Chdir(originalDir)
 
 		os.Chdir(tmpDir)
 
+		createTestGoProject(t, tmpDir, 100)
+
+		tests := testRun(t)
+
+		violations, err := RunGolangChecks(t.Context(), RunOptions{Tests: tests})
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+
+		// Dropping coverage after the run proves the checks do not rerun it.
 		createTestGoProject(t, tmpDir, 50)
 
-		err := RunGolangChecks()
+		violations, err = RunGolangChecks(t.Context(), RunOptions{Tests: tests})
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("reports test checks when the run cannot start", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		createTestGoProject(t, tmpDir, 100)
+		t.Setenv("PATH", t.TempDir())
+
+		_, err := RunGolangChecks(t.Context(), RunOptions{})
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "coverage violations")
+		assert.Contains(t, err.Error(), RuleTestDuration)
+		assert.Contains(t, err.Error(), RuleCoverage)
 	})
 }
 
@@ -3234,8 +3266,9 @@ func Test_checkCoverage(t *testing.T) {
 
 		createTestGoProject(t, tmpDir, 100)
 
-		err := checkCoverage(coverageOptions{minCoverage: config.DefaultMinCoverage, maxUncoveredFuncLines: config.DefaultMaxUncoveredFuncLines})
-		assert.NoError(t, err)
+		violations, err := checkCoverage(t.Context(), testFileIndex(t), testRun(t), coverageOptions{minCoverage: config.DefaultMinCoverage, maxUncoveredFuncLines: config.DefaultMaxUncoveredFuncLines})
+		require.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("fails with low coverage", func(t *testing.T) {
@@ -3247,9 +3280,10 @@ func Test_checkCoverage(t *testing.T) {
 
 		createTestGoProject(t, tmpDir, 50)
 
-		err := checkCoverage(coverageOptions{minCoverage: config.DefaultMinCoverage, maxUncoveredFuncLines: config.DefaultMaxUncoveredFuncLines})
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "coverage violations")
+		violations, err := checkCoverage(t.Context(), testFileIndex(t), testRun(t), coverageOptions{minCoverage: config.DefaultMinCoverage, maxUncoveredFuncLines: config.DefaultMaxUncoveredFuncLines})
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "coverage (minimum 80%)")
 	})
 
 	t.Run("detects large uncovered function", func(t *testing.T) {
@@ -3261,16 +3295,89 @@ func Test_checkCoverage(t *testing.T) {
 
 		createTestGoProjectWithLargeFunc(t, tmpDir)
 
-		err := checkCoverage(coverageOptions{minCoverage: config.DefaultMinCoverage, maxUncoveredFuncLines: config.DefaultMaxUncoveredFuncLines})
+		violations, err := checkCoverage(t.Context(), testFileIndex(t), testRun(t), coverageOptions{minCoverage: config.DefaultMinCoverage, maxUncoveredFuncLines: config.DefaultMaxUncoveredFuncLines})
+		require.NoError(t, err)
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "no test coverage")
+		assert.Contains(t, violationsText(violations), "BigUntested")
+	})
+
+	t.Run("returns error when tests failed", func(t *testing.T) {
+		tests := &testrun.Result{Passes: []*testrun.Pass{{Err: errors.New("exit status 1")}}}
+
+		_, err := checkCoverage(t.Context(), &fileIndex{}, tests, coverageOptions{minCoverage: config.DefaultMinCoverage})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to run coverage check")
+	})
+}
+
+// testRun runs the tests of the current directory once and removes the
+// coverage profiles when the test ends.
+func testRun(t *testing.T) *testrun.Result {
+	t.Helper()
+
+	result, err := testrun.Run(t.Context(), testrun.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { result.Close() })
+
+	return result
+}
+
+func Test_checkTestDuration(t *testing.T) {
+	t.Run("reports slow packages from the shared run", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+
+		tests := &testrun.Result{Passes: []*testrun.Pass{{
+			Events: []testrun.Event{{Action: "pass", Package: "testproject/slow", Elapsed: 3}},
+		}}}
+
+		violations, err := checkTestDuration(&fileIndex{}, tests, testDurationOptions{limits: durationLimits{maxDuration: time.Second}})
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "slow", violations[0].File)
+	})
+
+	t.Run("returns error when tests failed without slow packages", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+
+		tests := &testrun.Result{Passes: []*testrun.Pass{{Err: errors.New("exit status 1")}}}
+
+		_, err := checkTestDuration(&fileIndex{}, tests, testDurationOptions{limits: durationLimits{maxDuration: time.Second}})
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "no test coverage")
-		assert.Contains(t, err.Error(), "BigUntested")
+		assert.Contains(t, err.Error(), "failed to run test duration check")
+	})
+
+	t.Run("returns error without go.mod", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		_, err := checkTestDuration(&fileIndex{}, &testrun.Result{Passes: []*testrun.Pass{{}}}, testDurationOptions{limits: durationLimits{maxDuration: time.Second}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read go.mod")
 	})
 }
 
 func createTestGoProject(t *testing.T, dir string, coveragePercent int) {
 	t.Helper()
 
+	// Nested test runs use -race; skip its one second delay at exit.
+	t.Setenv("GORACE", "atexit_sleep_ms=0")
+
 	goMod := `module testproject
 
 go 1.21
@@ -3341,6 +3448,9 @@ func TestAdd(t *testing.T) {
 func createTestGoProjectWithLargeFunc(t *testing.T, dir string) {
 	t.Helper()
 
+	// Nested test runs use -race; skip its one second delay at exit.
+	t.Setenv("GORACE", "atexit_sleep_ms=0")
+
 	goMod := `module testproject
 
 go 1.21
@@ -3402,9 +3512,10 @@ func doWork(a, b, c int, d string, e bool) (int, error) {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects too many parameters", func(t *testing.T) {
@@ -3422,10 +3533,11 @@ func doWork(a int, b int, c int, d string, e bool, f float64) int {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "function 'doWork' has 6 parameters (maximum 5)")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "function 'doWork' has 6 parameters (maximum 5)")
 	})
 
 	t.Run("detects too many return values", func(t *testing.T) {
@@ -3443,10 +3555,11 @@ func doWork() (int, string, bool, error, float64, byte) {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "function 'doWork' has 6 return values (maximum 5)")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "function 'doWork' has 6 return values (maximum 5)")
 	})
 
 	t.Run("detects both violations", func(t *testing.T) {
@@ -3464,11 +3577,12 @@ func doWork(a, b, c, d, e, f int) (int, string, bool, error, float64, byte) {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "has 6 parameters")
-		assert.Contains(t, err.Error(), "has 6 return values")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "has 6 parameters")
+		assert.Contains(t, violationsText(violations), "has 6 return values")
 	})
 
 	t.Run("skips test files", func(t *testing.T) {
@@ -3486,9 +3600,10 @@ func doWork(a, b, c, d, e, f int) int {
 `
 		require.NoError(t, os.WriteFile("main_test.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips with skip directive", func(t *testing.T) {
@@ -3507,9 +3622,10 @@ func doWork(a, b, c, d, e, f int) int {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips with func skip directive", func(t *testing.T) {
@@ -3528,9 +3644,10 @@ func doWork(a, b, c, d, e, f int) int {
 `
 		require.NoError(t, os.WriteFile("main.go", []byte(code), 0644))
 
-		err := checkFuncSignature(config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations, err := checkFuncSignature(testFileIndex(t), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
@@ -3547,7 +3664,7 @@ func doWork(a int) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findFuncSignatureViolations(path, config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations := findFuncSignatureViolations(testSourceFile(t, path), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
 
 		assert.Empty(t, violations)
 	})
@@ -3564,10 +3681,10 @@ func doWork(a, b, c, d, e, f int) error {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findFuncSignatureViolations(path, config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations := findFuncSignatureViolations(testSourceFile(t, path), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "has 6 parameters")
+		assert.Contains(t, violations[0].String(), "has 6 parameters")
 	})
 
 	t.Run("unnamed return values counted", func(t *testing.T) {
@@ -3582,10 +3699,10 @@ func doWork() (int, string, bool, error, float64, byte) {
 `
 		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
 
-		violations := findFuncSignatureViolations(path, config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations := findFuncSignatureViolations(testSourceFile(t, path), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
 
 		require.Len(t, violations, 1)
-		assert.Contains(t, violations[0], "has 6 return values")
+		assert.Contains(t, violations[0].String(), "has 6 return values")
 	})
 
 	t.Run("invalid go file", func(t *testing.T) {
@@ -3594,7 +3711,7 @@ func doWork() (int, string, bool, error, float64, byte) {
 
 		require.NoError(t, os.WriteFile(path, []byte("not go code"), 0644))
 
-		violations := findFuncSignatureViolations(path, config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		violations := findFuncSignatureViolations(testSourceFile(t, path), config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
 
 		assert.Empty(t, violations)
 	})
@@ -3626,9 +3743,10 @@ func foo() cfg {
 `
 		require.NoError(t, os.WriteFile("test.go", []byte(content), 0644))
 
-		err := checkCompositeLiteral(config.DefaultMaxSingleLineFields)
+		violations, err := checkCompositeLiteral(testFileIndex(t), config.DefaultMaxSingleLineFields)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects multiple fields on same line", func(t *testing.T) {
@@ -3655,10 +3773,11 @@ func foo() cfg {
 `
 		require.NoError(t, os.WriteFile("test.go", []byte(content), 0644))
 
-		err := checkCompositeLiteral(config.DefaultMaxSingleLineFields)
+		violations, err := checkCompositeLiteral(testFileIndex(t), config.DefaultMaxSingleLineFields)
+		require.NoError(t, err)
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "2 fields on the same line")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "2 fields on the same line")
 	})
 
 	t.Run("allows single field inline", func(t *testing.T) {
@@ -3680,9 +3799,10 @@ func foo() cfg {
 `
 		require.NoError(t, os.WriteFile("test.go", []byte(content), 0644))
 
-		err := checkCompositeLiteral(config.DefaultMaxSingleLineFields)
+		violations, err := checkCompositeLiteral(testFileIndex(t), config.DefaultMaxSingleLineFields)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows positional elements", func(t *testing.T) {
@@ -3700,9 +3820,10 @@ func foo() []int {
 `
 		require.NoError(t, os.WriteFile("test.go", []byte(content), 0644))
 
-		err := checkCompositeLiteral(config.DefaultMaxSingleLineFields)
+		violations, err := checkCompositeLiteral(testFileIndex(t), config.DefaultMaxSingleLineFields)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("skips file with skip directive", func(t *testing.T) {
@@ -3726,9 +3847,10 @@ func foo() cfg {
 `
 		require.NoError(t, os.WriteFile("test.go", []byte(content), 0644))
 
-		err := checkCompositeLiteral(config.DefaultMaxSingleLineFields)
+		violations, err := checkCompositeLiteral(testFileIndex(t), config.DefaultMaxSingleLineFields)
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
@@ -3751,7 +3873,7 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, config.DefaultMaxSingleLineFields)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), config.DefaultMaxSingleLineFields)
 
 		assert.Empty(t, violations)
 	})
@@ -3777,10 +3899,10 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, config.DefaultMaxSingleLineFields)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), config.DefaultMaxSingleLineFields)
 
 		assert.NotEmpty(t, violations)
-		assert.Contains(t, violations[0], "2 fields on the same line")
+		assert.Contains(t, violations[0].String(), "2 fields on the same line")
 	})
 
 	t.Run("no violations for proper formatting", func(t *testing.T) {
@@ -3803,7 +3925,7 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, config.DefaultMaxSingleLineFields)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), config.DefaultMaxSingleLineFields)
 
 		assert.Empty(t, violations)
 	})
@@ -3828,7 +3950,7 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, 5)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), 5)
 
 		assert.Empty(t, violations)
 	})
@@ -3854,10 +3976,10 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, 5)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), 5)
 
 		assert.NotEmpty(t, violations)
-		assert.Contains(t, violations[0], "6 fields on the same line")
+		assert.Contains(t, violations[0].String(), "6 fields on the same line")
 	})
 
 	t.Run("custom max allows fewer fields", func(t *testing.T) {
@@ -3878,10 +4000,10 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, 2)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), 2)
 
 		assert.NotEmpty(t, violations)
-		assert.Contains(t, violations[0], "3 fields on the same line")
+		assert.Contains(t, violations[0].String(), "3 fields on the same line")
 	})
 
 	t.Run("custom max allows exact count", func(t *testing.T) {
@@ -3901,16 +4023,10 @@ func foo() cfg {
 		path := filepath.Join(tmpDir, "test.go")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-		violations := findCompositeLiteralViolations(path, 2)
+		violations := findCompositeLiteralViolations(testSourceFile(t, path), 2)
 
 		assert.Empty(t, violations)
 	})
-
-	t.Run("nonexistent file returns nil", func(t *testing.T) {
-		violations := findCompositeLiteralViolations("/non/existent/file.go", config.DefaultMaxSingleLineFields)
-
-		assert.Nil(t, violations)
-	})
 }
 
 func Test_countFields(t *testing.T) {
@@ -3979,10 +4095,11 @@ func Test_checkStuttering(t *testing.T) {
 func CustomerOrders() {}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "customer.CustomerOrders")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "customer.CustomerOrders")
 	})
 
 	t.Run("detects stuttering type", func(t *testing.T) {
@@ -3999,10 +4116,11 @@ func CustomerOrders() {}
 type CustomerAddress struct{}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "customer.CustomerAddress")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "customer.CustomerAddress")
 	})
 
 	t.Run("detects stuttering var", func(t *testing.T) {
@@ -4019,10 +4137,11 @@ type CustomerAddress struct{}
 var CustomerDefault = "none"
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "customer.CustomerDefault")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "customer.CustomerDefault")
 	})
 
 	t.Run("allows type matching package name", func(t *testing.T) {
@@ -4039,9 +4158,10 @@ var CustomerDefault = "none"
 type Customer struct{}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows non-stuttering exported names", func(t *testing.T) {
@@ -4062,9 +4182,10 @@ type Address struct{}
 func Orders() {}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores unexported identifiers", func(t *testing.T) {
@@ -4081,9 +4202,10 @@ func Orders() {}
 func customerOrders() {}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores methods", func(t *testing.T) {
@@ -4102,9 +4224,10 @@ type Order struct{}
 func (o *Order) CustomerName() string { return "" }
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects file skip directive", func(t *testing.T) {
@@ -4122,9 +4245,10 @@ package customer
 func CustomerOrders() {}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects function skip directive", func(t *testing.T) {
@@ -4142,9 +4266,10 @@ func CustomerOrders() {}
 func CustomerOrders() {}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects type skip directive", func(t *testing.T) {
@@ -4162,9 +4287,10 @@ func CustomerOrders() {}
 type CustomerAddress struct{}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores main package", func(t *testing.T) {
@@ -4180,9 +4306,10 @@ type CustomerAddress struct{}
 func main() {}
 `), 0644))
 
-		err := checkStuttering()
+		violations, err := checkStuttering(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 }
 
@@ -4230,11 +4357,12 @@ func (u *User) GetName() string {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "GetName")
-		assert.Contains(t, err.Error(), "Name")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "GetName")
+		assert.Contains(t, violationsText(violations), "Name")
 	})
 
 	t.Run("allows getter with parameters", func(t *testing.T) {
@@ -4255,9 +4383,10 @@ func (u *User) GetField(name string) string {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows getter with multiple return values", func(t *testing.T) {
@@ -4278,9 +4407,10 @@ func (u *User) GetName() (string, error) {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores non-method functions", func(t *testing.T) {
@@ -4299,9 +4429,10 @@ func GetDefault() string {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores unexported methods", func(t *testing.T) {
@@ -4322,9 +4453,10 @@ func (u *User) getName() string {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects skip directive", func(t *testing.T) {
@@ -4346,9 +4478,10 @@ func (u *User) GetName() string {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects function skip directive", func(t *testing.T) {
@@ -4370,9 +4503,33 @@ func (u *User) GetName() string {
 }
 `), 0644))
 
-		err := checkGetterNaming()
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+	t.Run("allows plugin interface getters without type information", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.MkdirAll("internal/plugin", 0755))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/test\n"), 0644))
+		require.NoError(t, os.WriteFile("internal/plugin/plugin.go", []byte(`package plugin
+
+type Plugin struct{}
+
+func (p *Plugin) GetLoadMode() string {
+	return "syntax"
+}
+`), 0644))
+
+		violations, err := checkGetterNaming(testFileIndex(t))
+		require.NoError(t, err)
+
+		assert.Empty(t, violations)
 	})
 }
 
@@ -4382,7 +4539,7 @@ func Test_findStutteringViolations(t *testing.T) {
 		path := filepath.Join(tmpDir, "bad.go")
 		require.NoError(t, os.WriteFile(path, []byte("not valid go"), 0644))
 
-		violations := findStutteringViolations(path)
+		violations := findStutteringViolations(testSourceFile(t, path))
 
 		assert.Nil(t, violations)
 	})
@@ -4394,7 +4551,7 @@ func Test_findGetterViolations(t *testing.T) {
 		path := filepath.Join(tmpDir, "bad.go")
 		require.NoError(t, os.WriteFile(path, []byte("not valid go"), 0644))
 
-		violations := findGetterViolations(path)
+		violations := findGetterViolations(testSourceFile(t, path))
 
 		assert.Nil(t, violations)
 	})
@@ -4423,11 +4580,12 @@ func (c *codec) WritePacket(w io.Writer, packet Packet, maxSize uint32) (int, er
 }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "WritePacket")
-		assert.Contains(t, err.Error(), "codec")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "WritePacket")
+		assert.Contains(t, violationsText(violations), "codec")
 	})
 
 	t.Run("allows unexported methods on private struct", func(t *testing.T) {
@@ -4448,9 +4606,10 @@ func (s *service) run() error {
 }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows exported methods on public struct", func(t *testing.T) {
@@ -4471,9 +4630,10 @@ func (s *Service) Run() error {
 }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows String method on private struct", func(t *testing.T) {
@@ -4496,9 +4656,10 @@ func (s *status) String() string {
 }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows Error method on private struct", func(t *testing.T) {
@@ -4521,9 +4682,10 @@ func (e *myError) Error() string {
 }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows MarshalJSON on private struct", func(t *testing.T) {
@@ -4544,9 +4706,10 @@ func (d *data) MarshalJSON() ([]byte, error) {
 }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("allows ServeHTTP on private struct", func(t *testing.T) {
@@ -4567,9 +4730,10 @@ type handler struct{}
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("detects multiple violations in same file", func(t *testing.T) {
@@ -4590,12 +4754,13 @@ func (w *worker) Execute() error { return nil }
 func (w *worker) String() string { return "worker" }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "Process")
-		assert.Contains(t, err.Error(), "Execute")
-		assert.NotContains(t, err.Error(), "String")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "Process")
+		assert.Contains(t, violationsText(violations), "Execute")
+		assert.NotContains(t, violationsText(violations), "String")
 	})
 
 	t.Run("respects skip directive on file", func(t *testing.T) {
@@ -4615,9 +4780,10 @@ type worker struct{}
 func (w *worker) Start() error { return nil }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects skip directive on function", func(t *testing.T) {
@@ -4637,9 +4803,10 @@ type worker struct{}
 func (w *worker) Start() error { return nil }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("handles value receiver", func(t *testing.T) {
@@ -4658,10 +4825,11 @@ type worker struct{}
 func (w worker) Process() error { return nil }
 `), 0644))
 
-		err := checkPrivateExportedMethods()
+		violations, err := checkPrivateExportedMethods(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "Process")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "Process")
 	})
 }
 
@@ -4671,7 +4839,7 @@ func Test_findPrivateExportedMethodViolations(t *testing.T) {
 		path := filepath.Join(tmpDir, "bad.go")
 		require.NoError(t, os.WriteFile(path, []byte("not valid go"), 0644))
 
-		violations := findPrivateExportedMethodViolations(path)
+		violations := findPrivateExportedMethodViolations(testSourceFile(t, path))
 
 		assert.Nil(t, violations)
 	})
@@ -4753,11 +4921,12 @@ func init() {
 }
 `), 0644))
 
-		err := checkNoInit()
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "init() function is forbidden")
-		assert.Contains(t, err.Error(), "internal/cfg/cfg.go")
+		require.NotEmpty(t, violations)
+		assert.Contains(t, violationsText(violations), "init() function is forbidden")
+		assert.Contains(t, violationsText(violations), "internal/cfg/cfg.go")
 	})
 
 	t.Run("allows files without init", func(t *testing.T) {
@@ -4776,9 +4945,10 @@ func Load() error {
 }
 `), 0644))
 
-		err := checkNoInit()
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores test files", func(t *testing.T) {
@@ -4797,9 +4967,10 @@ func init() {
 }
 `), 0644))
 
-		err := checkNoInit()
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("ignores method named init", func(t *testing.T) {
@@ -4818,9 +4989,10 @@ type srv struct{}
 func (s *srv) init() {}
 `), 0644))
 
-		err := checkNoInit()
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects file skip directive", func(t *testing.T) {
@@ -4838,9 +5010,10 @@ package cfg
 func init() {}
 `), 0644))
 
-		err := checkNoInit()
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
+		assert.Empty(t, violations)
 	})
 
 	t.Run("respects per-function skip directive", func(t *testing.T) {
@@ -4858,9 +5031,44 @@ func init() {}
 func init() {}
 `), 0644))
 
-		err := checkNoInit()
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
+
+		assert.Empty(t, violations)
+	})
+
+	t.Run("allows init that only registers", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.MkdirAll("internal/plugin", 0755))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/test\n"), 0644))
+		require.NoError(t, os.WriteFile("internal/plugin/plugin.go", []byte(`package plugin
+
+import "github.com/golangci/plugin-module-register/register"
+
+func init() {
+	register.Plugin("example", New)
+}
+`), 0644))
+		require.NoError(t, os.WriteFile("internal/plugin/setup.go", []byte(`package plugin
+
+import "github.com/golangci/plugin-module-register/register"
 
-		assert.NoError(t, err)
+func init() {
+	register.Plugin("example", New)
+	load()
+}
+`), 0644))
+
+		violations, err := checkNoInit(testFileIndex(t))
+		require.NoError(t, err)
+
+		require.Len(t, violations, 1)
+		assert.Equal(t, "internal/plugin/setup.go", violations[0].File)
 	})
 }
 func Test_enabled(t *testing.T) {
@@ -4885,6 +5093,63 @@ func Test_enabled(t *testing.T) {
 		assert.False(t, enabled(&config.FuncSignaturePolicy{Enabled: boolPtr(false)}))
 		assert.False(t, enabled(&config.TestDurationPolicy{Enabled: boolPtr(false)}))
 		assert.False(t, enabled(&config.CoveragePolicy{Enabled: boolPtr(false)}))
+		assert.False(t, enabled(&config.SuppressionsPolicy{Enabled: boolPtr(false)}))
+	})
+}
+
+func Test_optedIn(t *testing.T) {
+	t.Run("nil section defaults to disabled", func(t *testing.T) {
+		assert.False(t, optedIn(nil))
+		assert.False(t, optedIn((*config.PolicyToggle)(nil)))
+		assert.False(t, optedIn((*config.ComplexityPolicy)(nil)))
+	})
+
+	t.Run("configured section defaults to enabled", func(t *testing.T) {
+		assert.True(t, optedIn(&config.PolicyToggle{}))
+		assert.True(t, optedIn(&config.TestQualityPolicy{RequireParallel: boolPtr(true)}))
+		assert.False(t, optedIn(&config.TerminationPolicy{Enabled: boolPtr(false)}))
+	})
+}
+
+func TestGolangPolicyCheck_active(t *testing.T) {
+	activeRules := func(cfg *config.Config) []string {
+		var rules []string
+
+		for _, check := range golangPolicyChecks(cfg) {
+			if check.active() {
+				rules = append(rules, check.rule)
+			}
+		}
+
+		return rules
+	}
+
+	optInRules := []string{RuleComplexity, RuleErrorHandling, RuleContext, RuleTermination, RuleConcurrency, RuleTestQuality}
+
+	t.Run("leaves opt-in policies off without their section", func(t *testing.T) {
+		rules := activeRules(&config.Config{})
+
+		assert.Contains(t, rules, RuleStuttering)
+		assert.Contains(t, rules, RuleCoverage)
+
+		for _, rule := range optInRules {
+			assert.NotContains(t, rules, rule)
+		}
+	})
+
+	t.Run("runs opt-in policies once configured", func(t *testing.T) {
+		rules := activeRules(&config.Config{Policy: config.PolicyConfig{
+			Complexity:    &config.ComplexityPolicy{},
+			ErrorHandling: &config.PolicyToggle{},
+			Context:       &config.PolicyToggle{Enabled: boolPtr(true)},
+			Termination:   &config.TerminationPolicy{},
+			Concurrency:   &config.PolicyToggle{},
+			TestQuality:   &config.TestQualityPolicy{},
+		}})
+
+		for _, rule := range optInRules {
+			assert.Contains(t, rules, rule)
+		}
 	})
 }
 
@@ -4937,3 +5202,15 @@ func Test_resolveCoverage(t *testing.T) {
 	assert.Equal(t, []string{"internal/cmd"}, resolveExcludePackages(p))
 	assert.Equal(t, map[string]float64{"internal/cmd": 40.0}, resolvePackageOverrides(p))
 }
+
+func Test_resolveRequireReason(t *testing.T) {
+	assert.False(t, resolveRequireReason(nil))
+	assert.False(t, resolveRequireReason(&config.SuppressionsPolicy{}))
+	assert.True(t, resolveRequireReason(&config.SuppressionsPolicy{RequireReason: boolPtr(true)}))
+}
+
+func Test_resolveTypeCheck(t *testing.T) {
+	assert.False(t, resolveTypeCheck(config.PolicyConfig{}))
+	assert.False(t, resolveTypeCheck(config.PolicyConfig{TypeCheck: boolPtr(false)}))
+	assert.True(t, resolveTypeCheck(config.PolicyConfig{TypeCheck: boolPtr(true)}))
+}
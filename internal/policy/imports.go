@@ -0,0 +1,187 @@
+package policy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+// importedPackage names a package both by its import path and, for packages
+// of the module, by its directory relative to the module root.
+type importedPackage struct {
+	path string
+	// dir is empty for packages outside the module.
+	dir string
+}
+
+func (p importedPackage) matches(pattern string) bool {
+	if p.dir != "" && matchPath(pattern, p.dir) {
+		return true
+	}
+
+	return matchImport(pattern, p.path)
+}
+
+func (p importedPackage) matchesAny(patterns []string) bool {
+	for _, pattern := range patterns {
+		if p.matches(pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkImports(idx *fileIndex, p *config.ImportsPolicy) ([]Violation, error) {
+	log.Println("Checking import dependencies...")
+
+	if p == nil || len(p.Layers)+len(p.Rules)+len(p.Forbidden) == 0 {
+		return nil, nil
+	}
+
+	goModData, err := os.ReadFile("go.mod")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	modulePath := parseModulePath(string(goModData))
+
+	violations := idx.collect(idx.sources(), func(file *sourceFile) []Violation {
+		return findImportViolations(file, modulePath, p)
+	})
+
+	return violations, nil
+}
+
+// findImportViolations reports each import of the file that is forbidden,
+// denied by a rule or reaches into a higher layer. An import is reported once,
+// for the first of those it breaks.
+func findImportViolations(file *sourceFile, modulePath string, p *config.ImportsPolicy) []Violation {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip {
+		return nil
+	}
+
+	dir := filepath.ToSlash(filepath.Dir(file.path))
+	importer := modulePackage(modulePath, dir)
+
+	var violations []Violation
+
+	for _, spec := range node.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		message, suggestion := importViolation(p, importer, resolveImport(modulePath, importPath))
+		if message == "" {
+			continue
+		}
+
+		pos := file.fset.Position(spec.Path.Pos())
+		violations = append(violations, Violation{
+			Rule:       RuleImports,
+			File:       file.path,
+			Line:       pos.Line,
+			Column:     pos.Column,
+			Severity:   SeverityError,
+			Message:    message,
+			Suggestion: suggestion,
+		})
+	}
+
+	return violations
+}
+
+// importViolation returns the message and suggestion for an import that
+// breaks the policy, or empty strings when it is allowed.
+func importViolation(p *config.ImportsPolicy, importer, imported importedPackage) (string, string) {
+	for _, pattern := range p.Forbidden {
+		if imported.matches(pattern) {
+			return fmt.Sprintf("import of forbidden package '%s'", imported.path), ""
+		}
+	}
+
+	for _, rule := range p.Rules {
+		if !importer.matches(rule.From) {
+			continue
+		}
+
+		if imported.matchesAny(rule.Deny) {
+			return fmt.Sprintf("package '%s' must not import '%s'", importer.dir, imported.path), rule.Reason
+		}
+
+		if len(rule.Allow) > 0 && imported.dir != "" && imported.dir != importer.dir && !imported.matchesAny(rule.Allow) {
+			return fmt.Sprintf("package '%s' may not import '%s' (not in the allowed imports)", importer.dir, imported.path), rule.Reason
+		}
+	}
+
+	from, to := importLayer(p.Layers, importer), importLayer(p.Layers, imported)
+	if from >= 0 && to >= 0 && to < from {
+		return fmt.Sprintf("package '%s' of layer '%s' must not import '%s' of the higher layer '%s'", importer.dir, p.Layers[from].Name, imported.path, p.Layers[to].Name), ""
+	}
+
+	return "", ""
+}
+
+// importLayer returns the index of the first layer holding pkg, or -1.
+func importLayer(layers []config.ImportLayer, pkg importedPackage) int {
+	for i, layer := range layers {
+		if pkg.matchesAny(layer.Packages) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func modulePackage(modulePath, dir string) importedPackage {
+	if dir == "." {
+		return importedPackage{
+			path: modulePath,
+			dir:  dir,
+		}
+	}
+
+	return importedPackage{
+		path: fmt.Sprintf("%s/%s", modulePath, dir),
+		dir:  dir,
+	}
+}
+
+func resolveImport(modulePath, importPath string) importedPackage {
+	switch {
+	case modulePath == "":
+		return importedPackage{path: importPath}
+	case importPath == modulePath:
+		return modulePackage(modulePath, ".")
+	case strings.HasPrefix(importPath, fmt.Sprintf("%s/", modulePath)):
+		return modulePackage(modulePath, strings.TrimPrefix(importPath, fmt.Sprintf("%s/", modulePath)))
+	default:
+		return importedPackage{path: importPath}
+	}
+}
+
+// matchImport reports whether pattern matches importPath or one of its parent
+// paths, segment by segment from the start.
+func matchImport(pattern, importPath string) bool {
+	patternParts := strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+	parts := strings.Split(importPath, "/")
+
+	for i := len(parts); i > 0; i-- {
+		if matchSegments(patternParts, parts[:i]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+)
+
+func Test_checkImports(t *testing.T) {
+	writeFixProject(t, map[string]string{
+		"internal/domain/user.go": `package domain
+
+import (
+	"fmt"
+
+	"example.com/fx/internal/transport/http"
+	"github.com/pkg/errors"
+)
+`,
+		"internal/domain/user_test.go":      "package domain\n\nimport _ \"example.com/fx/internal/transport/http\"\n",
+		"internal/domain/order/order.go":    "package order\n\nimport _ \"example.com/fx/internal/store\"\n",
+		"internal/store/store.go":           "package store\n\nimport _ \"example.com/fx/internal/domain\"\n",
+		"internal/tools/tools.go":           "package tools\n\nimport _ \"example.com/fx/internal/core\"\n",
+		"internal/core/core.go":             "package core\n\nimport _ \"example.com/fx/internal/tools\"\n",
+		"internal/transport/http/server.go": "package http\n\nimport _ \"example.com/fx/internal/domain\"\n",
+		"internal/legacy/legacy.go":         "//yake:skip-test\n\npackage legacy\n\nimport _ \"github.com/pkg/errors/v2\"\n",
+	})
+
+	policy := &config.ImportsPolicy{
+		Layers: []config.ImportLayer{
+			{
+				Name:     "core",
+				Packages: []string{"internal/core"},
+			},
+			{
+				Name:     "tools",
+				Packages: []string{"internal/tools"},
+			},
+		},
+		Rules: []config.ImportRule{
+			{
+				From:   "internal/domain/**",
+				Deny:   []string{"internal/transport/**"},
+				Reason: "the domain must not depend on transports",
+			},
+			{
+				From:  "internal/domain/order",
+				Allow: []string{"internal/domain"},
+			},
+		},
+		Forbidden: []string{"github.com/pkg/errors"},
+	}
+
+	violations, err := checkImports(testFileIndex(t), policy)
+	require.NoError(t, err)
+
+	t.Run("reports forbidden, denied and upward imports", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"internal/domain/order/order.go:3:10: package 'internal/domain/order' may not import 'example.com/fx/internal/store' (not in the allowed imports)",
+			"internal/domain/user.go:6:2: package 'internal/domain' must not import 'example.com/fx/internal/transport/http'",
+			"internal/domain/user.go:7:2: import of forbidden package 'github.com/pkg/errors'",
+			"internal/tools/tools.go:3:10: package 'internal/tools' of layer 'tools' must not import 'example.com/fx/internal/core' of the higher layer 'core'",
+		}, violationStrings(violations))
+	})
+
+	t.Run("carries the rule reason as suggestion", func(t *testing.T) {
+		require.Len(t, violations, 4)
+		assert.Equal(t, "the domain must not depend on transports", violations[1].Suggestion)
+		assert.Equal(t, RuleImports, violations[1].Rule)
+	})
+
+	t.Run("skips the check without layers, rules or forbidden packages", func(t *testing.T) {
+		violations, err := checkImports(testFileIndex(t), &config.ImportsPolicy{})
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("returns error without go.mod", func(t *testing.T) {
+		require.NoError(t, os.Remove("go.mod"))
+
+		_, err := checkImports(testFileIndex(t), policy)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read go.mod")
+	})
+}
+
+func Test_resolveImport(t *testing.T) {
+	assert.Equal(t, importedPackage{path: "example.com/fx", dir: "."}, resolveImport("example.com/fx", "example.com/fx"))
+	assert.Equal(t, importedPackage{path: "example.com/fx/a/b", dir: "a/b"}, resolveImport("example.com/fx", "example.com/fx/a/b"))
+	assert.Equal(t, importedPackage{path: "example.com/fxy"}, resolveImport("example.com/fx", "example.com/fxy"))
+	assert.Equal(t, importedPackage{path: "fmt"}, resolveImport("", "fmt"))
+}
+
+func Test_matchImport(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"github.com/pkg/errors", "github.com/pkg/errors", true},
+		{"github.com/pkg/errors", "github.com/pkg/errors/sub", true},
+		{"github.com/pkg/errors/", "github.com/pkg/errors", true},
+		{"github.com/pkg/errors", "github.com/pkg/errorsx", false},
+		{"github.com/*/errors", "github.com/pkg/errors", true},
+		{"**/internal/**", "example.com/x/internal/a", true},
+		{"errors", "github.com/pkg/errors", false},
+		{"unsafe", "unsafe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchImport(tt.pattern, tt.path), tt.path)
+		})
+	}
+}
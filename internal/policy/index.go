@@ -0,0 +1,244 @@
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+// skippedDirs are never descended into when indexing the project.
+var skippedDirs = map[string]bool{
+	"vendor":   true,
+	".git":     true,
+	"test":     true,
+	"tests":    true,
+	"examples": true,
+}
+
+// sourceFile is a Go file read and parsed once per policy run. Every AST-based
+// rule inspects the same sourceFile instead of reopening the file itself.
+type sourceFile struct {
+	path     string
+	fset     *token.FileSet
+	node     *ast.File
+	parseErr error
+	src      []byte
+	skip     bool
+	// suppressions are the //yake:ignore directives in the file.
+	suppressions []*suppression
+	// typed is the type information of the file's package, or nil when the
+	// run is syntax-only or the package did not type-check.
+	typed *typedPackage
+}
+
+// loadSourceFile reads and parses a single file. Syntax errors are kept on the
+// sourceFile so byte-level rules can still run; only read errors are returned.
+func loadSourceFile(fset *token.FileSet, path string) (*sourceFile, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSourceFile(fset, path, src), nil
+}
+
+// parseSourceFile parses src as the contents of path.
+func parseSourceFile(fset *token.FileSet, path string, src []byte) *sourceFile {
+	node, parseErr := parser.ParseFile(fset, path, src, parser.ParseComments)
+
+	return newSourceFile(fset, path, src, node, parseErr)
+}
+
+// newSourceFile wraps the syntax tree of src, parsed by the caller.
+func newSourceFile(fset *token.FileSet, path string, src []byte, node *ast.File, parseErr error) *sourceFile {
+	return &sourceFile{
+		path:         path,
+		fset:         fset,
+		node:         node,
+		parseErr:     parseErr,
+		src:          src,
+		skip:         hasSkipDirectiveSource(src),
+		suppressions: parseSuppressions(fset, node, src),
+	}
+}
+
+// parsed returns the syntax tree, or nil when the file does not parse.
+func (f *sourceFile) parsed() *ast.File {
+	if f.parseErr != nil {
+		return nil
+	}
+
+	return f.node
+}
+
+// packageName returns the package clause name, or an empty string when the
+// clause itself could not be parsed.
+func (f *sourceFile) packageName() string {
+	if f.node == nil || f.node.Name == nil {
+		return ""
+	}
+
+	return f.node.Name.Name
+}
+
+func (f *sourceFile) isTest() bool {
+	return strings.HasSuffix(f.path, "_test.go")
+}
+
+// fileIndex holds every Go file of the project in walk order. Generated
+// protobuf files, skippedDirs and globally excluded paths are left out.
+type fileIndex struct {
+	fset  *token.FileSet
+	files []*sourceFile
+	// pool is shared by every parallel map over the index, so checks and
+	// the files they walk stay within one bound together.
+	pool *workerPool
+}
+
+// buildFileIndex walks root once and parses every Go file it finds, spreading
+// the parsing across jobs workers. Paths matching an exclude glob are skipped.
+func buildFileIndex(root string, jobs int, exclude []string) (*fileIndex, error) {
+	idx := &fileIndex{
+		fset: token.NewFileSet(),
+		pool: newWorkerPool(jobs),
+	}
+
+	var paths []string
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		excluded := path != root && matchAny(exclude, path)
+
+		if info.IsDir() {
+			if skippedDirs[info.Name()] || excluded {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") || excluded {
+			return nil
+		}
+
+		paths = append(paths, path)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to index Go files: %w", err)
+	}
+
+	type loadResult struct {
+		file *sourceFile
+		err  error
+	}
+
+	results := parallelMap(idx.pool, paths, func(path string) loadResult {
+		file, err := loadSourceFile(idx.fset, path)
+		return loadResult{file: file, err: err}
+	})
+
+	for _, result := range results {
+		if result.err != nil {
+			return nil, fmt.Errorf("failed to index Go files: %w", result.err)
+		}
+
+		idx.files = append(idx.files, result.file)
+	}
+
+	return idx, nil
+}
+
+// sources returns the non-test files.
+func (idx *fileIndex) sources() []*sourceFile {
+	files := make([]*sourceFile, 0, len(idx.files))
+
+	for _, file := range idx.files {
+		if !file.isTest() {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// collect runs fn on every file across the index workers and concatenates the
+// violations in file order.
+func (idx *fileIndex) collect(files []*sourceFile, fn func(*sourceFile) []Violation) []Violation {
+	var violations []Violation
+
+	for _, fileViolations := range parallelMap(idx.pool, files, fn) {
+		violations = append(violations, fileViolations...)
+	}
+
+	return violations
+}
+
+// only returns an index over the files that appear in changes. The files and
+// file set are shared with idx.
+func (idx *fileIndex) only(changes tools.Changes) *fileIndex {
+	scoped := &fileIndex{
+		fset: idx.fset,
+		pool: idx.pool,
+	}
+
+	for _, file := range idx.files {
+		if changes.HasFile(filepath.ToSlash(file.path)) {
+			scoped.files = append(scoped.files, file)
+		}
+	}
+
+	return scoped
+}
+
+// lookup returns the indexed file at path, or nil.
+func (idx *fileIndex) lookup(path string) *sourceFile {
+	for _, file := range idx.files {
+		if file.path == path {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// hasSkipDirectiveSource reports whether a skip directive appears in the
+// comments above the package clause.
+func hasSkipDirectiveSource(src []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "//") {
+			if strings.HasPrefix(line, skipDirective) {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,308 @@
+package policy
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+// testFileIndex indexes the current directory.
+func testFileIndex(t *testing.T) *fileIndex {
+	t.Helper()
+
+	idx, err := buildFileIndex(".", DefaultJobs(), nil)
+	require.NoError(t, err)
+
+	return idx
+}
+
+// testSourceFile loads a single file outside of any index.
+func testSourceFile(t *testing.T, path string) *sourceFile {
+	t.Helper()
+
+	file, err := loadSourceFile(token.NewFileSet(), path)
+	require.NoError(t, err)
+
+	return file
+}
+
+func Test_loadSourceFile(t *testing.T) {
+	t.Run("parses valid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "service.go")
+		require.NoError(t, os.WriteFile(path, []byte("package service\n\nfunc Run() {}\n"), 0644))
+
+		file, err := loadSourceFile(token.NewFileSet(), path)
+		require.NoError(t, err)
+		assert.Equal(t, path, file.path)
+		assert.NotNil(t, file.parsed())
+		assert.Equal(t, "service", file.packageName())
+		assert.False(t, file.skip)
+		assert.False(t, file.isTest())
+	})
+
+	t.Run("keeps source of files with syntax errors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "broken_test.go")
+		require.NoError(t, os.WriteFile(path, []byte("package broken\n\nfunc {\n"), 0644))
+
+		file, err := loadSourceFile(token.NewFileSet(), path)
+		require.NoError(t, err)
+		assert.Error(t, file.parseErr)
+		assert.Nil(t, file.parsed())
+		assert.Equal(t, "broken", file.packageName())
+		assert.NotEmpty(t, file.src)
+		assert.True(t, file.isTest())
+	})
+
+	t.Run("returns empty package name for invalid package clause", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "invalid.go")
+		require.NoError(t, os.WriteFile(path, []byte("not a go file"), 0644))
+
+		file, err := loadSourceFile(token.NewFileSet(), path)
+		require.NoError(t, err)
+		assert.Equal(t, "", file.packageName())
+	})
+
+	t.Run("detects skip directive", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "skipped.go")
+		require.NoError(t, os.WriteFile(path, []byte("//yake:skip-test\npackage skipped\n"), 0644))
+
+		assert.True(t, testSourceFile(t, path).skip)
+	})
+
+	t.Run("returns error for missing file", func(t *testing.T) {
+		_, err := loadSourceFile(token.NewFileSet(), "/non/existent/file.go")
+		assert.Error(t, err)
+	})
+}
+
+func Test_buildFileIndex(t *testing.T) {
+	t.Run("indexes go files and skips excluded directories", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		for _, dir := range []string{"pkg", "vendor/dep", "examples", "testdata"} {
+			require.NoError(t, os.MkdirAll(dir, 0755))
+		}
+
+		files := map[string]string{
+			"main.go":          "package main\n\nfunc main() {}\n",
+			"pkg/pkg.go":       "package pkg\n",
+			"pkg/pkg_test.go":  "package pkg\n",
+			"pkg/api.pb.go":    "package pkg\n",
+			"pkg/readme.md":    "docs\n",
+			"vendor/dep/a.go":  "package dep\n",
+			"examples/main.go": "package main\n",
+			"testdata/data.go": "package testdata\n",
+		}
+
+		for path, content := range files {
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+
+		idx := testFileIndex(t)
+
+		var paths []string
+		for _, file := range idx.files {
+			paths = append(paths, file.path)
+			assert.Same(t, idx.fset, file.fset)
+		}
+
+		assert.Equal(t, []string{"main.go", filepath.Join("pkg", "pkg.go"), filepath.Join("pkg", "pkg_test.go"), filepath.Join("testdata", "data.go")}, paths)
+		assert.Len(t, idx.sources(), 3)
+		assert.NotNil(t, idx.lookup("main.go"))
+		assert.Nil(t, idx.lookup("missing.go"))
+	})
+
+	t.Run("skips globally excluded paths", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+
+		require.NoError(t, os.MkdirAll("internal/mocks", 0755))
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n"), 0644))
+		require.NoError(t, os.WriteFile("types_gen.go", []byte("package main\n"), 0644))
+		require.NoError(t, os.WriteFile("internal/mocks/db.go", []byte("package mocks\n"), 0644))
+
+		idx, err := buildFileIndex(".", 1, []string{"mocks", "*_gen.go"})
+		require.NoError(t, err)
+		require.Len(t, idx.files, 1)
+		assert.Equal(t, "main.go", idx.files[0].path)
+	})
+
+	t.Run("returns error for missing root", func(t *testing.T) {
+		_, err := buildFileIndex("/non/existent/dir", 1, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to index Go files")
+	})
+}
+
+func Test_fileIndexOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.MkdirAll("pkg", 0755))
+	require.NoError(t, os.WriteFile("main.go", []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile("pkg/pkg.go", []byte("package pkg\n"), 0644))
+
+	idx := testFileIndex(t)
+	scoped := idx.only(tools.Changes{"pkg/pkg.go": nil, "deleted.go": nil})
+
+	require.Len(t, scoped.files, 1)
+	assert.Equal(t, filepath.Join("pkg", "pkg.go"), scoped.files[0].path)
+	assert.Same(t, idx.fset, scoped.fset)
+	assert.Len(t, idx.files, 2)
+}
+
+func Test_hasSkipDirectiveSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected bool
+	}{
+		{"directive before package", "//yake:skip-test\npackage foo\n", true},
+		{"directive after other comments", "// Package foo.\n\n//yake:skip-test\npackage foo\n", true},
+		{"no directive", "// Package foo.\npackage foo\n", false},
+		{"directive after package clause", "package foo\n\n//yake:skip-test\n", false},
+		{"space after slashes", "// yake:skip-test\npackage foo\n", false},
+		{"empty source", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasSkipDirectiveSource([]byte(tt.src)))
+		})
+	}
+}
+
+// astRuleChecks lists every rule that runs purely on the file index.
+func astRuleChecks() []func(idx *fileIndex) ([]Violation, error) {
+	return []func(idx *fileIndex) ([]Violation, error){
+		func(idx *fileIndex) ([]Violation, error) {
+			return checkEntryPoints(idx, config.DefaultMaxMainLines)
+		},
+		func(idx *fileIndex) ([]Violation, error) {
+			return checkPackageNaming(idx, config.DefaultPackageNamingPattern)
+		},
+		checkASCIIOnly,
+		checkStringConcat,
+		checkStdlibWrappers,
+		func(idx *fileIndex) ([]Violation, error) {
+			return checkFuncSignature(idx, config.DefaultMaxFuncParams, config.DefaultMaxFuncResults)
+		},
+		func(idx *fileIndex) ([]Violation, error) {
+			return checkCompositeLiteral(idx, config.DefaultMaxSingleLineFields)
+		},
+		checkStuttering,
+		checkGetterNaming,
+		checkPrivateExportedMethods,
+		checkNoInit,
+		checkTestFileNaming,
+	}
+}
+
+// writeBenchmarkProject generates a project with the given number of
+// packages, each holding a source file and its test.
+func writeBenchmarkProject(b *testing.B, packages int) {
+	b.Helper()
+
+	require.NoError(b, os.WriteFile("go.mod", []byte("module bench\n\ngo 1.21\n"), 0644))
+	require.NoError(b, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	for i := 0; i < packages; i++ {
+		name := fmt.Sprintf("pkg%d", i)
+		dir := filepath.Join("internal", name)
+		require.NoError(b, os.MkdirAll(dir, 0755))
+
+		src := fmt.Sprintf(`package %s
+
+import "fmt"
+
+type service struct {
+	name string
+}
+
+func newService(name string) *service {
+	return &service{name: name}
+}
+
+func (s *service) describe(id int) string {
+	label := fmt.Sprintf("%%s-%%d", s.name, id)
+	if id > 10 {
+		label = fmt.Sprintf("%%s-large", label)
+	}
+
+	return label
+}
+`, name)
+
+		test := fmt.Sprintf("package %s\n\nimport \"testing\"\n\nfunc TestService(t *testing.T) {\n\t_ = newService(\"a\").describe(1)\n}\n", name)
+
+		require.NoError(b, os.WriteFile(filepath.Join(dir, "service.go"), []byte(src), 0644))
+		require.NoError(b, os.WriteFile(filepath.Join(dir, "service_test.go"), []byte(test), 0644))
+	}
+}
+
+func BenchmarkFileIndex(b *testing.B) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	require.NoError(b, os.Chdir(b.TempDir()))
+	writeBenchmarkProject(b, 200)
+
+	checks := astRuleChecks()
+
+	b.Run("parse per rule", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, check := range checks {
+				idx, err := buildFileIndex(".", 1, nil)
+				require.NoError(b, err)
+
+				_, err = check(idx)
+				require.NoError(b, err)
+			}
+		}
+	})
+
+	cases := []struct {
+		name string
+		jobs int
+	}{
+		{"shared index", 1},
+		{"shared index parallel", DefaultJobs()},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				idx, err := buildFileIndex(".", tc.jobs, nil)
+				require.NoError(b, err)
+
+				for _, check := range checks {
+					_, err = check(idx)
+					require.NoError(b, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package policy
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+// Report formats accepted by WriteReport.
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatSARIF  = "sarif"
+	FormatJUnit  = "junit"
+	FormatGitHub = "github"
+)
+
+// ReportFormats lists every supported report format.
+var ReportFormats = []string{FormatText, FormatJSON, FormatSARIF, FormatJUnit, FormatGitHub}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "yake"
+	toolURI      = "https://github.com/vitalvas/yake"
+)
+
+// WriteReport renders violations in the requested format.
+func WriteReport(w io.Writer, format string, violations []Violation) error {
+	switch format {
+	case FormatText:
+		return writeTextReport(w, violations)
+	case FormatJSON:
+		return writeJSONReport(w, violations)
+	case FormatSARIF:
+		return writeSARIFReport(w, violations)
+	case FormatJUnit:
+		return writeJUnitReport(w, violations)
+	case FormatGitHub:
+		return writeGitHubReport(w, violations)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+func writeTextReport(w io.Writer, violations []Violation) error {
+	err := ViolationsError(violations)
+	if err == nil {
+		return nil
+	}
+
+	_, writeErr := fmt.Fprintln(w, err.Error())
+
+	return writeErr
+}
+
+type jsonReport struct {
+	Total      int         `json:"total"`
+	Violations []Violation `json:"violations"`
+}
+
+func writeJSONReport(w io.Writer, violations []Violation) error {
+	report := jsonReport{
+		Total:      len(violations),
+		Violations: violations,
+	}
+
+	if report.Violations == nil {
+		report.Violations = []Violation{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIFReport(w io.Writer, violations []Violation) error {
+	ruleIDs := reportRules(violations)
+	ruleIndex := make(map[string]int, len(ruleIDs))
+	rules := make([]sarifRule, 0, len(ruleIDs))
+
+	for i, id := range ruleIDs {
+		ruleIndex[id] = i
+		rules = append(rules, sarifRule{
+			ID:               id,
+			ShortDescription: sarifMessage{Text: ruleTitle(id)},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(violations))
+
+	for _, v := range violations {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(v.File)},
+		}
+
+		if v.Line > 0 {
+			location.Region = &sarifRegion{StartLine: v.Line, StartColumn: v.Column}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    v.Rule,
+			RuleIndex: ruleIndex[v.Rule],
+			Level:     sarifLevel(v.Severity),
+			Message:   sarifMessage{Text: reportMessage(v)},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	report := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           toolName,
+						InformationURI: toolURI,
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
+
+func sarifLevel(severity Severity) string {
+	if severity == SeverityWarning {
+		return "warning"
+	}
+
+	return "error"
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnitReport(w io.Writer, violations []Violation) error {
+	grouped := make(map[string][]Violation)
+	for _, v := range violations {
+		grouped[v.Rule] = append(grouped[v.Rule], v)
+	}
+
+	suite := junitTestSuite{Name: "policy"}
+
+	for _, rule := range reportRules(violations) {
+		testCase := junitTestCase{
+			Name:      rule,
+			ClassName: "yake.policy",
+		}
+
+		if ruleViolations := grouped[rule]; len(ruleViolations) > 0 {
+			lines := make([]string, 0, len(ruleViolations))
+			for _, v := range ruleViolations {
+				lines = append(lines, fmt.Sprintf("%s: %s", v.Location(), reportMessage(v)))
+			}
+
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d %s", len(ruleViolations), ruleTitle(rule)),
+				Type:    rule,
+				Text:    strings.Join(lines, "\n"),
+			}
+
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suite.Tests = len(suite.TestCases)
+
+	report := junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// writeGitHubReport emits one workflow command per violation so GitHub
+// Actions shows it as an inline annotation on the pull request diff.
+func writeGitHubReport(w io.Writer, violations []Violation) error {
+	for _, v := range violations {
+		annotation := tools.GitHubAnnotation{
+			Level:   sarifLevel(v.Severity),
+			File:    filepath.ToSlash(v.File),
+			Line:    v.Line,
+			Column:  v.Column,
+			Title:   v.Rule,
+			Message: reportMessage(v),
+		}
+
+		if _, err := fmt.Fprintln(w, annotation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteSummary renders violations as a markdown table suitable for the
+// GitHub Actions job summary.
+func WriteSummary(w io.Writer, violations []Violation) error {
+	var b strings.Builder
+
+	b.WriteString("## yake policy\n\n")
+
+	if len(violations) == 0 {
+		b.WriteString("All policy checks passed.\n")
+
+		_, err := io.WriteString(w, b.String())
+
+		return err
+	}
+
+	fmt.Fprintf(&b, "%d violations found.\n\n", len(violations))
+	b.WriteString("| Rule | Location | Message |\n")
+	b.WriteString("| --- | --- | --- |\n")
+
+	for _, v := range violations {
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s |\n", v.Rule, v.Location(), markdownCell(reportMessage(v)))
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+var markdownCellEscaper = strings.NewReplacer("|", "\\|", "\n", " ")
+
+func markdownCell(s string) string {
+	return markdownCellEscaper.Replace(s)
+}
+
+// reportRules returns every known rule followed by any unknown rule that
+// appears in violations, so reports list passing rules too.
+func reportRules(violations []Violation) []string {
+	rules := append([]string(nil), ruleIDs...)
+
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		seen[rule] = true
+	}
+
+	for _, v := range violations {
+		if !seen[v.Rule] {
+			seen[v.Rule] = true
+			rules = append(rules, v.Rule)
+		}
+	}
+
+	return rules
+}
+
+func reportMessage(v Violation) string {
+	if v.Suggestion == "" {
+		return v.Message
+	}
+
+	return fmt.Sprintf("%s (%s)", v.Message, v.Suggestion)
+}
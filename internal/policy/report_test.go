@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reportFixture() []Violation {
+	return []Violation{
+		{
+			Rule:       RuleStringConcat,
+			File:       "internal/app/app.go",
+			Line:       12,
+			Column:     9,
+			Severity:   SeverityError,
+			Message:    "string concatenation with '+'",
+			Suggestion: "use fmt.Sprintf or strings.Builder",
+		},
+		{
+			Rule:     RuleCoverage,
+			File:     "internal/app",
+			Severity: SeverityWarning,
+			Message:  "75.0% coverage (minimum 80%)",
+		},
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	t.Run("rejects unknown format", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteReport(&buf, "yaml", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported report format: yaml")
+	})
+
+	t.Run("text groups violations by rule", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatText, reportFixture()))
+		assert.Contains(t, buf.String(), "string concatenation violations")
+		assert.Contains(t, buf.String(), "  - internal/app/app.go:12:9: string concatenation with '+'")
+		assert.Contains(t, buf.String(), "  - internal/app: 75.0% coverage (minimum 80%)")
+	})
+
+	t.Run("text is empty without violations", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatText, nil))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("json lists violations with total", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatJSON, reportFixture()))
+
+		var report jsonReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, reportFixture(), report.Violations)
+	})
+
+	t.Run("json encodes empty list", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatJSON, nil))
+		assert.Contains(t, buf.String(), `"violations": []`)
+	})
+
+	t.Run("sarif declares every rule", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatSARIF, reportFixture()))
+
+		var report sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, sarifVersion, report.Version)
+		require.Len(t, report.Runs, 1)
+
+		run := report.Runs[0]
+		assert.Equal(t, "yake", run.Tool.Driver.Name)
+		require.Len(t, run.Tool.Driver.Rules, len(ruleIDs))
+
+		for i, id := range ruleIDs {
+			assert.Equal(t, id, run.Tool.Driver.Rules[i].ID)
+		}
+
+		require.Len(t, run.Results, 2)
+
+		first := run.Results[0]
+		assert.Equal(t, RuleStringConcat, first.RuleID)
+		assert.Equal(t, RuleStringConcat, run.Tool.Driver.Rules[first.RuleIndex].ID)
+		assert.Equal(t, "error", first.Level)
+		assert.Equal(t, "string concatenation with '+' (use fmt.Sprintf or strings.Builder)", first.Message.Text)
+		assert.Equal(t, "internal/app/app.go", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		require.NotNil(t, first.Locations[0].PhysicalLocation.Region)
+		assert.Equal(t, 12, first.Locations[0].PhysicalLocation.Region.StartLine)
+		assert.Equal(t, 9, first.Locations[0].PhysicalLocation.Region.StartColumn)
+
+		second := run.Results[1]
+		assert.Equal(t, "warning", second.Level)
+		assert.Nil(t, second.Locations[0].PhysicalLocation.Region)
+	})
+
+	t.Run("sarif appends unknown rules", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		violations := []Violation{{Rule: "custom", File: "a.go", Message: "msg"}}
+		require.NoError(t, WriteReport(&buf, FormatSARIF, violations))
+
+		var report sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+		rules := report.Runs[0].Tool.Driver.Rules
+		assert.Equal(t, "custom", rules[len(rules)-1].ID)
+		assert.Equal(t, len(rules)-1, report.Runs[0].Results[0].RuleIndex)
+	})
+
+	t.Run("junit reports one testcase per rule", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatJUnit, reportFixture()))
+		assert.Contains(t, buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`)
+
+		var report junitTestSuites
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, len(ruleIDs), report.Tests)
+		assert.Equal(t, 2, report.Failures)
+		require.Len(t, report.Suites, 1)
+
+		failures := make(map[string]*junitFailure)
+		for _, tc := range report.Suites[0].TestCases {
+			failures[tc.Name] = tc.Failure
+		}
+
+		require.NotNil(t, failures[RuleStringConcat])
+		assert.Equal(t, RuleStringConcat, failures[RuleStringConcat].Type)
+		assert.Contains(t, failures[RuleStringConcat].Text, "internal/app/app.go:12:9")
+		require.NotNil(t, failures[RuleCoverage])
+		assert.Nil(t, failures[RuleNoInit])
+	})
+}
+
+func TestWriteReportGitHub(t *testing.T) {
+	t.Run("emits one annotation per violation", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatGitHub, reportFixture()))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, "::error file=internal/app/app.go,line=12,col=9,title=string_concat::string concatenation with '+' (use fmt.Sprintf or strings.Builder)", lines[0])
+		assert.Equal(t, "::warning file=internal/app,title=coverage::75.0%25 coverage (minimum 80%25)", lines[1])
+	})
+
+	t.Run("writes nothing without violations", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteReport(&buf, FormatGitHub, nil))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriteSummary(t *testing.T) {
+	t.Run("renders markdown table", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteSummary(&buf, reportFixture()))
+		assert.Contains(t, buf.String(), "## yake policy")
+		assert.Contains(t, buf.String(), "2 violations found.")
+		assert.Contains(t, buf.String(), "| Rule | Location | Message |")
+		assert.Contains(t, buf.String(), "| `string_concat` | `internal/app/app.go:12:9` | string concatenation with '+' (use fmt.Sprintf or strings.Builder) |")
+	})
+
+	t.Run("reports success without violations", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, WriteSummary(&buf, nil))
+		assert.Contains(t, buf.String(), "All policy checks passed.")
+		assert.NotContains(t, buf.String(), "| Rule |")
+	})
+}
+
+func Test_markdownCell(t *testing.T) {
+	assert.Equal(t, "a \\| b c", markdownCell("a | b\nc"))
+}
+
+func Test_sarifLevel(t *testing.T) {
+	assert.Equal(t, "error", sarifLevel(SeverityError))
+	assert.Equal(t, "warning", sarifLevel(SeverityWarning))
+	assert.Equal(t, "error", sarifLevel(""))
+}
+
+func Test_reportMessage(t *testing.T) {
+	assert.Equal(t, "msg", reportMessage(Violation{Message: "msg"}))
+	assert.Equal(t, "msg (hint)", reportMessage(Violation{Message: "msg", Suggestion: "hint"}))
+}
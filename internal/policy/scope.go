@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+// matchPath reports whether name, a path relative to the project root, or
+// one of its parent directories matches the glob. A glob without a slash is
+// matched against every path element, so "mocks" or "*_gen.go" apply at any
+// depth; "**" matches any number of directories.
+func matchPath(pattern, name string) bool {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(name)), "/")
+
+	if !strings.Contains(pattern, "/") {
+		for _, part := range parts {
+			// Malformed patterns are rejected when the config loads.
+			if ok, err := path.Match(pattern, part); err == nil && ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	patternParts := strings.Split(strings.TrimPrefix(pattern, "./"), "/")
+
+	for i := len(parts); i > 0; i-- {
+		if matchSegments(patternParts, parts[:i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inScope reports whether a path passes a section's paths and exclude_paths.
+func inScope(scope config.PolicyScope, name string) bool {
+	if len(scope.Paths) > 0 && !matchAny(scope.Paths, name) {
+		return false
+	}
+
+	return !matchAny(scope.ExcludePaths, name)
+}
+
+// filterScoped drops violations outside their section's scope or under the
+// global exclude list. Package-level violations are scoped by directory.
+func filterScoped(violations []Violation, policyCfg config.PolicyConfig) []Violation {
+	scopes := policyCfg.Scopes()
+
+	var kept []Violation
+
+	for _, v := range violations {
+		if matchAny(policyCfg.Exclude, v.File) || !inScope(scopes[v.Rule], v.File) {
+			continue
+		}
+
+		kept = append(kept, v)
+	}
+
+	return kept
+}
+
+// filterChangedLines keeps the violations that point at a changed line.
+// Package-level violations and those of project-wide rules are kept as is.
+func filterChangedLines(violations []Violation, changes tools.Changes, projectRules map[string]bool) []Violation {
+	var kept []Violation
+
+	for _, v := range violations {
+		if v.Line > 0 && !projectRules[v.Rule] && !changes.HasLine(filepath.ToSlash(v.File), v.Line) {
+			continue
+		}
+
+		kept = append(kept, v)
+	}
+
+	return kept
+}
@@ -0,0 +1,209 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+func Test_matchPath(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"mocks", "internal/mocks/client.go", true},
+		{"mocks", "internal/mocksy/client.go", false},
+		{"*_gen.go", "internal/api/types_gen.go", true},
+		{"*_gen.go", "internal/api/types.go", false},
+		{"third_party", "third_party", true},
+		{"internal/generated", "internal/generated/api/client.go", true},
+		{"internal/generated/", "internal/generated/api/client.go", true},
+		{"./internal/generated", "internal/generated/client.go", true},
+		{"internal/generated", "pkg/internal/generated/client.go", false},
+		{"internal/*/mock.go", "internal/db/mock.go", true},
+		{"internal/*/mock.go", "internal/db/sub/mock.go", false},
+		{"internal/**/mock.go", "internal/db/sub/mock.go", true},
+		{"internal/**/mock.go", "internal/mock.go", true},
+		{"**/testdata", "a/b/testdata/x.go", true},
+		{"internal/**", "internal/db", true},
+		{"internal/**", "cmd/app", false},
+		{"cmd/*", ".", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.pattern, tt.name), func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchPath(tt.pattern, tt.name))
+		})
+	}
+}
+
+func Test_inScope(t *testing.T) {
+	t.Run("empty scope allows everything", func(t *testing.T) {
+		assert.True(t, inScope(config.PolicyScope{}, "main.go"))
+	})
+
+	t.Run("paths restrict to matching files", func(t *testing.T) {
+		scope := config.PolicyScope{Paths: []string{"internal/**"}}
+
+		assert.True(t, inScope(scope, "internal/db/db.go"))
+		assert.False(t, inScope(scope, "cmd/app/main.go"))
+	})
+
+	t.Run("exclude paths win over paths", func(t *testing.T) {
+		scope := config.PolicyScope{
+			Paths:        []string{"internal"},
+			ExcludePaths: []string{"internal/mocks"},
+		}
+
+		assert.True(t, inScope(scope, "internal/db/db.go"))
+		assert.False(t, inScope(scope, "internal/mocks/db.go"))
+	})
+}
+
+func Test_filterScoped(t *testing.T) {
+	violations := []Violation{
+		{Rule: RuleStringConcat, File: "internal/db/db.go", Line: 3},
+		{Rule: RuleStringConcat, File: "internal/mocks/db.go", Line: 3},
+		{Rule: RuleNoInit, File: "internal/mocks/db.go", Line: 9},
+		{Rule: RuleNoInit, File: "third_party/lib/lib.go", Line: 1},
+		{Rule: RuleCoverage, File: "internal/mocks"},
+		{Rule: RuleCoverage, File: "internal/db"},
+	}
+
+	policyCfg := config.PolicyConfig{
+		Exclude: []string{"third_party"},
+		StringConcat: &config.PolicyToggle{
+			PolicyScope: config.PolicyScope{ExcludePaths: []string{"mocks"}},
+		},
+		Coverage: &config.CoveragePolicy{
+			PolicyScope: config.PolicyScope{Paths: []string{"internal/db"}},
+		},
+	}
+
+	assert.Equal(t, []Violation{violations[0], violations[2], violations[5]}, filterScoped(violations, policyCfg))
+}
+
+func Test_filterChangedLines(t *testing.T) {
+	violations := []Violation{
+		{Rule: RuleStringConcat, File: "main.go", Line: 4},
+		{Rule: RuleStringConcat, File: "main.go", Line: 9},
+		{Rule: RulePackageNaming, File: "main.go"},
+		{Rule: RuleEntryPoints, File: "main.go", Line: 12},
+		{Rule: RuleNoInit, File: "other.go", Line: 4},
+	}
+
+	changes := tools.Changes{"main.go": {{Start: 3, End: 5}}}
+
+	got := filterChangedLines(violations, changes, map[string]bool{RuleEntryPoints: true})
+
+	assert.Equal(t, []Violation{violations[0], violations[2], violations[3]}, got)
+}
+
+func TestRunGolangChecks_changed(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n"
+	require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc init() {}\n\nfunc main() {}\n"), 0644))
+
+	files := map[string]string{
+		"internal/app/app.go":        "package app\n\nfunc init() {}\n\nfunc init() {}\n",
+		"internal/app/app_test.go":   "package app\n",
+		"internal/util/util.go":      "package util\n\nfunc init() {}\n",
+		"internal/util/util_test.go": "package util\n",
+	}
+
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	changes := tools.Changes{"internal/app/app.go": {{Start: 5, End: 5}}}
+
+	t.Run("checks changed files and project-wide rules", func(t *testing.T) {
+		violations, err := RunGolangChecks(t.Context(), RunOptions{Changed: changes})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"main.go:3:1: unexpected function 'init'",
+			"internal/app/app.go:3:1: init() function is forbidden",
+			"internal/app/app.go:5:1: init() function is forbidden",
+		}, violationStrings(violations))
+	})
+
+	t.Run("restricts violations to changed lines", func(t *testing.T) {
+		violations, err := RunGolangChecks(t.Context(), RunOptions{Changed: changes, ChangedLines: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"main.go:3:1: unexpected function 'init'",
+			"internal/app/app.go:5:1: init() function is forbidden",
+		}, violationStrings(violations))
+	})
+
+	t.Run("skips project-wide rules", func(t *testing.T) {
+		violations, err := RunGolangChecks(t.Context(), RunOptions{Changed: changes, SkipProjectRules: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"internal/app/app.go:3:1: init() function is forbidden",
+			"internal/app/app.go:5:1: init() function is forbidden",
+		}, violationStrings(violations))
+	})
+}
+
+func TestRunGolangChecks_scopes(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	yakeConfig := `policy:
+  exclude:
+    - third_party
+  test_duration:
+    enable: false
+  coverage:
+    enable: false
+  no_init:
+    exclude_paths:
+      - internal/legacy/**
+`
+	require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	files := map[string]string{
+		"third_party/lib/lib.go":          "package lib\n\nfunc init() {}\n",
+		"internal/legacy/old/old.go":      "package old\n\nfunc init() {}\n",
+		"internal/legacy/old/old_test.go": "package old\n",
+		"internal/app/app.go":             "package app\n\nfunc init() {}\n",
+		"internal/app/app_test.go":        "package app\n",
+	}
+
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	violations, err := RunGolangChecks(t.Context(), RunOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"internal/app/app.go:3:1: init() function is forbidden",
+	}, violationStrings(violations))
+}
@@ -0,0 +1,241 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+const ignoreDirective = "//yake:ignore"
+
+// suppression is a single //yake:ignore directive. A directive above the
+// package clause covers the whole file, one in a declaration's doc comment
+// covers that declaration, one trailing code covers its own line, and any
+// other covers the line right after its comment block.
+type suppression struct {
+	line   int
+	rules  []string
+	reason string
+	file   bool
+	from   int
+	to     int
+	used   map[string]bool
+}
+
+func (s *suppression) matches(v Violation) bool {
+	if !slices.Contains(s.rules, v.Rule) {
+		return false
+	}
+
+	if s.file {
+		return true
+	}
+
+	return v.Line >= s.from && v.Line <= s.to
+}
+
+// parseIgnoreDirective splits "//yake:ignore rule[,rule...] -- reason" into
+// its rules and reason. ok is false for comments that are not directives.
+func parseIgnoreDirective(text string) (rules []string, reason string, ok bool) {
+	rest, found := strings.CutPrefix(text, ignoreDirective)
+	if !found || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return nil, "", false
+	}
+
+	spec, reason, _ := strings.Cut(rest, "--")
+
+	rules = strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	return rules, strings.TrimSpace(reason), true
+}
+
+// parseSuppressions collects the //yake:ignore directives of a file. Files
+// whose package clause does not parse have none.
+func parseSuppressions(fset *token.FileSet, node *ast.File, src []byte) []*suppression {
+	if node == nil || node.Name == nil {
+		return nil
+	}
+
+	docs := make(map[*ast.CommentGroup]ast.Decl)
+
+	for _, decl := range node.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				docs[d.Doc] = d
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil {
+				docs[d.Doc] = d
+			}
+		}
+	}
+
+	lines := bytes.Split(src, []byte("\n"))
+
+	var suppressions []*suppression
+
+	for _, group := range node.Comments {
+		for _, comment := range group.List {
+			rules, reason, ok := parseIgnoreDirective(comment.Text)
+			if !ok {
+				continue
+			}
+
+			pos := fset.Position(comment.Slash)
+
+			s := &suppression{
+				line:   pos.Line,
+				rules:  rules,
+				reason: reason,
+				used:   make(map[string]bool),
+			}
+
+			decl, isDoc := docs[group]
+
+			switch {
+			case comment.Pos() < node.Package:
+				s.file = true
+			case hasCodeBefore(lines, pos):
+				s.from, s.to = pos.Line, pos.Line
+			case isDoc:
+				s.from, s.to = pos.Line, fset.Position(decl.End()).Line
+			default:
+				next := fset.Position(group.End()).Line + 1
+				s.from, s.to = next, next
+			}
+
+			suppressions = append(suppressions, s)
+		}
+	}
+
+	return suppressions
+}
+
+// hasCodeBefore reports whether anything but whitespace precedes pos on its
+// line, meaning the comment trails a statement.
+func hasCodeBefore(lines [][]byte, pos token.Position) bool {
+	if pos.Line > len(lines) {
+		return false
+	}
+
+	line := lines[pos.Line-1]
+	if pos.Column-1 > len(line) {
+		return false
+	}
+
+	return len(bytes.TrimSpace(line[:pos.Column-1])) > 0
+}
+
+type suppressionOptions struct {
+	// ran holds the rules that completed; only their directives can be
+	// reported as unused.
+	ran           map[string]bool
+	report        bool
+	requireReason bool
+	// changed limits reporting to directives in these files. Nil reports
+	// directives everywhere.
+	changed tools.Changes
+}
+
+// applySuppressions drops the violations covered by a //yake:ignore directive
+// and, when reporting is enabled, adds violations for directives that are
+// unused, name unknown rules or lack a required reason.
+func applySuppressions(idx *fileIndex, violations []Violation, opts suppressionOptions) []Violation {
+	byFile := make(map[string][]*suppression)
+	byDir := make(map[string][]*suppression)
+
+	for _, file := range idx.files {
+		byFile[file.path] = file.suppressions
+
+		for _, s := range file.suppressions {
+			if s.file {
+				dir := filepath.Dir(file.path)
+				byDir[dir] = append(byDir[dir], s)
+			}
+		}
+	}
+
+	var kept []Violation
+
+	for _, v := range violations {
+		candidates := byFile[v.File]
+		if v.Line == 0 {
+			// Package-level violations point at a directory and can only be
+			// suppressed file-wide from one of its files.
+			candidates = append(slices.Clip(candidates), byDir[v.File]...)
+		}
+
+		suppressed := false
+
+		for _, s := range candidates {
+			if s.matches(v) {
+				s.used[v.Rule] = true
+				suppressed = true
+			}
+		}
+
+		if !suppressed {
+			kept = append(kept, v)
+		}
+	}
+
+	if !opts.report {
+		return kept
+	}
+
+	for _, file := range idx.files {
+		if opts.changed != nil && !opts.changed.HasFile(filepath.ToSlash(file.path)) {
+			continue
+		}
+
+		for _, s := range file.suppressions {
+			kept = append(kept, suppressionViolations(file.path, s, opts)...)
+		}
+	}
+
+	return kept
+}
+
+func suppressionViolations(path string, s *suppression, opts suppressionOptions) []Violation {
+	var violations []Violation
+
+	report := func(message, suggestion string) {
+		violations = append(violations, Violation{
+			Rule:       RuleSuppressions,
+			File:       path,
+			Line:       s.line,
+			Severity:   SeverityError,
+			Message:    message,
+			Suggestion: suggestion,
+		})
+	}
+
+	if len(s.rules) == 0 {
+		report("//yake:ignore does not name a rule", "name the rules to ignore, e.g. //yake:ignore string_concat -- reason")
+	}
+
+	for _, rule := range s.rules {
+		switch {
+		case !slices.Contains(ruleIDs, rule):
+			report(fmt.Sprintf("//yake:ignore names unknown rule '%s'", rule), "use a rule ID from the policy section names")
+		case opts.ran[rule] && !s.used[rule]:
+			report(fmt.Sprintf("unused //yake:ignore for rule '%s'", rule), "remove the stale directive")
+		}
+	}
+
+	if opts.requireReason && s.reason == "" {
+		report("//yake:ignore has no reason", "explain the exception after '--'")
+	}
+
+	return violations
+}
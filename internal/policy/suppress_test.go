@@ -0,0 +1,228 @@
+package policy
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitalvas/yake/internal/tools"
+)
+
+func Test_parseIgnoreDirective(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		rules  []string
+		reason string
+		ok     bool
+	}{
+		{"single rule with reason", "//yake:ignore string_concat -- legacy API", []string{"string_concat"}, "legacy API", true},
+		{"multiple rules", "//yake:ignore no_init, stuttering", []string{"no_init", "stuttering"}, "", true},
+		{"reason without spaces", "//yake:ignore no_init --generated", []string{"no_init"}, "generated", true},
+		{"no rules", "//yake:ignore", []string{}, "", true},
+		{"other directive", "//yake:skip-test", nil, "", false},
+		{"longer word", "//yake:ignored no_init", nil, "", false},
+		{"regular comment", "// yake:ignore no_init", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, reason, ok := parseIgnoreDirective(tt.text)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.rules, rules)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func Test_parseSuppressions(t *testing.T) {
+	src := `//yake:ignore ascii_only -- whole file
+package service
+
+//yake:ignore func_signature -- wide by design
+func Wide(a, b, c, d, e, f int) int {
+	return a + b + c + d + e + f
+}
+
+func Run() string {
+	name := "svc"
+	//yake:ignore string_concat
+	greeting := "hello " + name
+	return greeting + "!" //yake:ignore string_concat -- trailing
+}
+`
+
+	path := filepath.Join(t.TempDir(), "service.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+
+	file := testSourceFile(t, path)
+	require.Len(t, file.suppressions, 4)
+
+	fileWide := file.suppressions[0]
+	assert.True(t, fileWide.file)
+	assert.Equal(t, 1, fileWide.line)
+	assert.Equal(t, "whole file", fileWide.reason)
+
+	decl := file.suppressions[1]
+	assert.False(t, decl.file)
+	assert.Equal(t, 4, decl.from)
+	assert.Equal(t, 7, decl.to)
+
+	nextLine := file.suppressions[2]
+	assert.Equal(t, 12, nextLine.from)
+	assert.Equal(t, 12, nextLine.to)
+	assert.Empty(t, nextLine.reason)
+
+	trailing := file.suppressions[3]
+	assert.Equal(t, 13, trailing.from)
+	assert.Equal(t, 13, trailing.to)
+
+	t.Run("matches rule and range", func(t *testing.T) {
+		assert.True(t, fileWide.matches(Violation{Rule: RuleASCIIOnly, Line: 40}))
+		assert.False(t, fileWide.matches(Violation{Rule: RuleNoInit, Line: 40}))
+		assert.True(t, decl.matches(Violation{Rule: RuleFuncSignature, Line: 5}))
+		assert.False(t, decl.matches(Violation{Rule: RuleFuncSignature, Line: 9}))
+		assert.False(t, nextLine.matches(Violation{Rule: RuleStringConcat}))
+	})
+
+	t.Run("ignores files without package clause", func(t *testing.T) {
+		assert.Nil(t, parseSuppressions(token.NewFileSet(), nil, nil))
+	})
+}
+
+func Test_hasCodeBefore(t *testing.T) {
+	lines := [][]byte{[]byte("x := 1 // note"), []byte("\t// note")}
+
+	assert.True(t, hasCodeBefore(lines, token.Position{Line: 1, Column: 8}))
+	assert.False(t, hasCodeBefore(lines, token.Position{Line: 2, Column: 2}))
+	assert.False(t, hasCodeBefore(lines, token.Position{Line: 3, Column: 1}))
+	assert.False(t, hasCodeBefore(lines, token.Position{Line: 2, Column: 20}))
+}
+
+func Test_applySuppressions(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.MkdirAll("pkg", 0755))
+
+	files := map[string]string{
+		"main.go": "package main\n\nfunc main() {\n\t_ = \"a\" + name //yake:ignore string_concat -- legacy\n\t_ = \"b\" + name\n}\n",
+		"pkg/pkg.go": `//yake:ignore coverage -- generated client
+package pkg
+
+//yake:ignore no_init, stuttering -- registers drivers
+func init() {}
+
+//yake:ignore getter_naming, bogus_rule
+var x = 1
+`,
+	}
+
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	idx := testFileIndex(t)
+
+	violations := []Violation{
+		{Rule: RuleStringConcat, File: "main.go", Line: 4},
+		{Rule: RuleStringConcat, File: "main.go", Line: 5},
+		{Rule: RuleNoInit, File: filepath.Join("pkg", "pkg.go"), Line: 5},
+		{Rule: RuleCoverage, File: "pkg"},
+		{Rule: RuleCoverage, File: "."},
+	}
+
+	t.Run("drops suppressed violations", func(t *testing.T) {
+		got := applySuppressions(idx, violations, suppressionOptions{})
+
+		assert.Equal(t, []Violation{violations[1], violations[4]}, got)
+	})
+
+	t.Run("reports unused, unknown and unexplained directives", func(t *testing.T) {
+		got := applySuppressions(testFileIndex(t), violations, suppressionOptions{
+			ran: map[string]bool{
+				RuleStringConcat: true,
+				RuleNoInit:       true,
+				RuleStuttering:   true,
+				RuleCoverage:     true,
+			},
+			report:        true,
+			requireReason: true,
+		})
+
+		var messages []string
+		for _, v := range got {
+			if v.Rule == RuleSuppressions {
+				messages = append(messages, v.String())
+			}
+		}
+
+		pkgFile := filepath.Join("pkg", "pkg.go")
+
+		assert.Equal(t, []string{
+			fmt.Sprintf("%s:4: unused //yake:ignore for rule 'stuttering'", pkgFile),
+			fmt.Sprintf("%s:7: //yake:ignore names unknown rule 'bogus_rule'", pkgFile),
+			fmt.Sprintf("%s:7: //yake:ignore has no reason", pkgFile),
+		}, messages)
+	})
+
+	t.Run("reports directives only in changed files", func(t *testing.T) {
+		got := applySuppressions(testFileIndex(t), violations, suppressionOptions{
+			ran:     map[string]bool{RuleStuttering: true},
+			report:  true,
+			changed: tools.Changes{"main.go": nil},
+		})
+
+		for _, v := range got {
+			assert.NotEqual(t, RuleSuppressions, v.Rule)
+		}
+	})
+
+	t.Run("reports directive without rules", func(t *testing.T) {
+		got := suppressionViolations("a.go", &suppression{line: 3}, suppressionOptions{})
+
+		require.Len(t, got, 1)
+		assert.Equal(t, "a.go:3: //yake:ignore does not name a rule", got[0].String())
+	})
+}
+
+func TestRunGolangChecks_suppressions(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	yakeConfig := "policy:\n  test_duration:\n    enable: false\n  coverage:\n    enable: false\n  suppressions:\n    require_reason: true\n"
+	require.NoError(t, os.WriteFile(".yake.yaml", []byte(yakeConfig), 0644))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+
+	main := "package main\n\n//yake:ignore no_init -- wires flags\nfunc init() {}\n\nfunc main() {}\n\n//yake:ignore string_concat\nvar greeting = \"a\"\n"
+	require.NoError(t, os.WriteFile("main.go", []byte(main), 0644))
+
+	violations, err := RunGolangChecks(t.Context(), RunOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"main.go:4:1: unexpected function 'init'",
+		"main.go:8: unused //yake:ignore for rule 'string_concat'",
+		"main.go:8: //yake:ignore has no reason",
+	}, violationStrings(violations))
+}
+
+func violationStrings(violations []Violation) []string {
+	out := make([]string, 0, len(violations))
+	for _, v := range violations {
+		out = append(out, v.String())
+	}
+
+	return out
+}
@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"log"
+	"path/filepath"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+// terminationOptions holds the resolved termination section.
+type terminationOptions struct {
+	noStdout      bool
+	allowPackages []string
+}
+
+func resolveTerminationOptions(p *config.TerminationPolicy) terminationOptions {
+	if p == nil {
+		return terminationOptions{}
+	}
+
+	return terminationOptions{
+		noStdout:      p.NoStdout != nil && *p.NoStdout,
+		allowPackages: p.AllowPackages,
+	}
+}
+
+func checkTermination(idx *fileIndex, opts terminationOptions) ([]Violation, error) {
+	log.Println("Checking process termination outside package main...")
+
+	violations := idx.collect(idx.sources(), func(file *sourceFile) []Violation {
+		return findTerminationViolations(file, opts)
+	})
+
+	return violations, nil
+}
+
+// findTerminationViolations reports os.Exit, log.Fatal*, log.Panic* and panic
+// outside package main and, with noStdout, printing to stdout. Files of
+// package main and of the allowed packages are left alone.
+func findTerminationViolations(file *sourceFile, opts terminationOptions) []Violation {
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip || node.Name.Name == "main" {
+		return nil
+	}
+
+	if matchAny(opts.allowPackages, filepath.Dir(file.path)) {
+		return nil
+	}
+
+	c := &terminationChecker{
+		file:     file,
+		os:       importedName(node, "os"),
+		log:      importedName(node, "log"),
+		fmt:      importedName(node, "fmt"),
+		noStdout: opts.noStdout,
+	}
+
+	for _, decl := range node.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && hasFuncSkipDirective(fn) {
+			continue
+		}
+
+		if gd, ok := decl.(*ast.GenDecl); ok && hasGenDeclSkipDirective(gd) {
+			continue
+		}
+
+		ast.Inspect(decl, c.visit)
+	}
+
+	return c.violations
+}
+
+type terminationChecker struct {
+	file *sourceFile
+	// os, log and fmt are the names of the imported packages, if any.
+	os         string
+	log        string
+	fmt        string
+	noStdout   bool
+	violations []Violation
+}
+
+func (c *terminationChecker) report(n ast.Node, message, suggestion string) {
+	pos := c.file.fset.Position(n.Pos())
+	c.violations = append(c.violations, Violation{
+		Rule:       RuleTermination,
+		File:       c.file.path,
+		Line:       pos.Line,
+		Column:     pos.Column,
+		Severity:   SeverityError,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+func (c *terminationChecker) visit(n ast.Node) bool {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return true
+	}
+
+	name := types.ExprString(call.Fun)
+
+	switch {
+	case isCall(call, c.os, "Exit"):
+		c.report(call, fmt.Sprintf("%s outside package main", name), "return an error and leave the exit code to main")
+	case c.isLogCall(call):
+		c.report(call, fmt.Sprintf("%s outside package main", name), "return an error instead of ending the process")
+	case c.isBuiltin(call, "panic"):
+		c.report(call, "panic outside package main", "return an error instead of panicking")
+	case c.noStdout && c.isPrint(call):
+		c.report(call, fmt.Sprintf("%s writes to stdout outside package main", name), "accept an io.Writer or return the text to the caller")
+	}
+
+	return true
+}
+
+func (c *terminationChecker) isLogCall(call *ast.CallExpr) bool {
+	for _, name := range []string{"Fatal", "Fatalf", "Fatalln", "Panic", "Panicf", "Panicln"} {
+		if isCall(call, c.log, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *terminationChecker) isPrint(call *ast.CallExpr) bool {
+	if isCall(call, c.fmt, "Print") || isCall(call, c.fmt, "Printf") || isCall(call, c.fmt, "Println") {
+		return true
+	}
+
+	return c.isBuiltin(call, "print") || c.isBuiltin(call, "println")
+}
+
+// isBuiltin reports whether call invokes the named builtin. Without type
+// information a local function of the same name is taken for the builtin.
+func (c *terminationChecker) isBuiltin(call *ast.CallExpr, name string) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok || ident.Name != name {
+		return false
+	}
+
+	if c.file.typed != nil {
+		_, ok := c.file.typed.info.Uses[ident].(*types.Builtin)
+		return ok
+	}
+
+	return true
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+)
+
+const terminationSource = `package store
+
+import (
+	"fmt"
+	stdlog "log"
+	"os"
+)
+
+func Open(path string) {
+	if path == "" {
+		os.Exit(2)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		stdlog.Fatalf("stat: %v", err)
+	}
+
+	defer stdlog.Panicln("closed")
+
+	fmt.Println("opened", path)
+	println(path)
+	panic("unreachable")
+}
+
+//yake:skip-test
+func Must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+`
+
+func Test_findTerminationViolations(t *testing.T) {
+	file := parseSourceFile(token.NewFileSet(), "internal/store/store.go", []byte(terminationSource))
+	require.NoError(t, file.parseErr)
+
+	t.Run("reports process termination outside package main", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"internal/store/store.go:11:3: os.Exit outside package main",
+			"internal/store/store.go:15:3: stdlog.Fatalf outside package main",
+			"internal/store/store.go:18:8: stdlog.Panicln outside package main",
+			"internal/store/store.go:22:2: panic outside package main",
+		}, violationStrings(findTerminationViolations(file, terminationOptions{})))
+	})
+
+	t.Run("reports printing to stdout with no_stdout", func(t *testing.T) {
+		violations := violationStrings(findTerminationViolations(file, terminationOptions{noStdout: true}))
+
+		assert.Len(t, violations, 6)
+		assert.Contains(t, violations, "internal/store/store.go:20:2: fmt.Println writes to stdout outside package main")
+		assert.Contains(t, violations, "internal/store/store.go:21:2: println writes to stdout outside package main")
+	})
+
+	t.Run("skips allowed packages", func(t *testing.T) {
+		assert.Empty(t, findTerminationViolations(file, terminationOptions{allowPackages: []string{"internal/store"}}))
+	})
+
+	t.Run("skips package main", func(t *testing.T) {
+		main := parseSourceFile(token.NewFileSet(), "main.go", []byte("package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Exit(1)\n}\n"))
+		require.NoError(t, main.parseErr)
+
+		assert.Empty(t, findTerminationViolations(main, terminationOptions{}))
+	})
+
+	t.Run("tells the builtin from local functions by type", func(t *testing.T) {
+		writeFixProject(t, map[string]string{
+			"store/store.go": `package store
+
+func panic(v any) {}
+
+func Close() {
+	panic("closed")
+}
+`,
+		})
+
+		files := loadTypedFiles(t, nil)
+		require.NotNil(t, files["store/store.go"].typed)
+
+		assert.Empty(t, findTerminationViolations(files["store/store.go"], terminationOptions{}))
+	})
+}
+
+func Test_resolveTerminationOptions(t *testing.T) {
+	assert.Equal(t, terminationOptions{}, resolveTerminationOptions(nil))
+	assert.Equal(t, terminationOptions{
+		noStdout:      true,
+		allowPackages: []string{"internal/cli"},
+	}, resolveTerminationOptions(&config.TerminationPolicy{
+		NoStdout:      boolPtr(true),
+		AllowPackages: []string{"internal/cli"},
+	}))
+}
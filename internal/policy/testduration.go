@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
+)
+
+// durationLimits are the test duration limits of one package. A zero
+// maxTestDuration turns the per-test check off.
+type durationLimits struct {
+	maxDuration     time.Duration
+	maxTestDuration time.Duration
+}
+
+// testDurationOptions holds the resolved test_duration section.
+type testDurationOptions struct {
+	limits       durationLimits
+	slowestTests int
+	// overrides holds the limits of single packages, keyed by package
+	// directory or import path.
+	overrides map[string]durationLimits
+}
+
+func resolveTestDurationOptions(p *config.TestDurationPolicy) testDurationOptions {
+	opts := testDurationOptions{
+		limits: durationLimits{maxDuration: resolveMaxTestDuration(p)},
+	}
+
+	if p == nil {
+		return opts
+	}
+
+	opts.limits.maxTestDuration = parseDurationOr(p.MaxTestDuration, 0)
+
+	if p.SlowestTests != nil {
+		opts.slowestTests = *p.SlowestTests
+	}
+
+	if len(p.PackageOverrides) > 0 {
+		opts.overrides = make(map[string]durationLimits, len(p.PackageOverrides))
+	}
+
+	for pkg, override := range p.PackageOverrides {
+		opts.overrides[pkg] = durationLimits{
+			maxDuration:     parseDurationOr(override.MaxDuration, opts.limits.maxDuration),
+			maxTestDuration: parseDurationOr(override.MaxTestDuration, opts.limits.maxTestDuration),
+		}
+	}
+
+	return opts
+}
+
+// parseDurationOr parses an optional duration, falling back to def when it is
+// unset or invalid.
+func parseDurationOr(s *string, def time.Duration) time.Duration {
+	if s == nil {
+		return def
+	}
+
+	d, err := time.ParseDuration(*s)
+	if err != nil {
+		return def
+	}
+
+	return d
+}
+
+// limitsFor returns the limits of a package, matched against the overrides
+// as packageMinCoverage matches coverage overrides.
+func (o testDurationOptions) limitsFor(pkgName, modulePath string) durationLimits {
+	for pattern, limits := range o.overrides {
+		if pkgName == fmt.Sprintf("%s/%s", modulePath, pattern) || pkgName == pattern {
+			return limits
+		}
+	}
+
+	return o.limits
+}
+
+// testTiming is the elapsed time of one test or subtest.
+type testTiming struct {
+	pkg     string
+	name    string
+	elapsed time.Duration
+}
+
+// testTimings returns the finished tests of a run in event order.
+func testTimings(events []testrun.Event) []testTiming {
+	var timings []testTiming
+
+	for _, event := range events {
+		if event.Test == "" || (event.Action != "pass" && event.Action != "fail") {
+			continue
+		}
+
+		timings = append(timings, testTiming{
+			pkg:     event.Package,
+			name:    event.Test,
+			elapsed: time.Duration(event.Elapsed * float64(time.Second)),
+		})
+	}
+
+	return timings
+}
+
+// slowTestViolations reports tests and subtests running longer than the
+// max_test_duration of their package. A test is left out when one of its
+// subtests is reported, as the parent's time includes it.
+func slowTestViolations(idx *fileIndex, events []testrun.Event, modulePath string, opts testDurationOptions) []Violation {
+	var slow []testTiming
+
+	for _, timing := range testTimings(events) {
+		limit := opts.limitsFor(timing.pkg, modulePath).maxTestDuration
+		if limit > 0 && timing.elapsed > limit {
+			slow = append(slow, timing)
+		}
+	}
+
+	var violations []Violation
+
+	for _, timing := range slow {
+		prefix := fmt.Sprintf("%s/", timing.name)
+		if slices.ContainsFunc(slow, func(other testTiming) bool {
+			return other.pkg == timing.pkg && strings.HasPrefix(other.name, prefix)
+		}) {
+			continue
+		}
+
+		file, line := testLocation(idx, packageToDir(timing.pkg, modulePath), timing.name)
+
+		violations = append(violations, Violation{
+			Rule:       RuleTestDuration,
+			File:       file,
+			Line:       line,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("test '%s' took %s (maximum %s)", timing.name, timing.elapsed, opts.limitsFor(timing.pkg, modulePath).maxTestDuration),
+			Suggestion: "speed the test up or split it",
+		})
+	}
+
+	return violations
+}
+
+// testLocation finds the declaration of the top-level test of name among the
+// indexed test files of dir, falling back to the package directory.
+func testLocation(idx *fileIndex, dir, name string) (string, int) {
+	top, _, _ := strings.Cut(name, "/")
+
+	for _, file := range idx.files {
+		if !file.isTest() || filepath.Dir(file.path) != dir {
+			continue
+		}
+
+		node := file.parsed()
+		if node == nil {
+			continue
+		}
+
+		for _, decl := range node.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == top {
+				return file.path, file.fset.Position(fn.Name.Pos()).Line
+			}
+		}
+	}
+
+	return dir, 0
+}
+
+// slowestTests returns the n slowest top-level tests, slowest first.
+func slowestTests(events []testrun.Event, n int) []testTiming {
+	var timings []testTiming
+
+	for _, timing := range testTimings(events) {
+		if !strings.Contains(timing.name, "/") {
+			timings = append(timings, timing)
+		}
+	}
+
+	slices.SortStableFunc(timings, func(a, b testTiming) int {
+		return cmp.Compare(b.elapsed, a.elapsed)
+	})
+
+	return timings[:min(n, len(timings))]
+}
+
+// logSlowestTests logs the n slowest top-level tests of a run.
+func logSlowestTests(events []testrun.Event, modulePath string, n int) {
+	timings := slowestTests(events, n)
+	if len(timings) == 0 {
+		return
+	}
+
+	log.Printf("Slowest %d tests:", len(timings))
+
+	for _, timing := range timings {
+		log.Printf("  %10s  %s %s", timing.elapsed, packageToDir(timing.pkg, modulePath), timing.name)
+	}
+}
@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+	"github.com/vitalvas/yake/internal/testrun"
+)
+
+const slowTestEvents = `{"Action":"run","Package":"example.com/fx/store","Test":"TestOpen"}
+{"Action":"pass","Package":"example.com/fx/store","Test":"TestOpen/empty","Elapsed":0.1}
+{"Action":"pass","Package":"example.com/fx/store","Test":"TestOpen/remote","Elapsed":6.5}
+{"Action":"pass","Package":"example.com/fx/store","Test":"TestOpen","Elapsed":6.6}
+{"Action":"fail","Package":"example.com/fx/store","Test":"TestSave","Elapsed":3.2}
+{"Action":"pass","Package":"example.com/fx/store","Elapsed":9.9}
+{"Action":"pass","Package":"example.com/fx/db","Test":"TestMigrate","Elapsed":4.0}
+{"Action":"pass","Package":"example.com/fx/db","Elapsed":4.1}
+`
+
+func Test_slowTestViolations(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.MkdirAll("store", 0755))
+	require.NoError(t, os.WriteFile("store/store_test.go", []byte("package store\n\nimport \"testing\"\n\nfunc TestSave(t *testing.T) {}\n\nfunc TestOpen(t *testing.T) {}\n"), 0644))
+	require.NoError(t, os.MkdirAll("db", 0755))
+	require.NoError(t, os.WriteFile("db/db_test.go", []byte("package db\n\nimport \"testing\"\n\nfunc TestMigrate(t *testing.T) {}\n"), 0644))
+
+	// Excluded files are not indexed, so their tests fall back to the
+	// package directory.
+	idx, err := buildFileIndex(".", 1, []string{"db"})
+	require.NoError(t, err)
+
+	events := testrun.ParseEvents([]byte(slowTestEvents))
+
+	t.Run("reports the innermost slow tests at their declaration", func(t *testing.T) {
+		opts := testDurationOptions{limits: durationLimits{maxDuration: 10 * time.Second, maxTestDuration: 3 * time.Second}}
+
+		assert.Equal(t, []string{
+			"store/store_test.go:7: test 'TestOpen/remote' took 6.5s (maximum 3s)",
+			"store/store_test.go:5: test 'TestSave' took 3.2s (maximum 3s)",
+			"db: test 'TestMigrate' took 4s (maximum 3s)",
+		}, violationStrings(slowTestViolations(idx, events, "example.com/fx", opts)))
+	})
+
+	t.Run("applies package overrides", func(t *testing.T) {
+		opts := testDurationOptions{
+			limits: durationLimits{maxTestDuration: 3 * time.Second},
+			overrides: map[string]durationLimits{
+				"store": {maxTestDuration: 10 * time.Second},
+			},
+		}
+
+		assert.Equal(t, []string{
+			"db: test 'TestMigrate' took 4s (maximum 3s)",
+		}, violationStrings(slowTestViolations(idx, events, "example.com/fx", opts)))
+	})
+
+	t.Run("is off without max_test_duration", func(t *testing.T) {
+		assert.Empty(t, slowTestViolations(idx, events, "example.com/fx", resolveTestDurationOptions(nil)))
+	})
+}
+
+func Test_slowestTests(t *testing.T) {
+	events := testrun.ParseEvents([]byte(slowTestEvents))
+
+	assert.Equal(t, []testTiming{
+		{pkg: "example.com/fx/store", name: "TestOpen", elapsed: 6600 * time.Millisecond},
+		{pkg: "example.com/fx/db", name: "TestMigrate", elapsed: 4 * time.Second},
+	}, slowestTests(events, 2))
+	assert.Len(t, slowestTests(events, 10), 3)
+	assert.Empty(t, slowestTests(nil, 5))
+}
+
+func Test_logSlowestTests(t *testing.T) {
+	var out bytes.Buffer
+
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	logSlowestTests(testrun.ParseEvents([]byte(slowTestEvents)), "example.com/fx", 1)
+
+	assert.Contains(t, out.String(), "Slowest 1 tests:")
+	assert.Contains(t, out.String(), "6.6s  store TestOpen")
+
+	out.Reset()
+	logSlowestTests(nil, "example.com/fx", 1)
+	assert.Empty(t, out.String())
+}
+
+func Test_resolveTestDurationOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		assert.Equal(t, testDurationOptions{
+			limits: durationLimits{maxDuration: config.DefaultMaxTestDuration},
+		}, resolveTestDurationOptions(nil))
+	})
+
+	t.Run("overrides inherit unset limits", func(t *testing.T) {
+		slowest := 5
+
+		opts := resolveTestDurationOptions(&config.TestDurationPolicy{
+			MaxDuration:     stringPtr("20s"),
+			MaxTestDuration: stringPtr("2s"),
+			SlowestTests:    &slowest,
+			PackageOverrides: map[string]config.TestDurationOverride{
+				"internal/db": {MaxTestDuration: stringPtr("8s")},
+			},
+		})
+
+		assert.Equal(t, testDurationOptions{
+			limits:       durationLimits{maxDuration: 20 * time.Second, maxTestDuration: 2 * time.Second},
+			slowestTests: 5,
+			overrides: map[string]durationLimits{
+				"internal/db": {maxDuration: 20 * time.Second, maxTestDuration: 8 * time.Second},
+			},
+		}, opts)
+		assert.Equal(t, 8*time.Second, opts.limitsFor("example.com/fx/internal/db", "example.com/fx").maxTestDuration)
+		assert.Equal(t, 2*time.Second, opts.limitsFor("example.com/fx/internal/api", "example.com/fx").maxTestDuration)
+	})
+}
+
+func Test_parseDurationOr(t *testing.T) {
+	assert.Equal(t, time.Minute, parseDurationOr(nil, time.Minute))
+	assert.Equal(t, time.Minute, parseDurationOr(stringPtr("soon"), time.Minute))
+	assert.Equal(t, 3*time.Second, parseDurationOr(stringPtr("3s"), time.Minute))
+}
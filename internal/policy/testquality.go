@@ -0,0 +1,283 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"log"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+// testFailures are the testing methods a testify assertion replaces.
+var testFailures = []string{"Error", "Errorf", "Fatal", "Fatalf", "Fail", "FailNow"}
+
+// testQualityOptions holds the resolved test_quality section.
+type testQualityOptions struct {
+	requireParallel bool
+	requireTestify  bool
+}
+
+func resolveTestQualityOptions(p *config.TestQualityPolicy) testQualityOptions {
+	if p == nil {
+		return testQualityOptions{}
+	}
+
+	return testQualityOptions{
+		requireParallel: p.RequireParallel != nil && *p.RequireParallel,
+		requireTestify:  p.RequireTestify != nil && *p.RequireTestify,
+	}
+}
+
+func checkTestQuality(idx *fileIndex, opts testQualityOptions) ([]Violation, error) {
+	log.Println("Checking test quality...")
+
+	violations := idx.collect(idx.files, func(file *sourceFile) []Violation {
+		return findTestQualityViolations(file, opts)
+	})
+
+	return violations, nil
+}
+
+// findTestQualityViolations reports helpers without t.Helper(), os.Setenv,
+// t.Skip without a reason and, when required, tests not calling t.Parallel()
+// first and failures raised from an if statement instead of testify.
+func findTestQualityViolations(file *sourceFile, opts testQualityOptions) []Violation {
+	if !file.isTest() {
+		return nil
+	}
+
+	node := file.parsed()
+	if node == nil {
+		return nil
+	}
+
+	if file.skip {
+		return nil
+	}
+
+	c := &testChecker{
+		file:    file,
+		testing: importedName(node, "testing"),
+		os:      importedName(node, "os"),
+		opts:    opts,
+		vars:    make(map[string]bool),
+	}
+
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || hasFuncSkipDirective(fn) {
+			continue
+		}
+
+		c.checkFunc(fn)
+		ast.Inspect(fn, c.visit)
+	}
+
+	return c.violations
+}
+
+type testChecker struct {
+	file *sourceFile
+	// testing and os are the names of the imported packages, if any.
+	testing string
+	os      string
+	opts    testQualityOptions
+	// vars holds the names of the *testing.T, *testing.B, *testing.F and
+	// testing.TB parameters seen so far.
+	vars       map[string]bool
+	violations []Violation
+}
+
+func (c *testChecker) report(n ast.Node, message, suggestion string) {
+	pos := c.file.fset.Position(n.Pos())
+	c.violations = append(c.violations, Violation{
+		Rule:       RuleTestQuality,
+		File:       c.file.path,
+		Line:       pos.Line,
+		Column:     pos.Column,
+		Severity:   SeverityError,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+// isTestFunc reports whether name is run by go test: TestXxx, BenchmarkXxx,
+// FuzzXxx or ExampleXxx, where Xxx does not start with a lower case letter.
+func isTestFunc(name string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+
+		first, _ := utf8.DecodeRuneInString(rest)
+		if rest == "" || !unicode.IsLower(first) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// testingParam returns the first named parameter of a testing type, or nil.
+func (c *testChecker) testingParam(ft *ast.FuncType) *ast.Ident {
+	for _, field := range ft.Params.List {
+		if !c.isTestingType(field.Type) {
+			continue
+		}
+
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				return name
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *testChecker) isTestingType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		sel, ok := star.X.(*ast.SelectorExpr)
+		return ok && isPackageSelector(sel, c.testing) && slices.Contains([]string{"T", "B", "F"}, sel.Sel.Name)
+	}
+
+	sel, ok := expr.(*ast.SelectorExpr)
+
+	return ok && isPackageSelector(sel, c.testing) && sel.Sel.Name == "TB"
+}
+
+// isMethodCall reports whether stmt calls method on the named variable.
+func isMethodCall(stmt ast.Stmt, recv, method string) bool {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+
+	call, ok := expr.X.(*ast.CallExpr)
+
+	return ok && isCall(call, recv, method)
+}
+
+func (c *testChecker) checkFunc(fn *ast.FuncDecl) {
+	param := c.testingParam(fn.Type)
+	if param == nil {
+		return
+	}
+
+	if fn.Recv == nil && isTestFunc(fn.Name.Name) {
+		if c.opts.requireParallel && strings.HasPrefix(fn.Name.Name, "Test") && !c.changesProcess(fn.Body) &&
+			(len(fn.Body.List) == 0 || !isMethodCall(fn.Body.List[0], param.Name, "Parallel")) {
+			c.report(fn.Name, fmt.Sprintf("test '%s' does not call %s.Parallel() first", fn.Name.Name, param.Name), "start the test with t.Parallel()")
+		}
+
+		return
+	}
+
+	if !slices.ContainsFunc(fn.Body.List, func(stmt ast.Stmt) bool { return isMethodCall(stmt, param.Name, "Helper") }) {
+		c.report(fn.Name, fmt.Sprintf("helper '%s' does not call %s.Helper()", fn.Name.Name, param.Name), "call t.Helper() first so failures point at the caller")
+	}
+}
+
+// changesProcess reports whether a test changes the environment or working
+// directory, which t.Parallel() forbids.
+func (c *testChecker) changesProcess(body *ast.BlockStmt) bool {
+	changes := false
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && (sel.Sel.Name == "Setenv" || sel.Sel.Name == "Chdir") {
+			changes = true
+		}
+
+		return !changes
+	})
+
+	return changes
+}
+
+func (c *testChecker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.FuncType:
+		if param := c.testingParam(n); param != nil {
+			c.vars[param.Name] = true
+		}
+	case *ast.CallExpr:
+		c.checkCall(n)
+	case *ast.IfStmt:
+		c.checkIf(n)
+	}
+
+	return true
+}
+
+// isTestingVar reports whether expr is a testing parameter: by type when
+// known, otherwise by the names of the parameters seen.
+func (c *testChecker) isTestingVar(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	if c.file.typed != nil {
+		obj, ok := c.file.typed.info.Uses[ident].(*types.Var)
+		if !ok {
+			return false
+		}
+
+		typ := obj.Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+
+		named, ok := typ.(*types.Named)
+
+		return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "testing"
+	}
+
+	return c.vars[ident.Name]
+}
+
+func (c *testChecker) checkCall(call *ast.CallExpr) {
+	if isCall(call, c.os, "Setenv") {
+		c.report(call, fmt.Sprintf("%s in a test leaks into the tests after it", types.ExprString(call.Fun)), "use t.Setenv, which restores the variable")
+		return
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !c.isTestingVar(sel.X) {
+		return
+	}
+
+	if sel.Sel.Name == "SkipNow" || sel.Sel.Name == "Skip" && len(call.Args) == 0 {
+		c.report(call, fmt.Sprintf("%s without a reason", types.ExprString(sel)), "pass the reason to t.Skip")
+	}
+}
+
+// checkIf reports "if cond { t.Fatalf(...) }" when testify is required.
+func (c *testChecker) checkIf(stmt *ast.IfStmt) {
+	if !c.opts.requireTestify || stmt.Else != nil || len(stmt.Body.List) != 1 {
+		return
+	}
+
+	expr, ok := stmt.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return
+	}
+
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !slices.Contains(testFailures, sel.Sel.Name) || !c.isTestingVar(sel.X) {
+		return
+	}
+
+	c.report(stmt, fmt.Sprintf("'if' calling %s instead of a testify assertion", types.ExprString(sel)), "use require or assert from testify")
+}
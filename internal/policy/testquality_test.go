@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/yake/internal/config"
+)
+
+const testQualitySource = `package store
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	os.Setenv("STORE", "1")
+
+	if got := load(); got != 1 {
+		t.Fatalf("got %d", got)
+	}
+
+	t.Run("sub", func(st *testing.T) {
+		st.Skip()
+	})
+}
+
+func TestSave(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("slow")
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("STORE", "1")
+}
+
+func TestPlain(t *testing.T) {}
+
+func Testable(t *testing.T) {}
+
+func newStore(tb testing.TB) {
+	tb.SkipNow()
+}
+
+func assertLoaded(t *testing.T) {
+	t.Helper()
+}
+
+func load() int { return 1 }
+`
+
+func Test_findTestQualityViolations(t *testing.T) {
+	file := parseSourceFile(token.NewFileSet(), "store_test.go", []byte(testQualitySource))
+	require.NoError(t, file.parseErr)
+
+	t.Run("reports helpers, os.Setenv and skips without reason", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"store_test.go:9:2: os.Setenv in a test leaks into the tests after it",
+			"store_test.go:16:3: st.Skip without a reason",
+			"store_test.go:34:6: helper 'Testable' does not call t.Helper()",
+			"store_test.go:36:6: helper 'newStore' does not call tb.Helper()",
+			"store_test.go:37:2: tb.SkipNow without a reason",
+		}, violationStrings(findTestQualityViolations(file, testQualityOptions{})))
+	})
+
+	t.Run("requires t.Parallel and testify when configured", func(t *testing.T) {
+		violations := violationStrings(findTestQualityViolations(file, testQualityOptions{
+			requireParallel: true,
+			requireTestify:  true,
+		}))
+
+		assert.Len(t, violations, 7)
+		assert.Contains(t, violations, "store_test.go:32:6: test 'TestPlain' does not call t.Parallel() first")
+		assert.Contains(t, violations, "store_test.go:11:2: 'if' calling t.Fatalf instead of a testify assertion")
+	})
+
+	t.Run("skips non-test files", func(t *testing.T) {
+		source := parseSourceFile(token.NewFileSet(), "store.go", []byte(testQualitySource))
+		assert.Empty(t, findTestQualityViolations(source, testQualityOptions{}))
+	})
+}
+
+func Test_isTestFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Test", true},
+		{"TestLoad", true},
+		{"Test_load", true},
+		{"BenchmarkLoad", true},
+		{"FuzzParse", true},
+		{"ExampleStore", true},
+		{"Testable", false},
+		{"helper", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTestFunc(tt.name))
+		})
+	}
+}
+
+func Test_resolveTestQualityOptions(t *testing.T) {
+	assert.Equal(t, testQualityOptions{}, resolveTestQualityOptions(nil))
+	assert.Equal(t, testQualityOptions{requireParallel: true}, resolveTestQualityOptions(&config.TestQualityPolicy{
+		RequireParallel: boolPtr(true),
+		RequireTestify:  boolPtr(false),
+	}))
+}
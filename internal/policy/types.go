@@ -0,0 +1,370 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typedPackage is the type information of one loaded package, shared by its
+// files.
+type typedPackage struct {
+	info *types.Info
+	// interfaces indexes by method name the method-set interfaces of the
+	// whole load, shared by every package of it.
+	interfaces map[string][]*types.Interface
+}
+
+const typesLoadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedTypes |
+	packages.NeedSyntax |
+	packages.NeedTypesInfo
+
+// loadTypes type-checks the project packages, tests included, and attaches
+// the result to the indexed files. The packages reuse the index syntax trees,
+// so type information can be looked up for the nodes the checks walk. Files
+// outside the loaded packages, or in packages that do not type-check, keep
+// the syntax-only analysis.
+func (idx *fileIndex) loadTypes(tags []string) error {
+	log.Println("Loading packages with type information...")
+
+	byPath := make(map[string]*sourceFile, len(idx.files))
+	byNode := make(map[*ast.File]*sourceFile, len(idx.files))
+	overlay := make(map[string][]byte, len(idx.files))
+
+	for _, file := range idx.files {
+		abs, err := filepath.Abs(file.path)
+		if err != nil {
+			return fmt.Errorf("failed to load packages: %w", err)
+		}
+
+		byPath[abs] = file
+
+		// Only contents that differ from the disk, such as fixes not yet
+		// written, go into the overlay: overlaid packages are rebuilt on
+		// every load.
+		if disk, err := os.ReadFile(file.path); err != nil || !bytes.Equal(disk, file.src) {
+			overlay[abs] = file.src
+		}
+
+		if node := file.parsed(); node != nil {
+			byNode[node] = file
+		}
+	}
+
+	var buildFlags []string
+	if len(tags) > 0 {
+		buildFlags = []string{fmt.Sprintf("-tags=%s", strings.Join(tags, ","))}
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:       typesLoadMode,
+		Tests:      true,
+		Fset:       idx.fset,
+		BuildFlags: buildFlags,
+		Overlay:    overlay,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			if file, ok := byPath[filename]; ok && file.parsed() != nil {
+				return file.node, nil
+			}
+
+			return parser.ParseFile(fset, filename, src, parser.ParseComments)
+		},
+	}, "./...")
+	if err != nil {
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var (
+		roots []*types.Package
+		infos []*types.Info
+	)
+
+	for _, pkg := range pkgs {
+		if pkg.Types != nil && pkg.TypesInfo != nil {
+			roots = append(roots, pkg.Types)
+			infos = append(infos, pkg.TypesInfo)
+		}
+	}
+
+	interfaces := collectInterfaces(roots, infos)
+
+	// Plain packages go first so source files get the type information of
+	// the package they are built into rather than of its test variant.
+	for _, plain := range []bool{true, false} {
+		for _, pkg := range pkgs {
+			if (pkg.ID == pkg.PkgPath) != plain {
+				continue
+			}
+
+			if len(pkg.Errors) > 0 {
+				log.Printf("Type information unavailable for %s: %v", pkg.ID, pkg.Errors[0])
+				continue
+			}
+
+			typed := &typedPackage{
+				info:       pkg.TypesInfo,
+				interfaces: interfaces,
+			}
+
+			for _, node := range pkg.Syntax {
+				if file, ok := byNode[node]; ok && file.typed == nil {
+					file.typed = typed
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectInterfaces indexes by method name the non-generic method-set
+// interfaces a type may implement on purpose: error, the named interfaces of
+// the given packages and of all packages they import, and the interface
+// literals used in the given packages.
+func collectInterfaces(pkgs []*types.Package, infos []*types.Info) map[string][]*types.Interface {
+	seen := make(map[*types.Interface]bool)
+	interfaces := make(map[string][]*types.Interface)
+
+	add := func(t types.Type) {
+		iface, ok := t.Underlying().(*types.Interface)
+		if !ok || !iface.IsMethodSet() || seen[iface] {
+			return
+		}
+
+		seen[iface] = true
+
+		for method := range iface.Methods() {
+			interfaces[method.Name()] = append(interfaces[method.Name()], iface)
+		}
+	}
+
+	add(types.Universe.Lookup("error").Type())
+
+	visited := make(map[*types.Package]bool)
+
+	var visit func(p *types.Package)
+
+	visit = func(p *types.Package) {
+		if p == nil || visited[p] {
+			return
+		}
+
+		visited[p] = true
+
+		scope := p.Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+
+			if named, ok := typeName.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+				continue
+			}
+
+			add(typeName.Type())
+		}
+
+		for _, imported := range p.Imports() {
+			visit(imported)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+
+	for _, info := range infos {
+		for _, tv := range info.Types {
+			if tv.IsType() {
+				add(tv.Type)
+			}
+		}
+	}
+
+	return interfaces
+}
+
+// implementsMethod reports whether the method is required by an interface
+// that the receiver's type, or a pointer to it, implements.
+func (t *typedPackage) implementsMethod(fn *ast.FuncDecl) bool {
+	base := t.receiverType(fn)
+	if base == nil {
+		return false
+	}
+
+	for _, iface := range t.interfaces[fn.Name.Name] {
+		if types.Implements(base, iface) || types.Implements(types.NewPointer(base), iface) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// receiverType returns the receiver type of a method without its pointer.
+func (t *typedPackage) receiverType(fn *ast.FuncDecl) types.Type {
+	obj, ok := t.info.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return nil
+	}
+
+	recv := obj.Signature().Recv()
+	if recv == nil {
+		return nil
+	}
+
+	if ptr, ok := recv.Type().(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+
+	return recv.Type()
+}
+
+// isString reports whether expr has a string type.
+func (t *typedPackage) isString(expr ast.Expr) bool {
+	tv, ok := t.info.Types[expr]
+	if !ok || tv.Type == nil {
+		return false
+	}
+
+	basic, ok := tv.Type.Underlying().(*types.Basic)
+
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// isPlainString reports whether expr has the predeclared string type rather
+// than a named string type.
+func (t *typedPackage) isPlainString(expr ast.Expr) bool {
+	tv, ok := t.info.Types[expr]
+
+	return ok && tv.Type != nil && types.Identical(tv.Type, types.Typ[types.String])
+}
+
+// hasStringMethod reports whether formatting expr with %s would call a
+// String or Error method instead of printing the string itself.
+func (t *typedPackage) hasStringMethod(expr ast.Expr) bool {
+	tv, ok := t.info.Types[expr]
+	if !ok || tv.Type == nil {
+		return false
+	}
+
+	methods := types.NewMethodSet(tv.Type)
+
+	return methods.Lookup(nil, "String") != nil || methods.Lookup(nil, "Error") != nil
+}
+
+// isStdlibCall reports whether call invokes a function of an imported
+// standard library package, as opposed to a method of a variable shadowing
+// the package name or a type conversion.
+func (t *typedPackage) isStdlibCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	pkgName, ok := t.info.Uses[ident].(*types.PkgName)
+	if !ok {
+		return false
+	}
+
+	if _, ok := t.info.Uses[sel.Sel].(*types.Func); !ok {
+		return false
+	}
+
+	return isStdlibImport(pkgName.Imported().Path())
+}
+
+// forwardsParams reports whether call passes every parameter of fn on as a
+// plain argument, matching parameters by object rather than by name.
+func (t *typedPackage) forwardsParams(fn *ast.FuncDecl, call *ast.CallExpr) bool {
+	paramCount := countFields(fn.Type.Params)
+	if paramCount == 0 {
+		return len(call.Args) == 0
+	}
+
+	params := make(map[types.Object]bool, paramCount)
+
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			if obj := t.info.Defs[name]; obj != nil && name.Name != "_" {
+				params[obj] = false
+			}
+		}
+	}
+
+	// Unnamed or blank parameters cannot be forwarded.
+	if len(params) != paramCount {
+		return false
+	}
+
+	for _, arg := range call.Args {
+		ident, ok := arg.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		if obj := t.info.Uses[ident]; obj != nil {
+			if _, isParam := params[obj]; isParam {
+				params[obj] = true
+			}
+		}
+	}
+
+	for _, used := range params {
+		if !used {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isError reports whether expr has a type that implements error.
+func (t *typedPackage) isError(expr ast.Expr) bool {
+	tv, ok := t.info.Types[expr]
+	if !ok || tv.Type == nil || tv.IsNil() {
+		return false
+	}
+
+	return implementsError(tv.Type)
+}
+
+// resultIsError reports whether result i of call, a call assigned to n
+// values, is an error.
+func (t *typedPackage) resultIsError(call *ast.CallExpr, i, n int) bool {
+	tv, ok := t.info.Types[call]
+	if !ok || tv.Type == nil {
+		return false
+	}
+
+	if tuple, ok := tv.Type.(*types.Tuple); ok {
+		return i < tuple.Len() && implementsError(tuple.At(i).Type())
+	}
+
+	return n == 1 && implementsError(tv.Type)
+}
+
+func implementsError(typ types.Type) bool {
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+	return types.Implements(typ, errorType)
+}
@@ -0,0 +1,184 @@
+package policy
+
+import (
+	"go/ast"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// typedProject is a module whose checks give different results with and
+// without type information.
+var typedProject = map[string]string{
+	"lib/lib.go": `package lib
+
+import (
+	"strings"
+	"time"
+)
+
+type Label string
+
+func (Label) String() string { return "label" }
+
+type Namer interface{ GetName() string }
+
+type Repo struct{ name string }
+
+func (r *Repo) GetName() string { return r.name }
+
+func (r *Repo) GetID() int { return 1 }
+
+type describer interface{ Describe() string }
+
+type entry struct{}
+
+func (entry) Describe() string { return "entry" }
+
+func (entry) Accept() int { return 1 }
+
+func (item) Size() int { return 1 }
+
+type failure struct{}
+
+func (failure) Error() string { return "failure" }
+
+var _ describer = entry{}
+
+func Join(a, b string) string {
+	return a + b
+}
+
+func Sum(a, b int) int {
+	return a + b
+}
+
+func Tag(l Label) Label {
+	return "tag " + l
+}
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func Twice(a, b string) bool {
+	return strings.EqualFold(a, a)
+}
+
+func Seconds(n int64) time.Duration {
+	return time.Duration(n)
+}
+`,
+	"lib/item.go":        "package lib\n\ntype item struct{}\n",
+	"lib/lib_test.go":    "package lib\n\nfunc helper(a, b string) string { return a + b }\n",
+	"lib/integration.go": "//go:build integration\n\npackage lib\n\nfunc Extra(a, b string) string { return a + b }\n",
+	"broken/broken.go":   "package broken\n\nfunc Join(a, b string) string { return a + b + missing }\n",
+}
+
+// loadTypedFiles indexes the project in the current directory with type
+// information and returns its files by slash path.
+func loadTypedFiles(t *testing.T, tags []string) map[string]*sourceFile {
+	t.Helper()
+
+	idx, err := buildFileIndex(".", 2, nil)
+	require.NoError(t, err)
+	require.NoError(t, idx.loadTypes(tags))
+
+	files := make(map[string]*sourceFile, len(idx.files))
+	for _, file := range idx.files {
+		files[filepath.ToSlash(file.path)] = file
+	}
+
+	return files
+}
+
+func messages(violations []Violation) []string {
+	var result []string
+	for _, v := range violations {
+		result = append(result, v.Message)
+	}
+
+	return result
+}
+
+func TestFileIndex_loadTypes(t *testing.T) {
+	writeFixProject(t, typedProject)
+
+	files := loadTypedFiles(t, []string{"integration"})
+	lib := files["lib/lib.go"]
+
+	t.Run("attaches types to package, test and tagged files", func(t *testing.T) {
+		require.NotNil(t, lib.typed)
+		assert.NotNil(t, files["lib/lib_test.go"].typed)
+		assert.NotNil(t, files["lib/integration.go"].typed)
+	})
+
+	t.Run("leaves packages with type errors syntax-only", func(t *testing.T) {
+		assert.Nil(t, files["broken/broken.go"].typed)
+		assert.Empty(t, findStringConcatenations(files["broken/broken.go"]))
+	})
+
+	t.Run("flags every string addition", func(t *testing.T) {
+		violations := findStringConcatenations(lib)
+
+		require.Len(t, violations, 2)
+		assert.Equal(t, 37, violations[0].Line)
+		assert.Equal(t, 45, violations[1].Line)
+		assert.Len(t, findStringConcatenations(files["lib/integration.go"]), 1)
+	})
+
+	t.Run("skips getters required by implemented interfaces", func(t *testing.T) {
+		assert.Equal(t, []string{"method 'GetID' should be 'ID'"}, messages(findGetterViolations(lib)))
+	})
+
+	t.Run("checks private receivers by implemented interfaces", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"exported method 'Accept' on private struct 'entry'",
+			"exported method 'Size' on private struct 'item'",
+		}, messages(findPrivateExportedMethodViolations(lib)))
+	})
+
+	t.Run("matches wrappers by package and parameter objects", func(t *testing.T) {
+		assert.Equal(t, []string{"function 'Upper' is a wrapper around 'strings.ToUpper'"}, messages(findStdlibWrappers(lib)))
+	})
+
+	t.Run("skips fixes that would call a String method", func(t *testing.T) {
+		var concats []*ast.BinaryExpr
+
+		ast.Inspect(lib.node, func(n ast.Node) bool {
+			if binExpr, ok := n.(*ast.BinaryExpr); ok && isStringConcat(lib, binExpr) {
+				concats = append(concats, binExpr)
+			}
+
+			return true
+		})
+
+		require.Len(t, concats, 2)
+
+		text, sprintf, ok := concatReplacement(lib, concats[0], "fmt")
+		assert.True(t, ok)
+		assert.True(t, sprintf)
+		assert.Equal(t, `fmt.Sprintf("%s%s", a, b)`, text)
+
+		_, _, ok = concatReplacement(lib, concats[1], "fmt")
+		assert.False(t, ok)
+	})
+}
+
+func Test_attachTypes(t *testing.T) {
+	t.Run("falls back to syntax-only checks when loading fails", func(t *testing.T) {
+		writeFixProject(t, typedProject)
+		t.Setenv("PATH", t.TempDir())
+
+		idx, err := buildFileIndex(".", 2, nil)
+		require.NoError(t, err)
+
+		attachTypes(idx, nil)
+
+		for _, file := range idx.files {
+			assert.Nil(t, file.typed, file.path)
+		}
+	})
+}
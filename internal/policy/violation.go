@@ -0,0 +1,190 @@
+package policy
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rule identifiers match the policy section names in .yake.yaml.
+const (
+	RuleEntryPoints            = "entry_points"
+	RulePackageNaming          = "package_naming"
+	RuleASCIIOnly              = "ascii_only"
+	RuleStringConcat           = "string_concat"
+	RuleStdlibWrappers         = "stdlib_wrappers"
+	RuleFuncSignature          = "func_signature"
+	RuleComplexity             = "complexity"
+	RuleCompositeLiteral       = "composite_literal"
+	RuleStuttering             = "stuttering"
+	RuleGetterNaming           = "getter_naming"
+	RulePrivateExportedMethods = "private_exported_methods"
+	RuleNoInit                 = "no_init"
+	RuleErrorHandling          = "error_handling"
+	RuleContext                = "context"
+	RuleTermination            = "termination"
+	RuleConcurrency            = "concurrency"
+	RuleImports                = "imports"
+	RuleTestFileNaming         = "test_file_naming"
+	RuleTestQuality            = "test_quality"
+	RuleTestDuration           = "test_duration"
+	RuleCoverage               = "coverage"
+	RuleSuppressions           = "suppressions"
+)
+
+// ruleIDs lists every rule in the order the checks run. Suppressions are
+// checked last, once every other rule has reported.
+var ruleIDs = []string{
+	RuleEntryPoints,
+	RulePackageNaming,
+	RuleASCIIOnly,
+	RuleStringConcat,
+	RuleStdlibWrappers,
+	RuleFuncSignature,
+	RuleComplexity,
+	RuleCompositeLiteral,
+	RuleStuttering,
+	RuleGetterNaming,
+	RulePrivateExportedMethods,
+	RuleNoInit,
+	RuleErrorHandling,
+	RuleContext,
+	RuleTermination,
+	RuleConcurrency,
+	RuleImports,
+	RuleTestFileNaming,
+	RuleTestQuality,
+	RuleTestDuration,
+	RuleCoverage,
+	RuleSuppressions,
+}
+
+// Severity classifies how a violation affects the policy run.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Violation is a single policy finding. File is relative to the project root;
+// package-level findings (coverage, test duration) point at the package
+// directory and leave Line and Column at zero.
+type Violation struct {
+	Rule       string   `json:"rule"`
+	File       string   `json:"file"`
+	Line       int      `json:"line,omitempty"`
+	Column     int      `json:"column,omitempty"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// Location formats the violation position as file[:line[:column]].
+func (v Violation) Location() string {
+	switch {
+	case v.Line > 0 && v.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", v.File, v.Line, v.Column)
+	case v.Line > 0:
+		return fmt.Sprintf("%s:%d", v.File, v.Line)
+	default:
+		return v.File
+	}
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Location(), v.Message)
+}
+
+// ruleTitles holds the heading printed above each rule's violations.
+var ruleTitles = map[string]string{
+	RuleEntryPoints:            "entry point violations",
+	RulePackageNaming:          "package naming violations",
+	RuleASCIIOnly:              "ASCII-only violations (use only ASCII characters in non-test Go source)",
+	RuleStringConcat:           "string concatenation violations (use fmt.Sprintf or strings.Builder)",
+	RuleStdlibWrappers:         "stdlib wrapper violations (do not wrap standard library functions)",
+	RuleFuncSignature:          "function signature violations (use struct-based config)",
+	RuleComplexity:             "complexity violations (split complex functions)",
+	RuleCompositeLiteral:       "composite literal violations (each field must be on its own line)",
+	RuleStuttering:             "stuttering violations (exported names should not repeat the package name)",
+	RuleGetterNaming:           "getter naming violations (use Name() instead of GetName())",
+	RulePrivateExportedMethods: "private struct exported method violations (private structs should not have exported methods)",
+	RuleNoInit:                 "init() function violations (init() is forbidden; use explicit constructors or wire setup from main())",
+	RuleErrorHandling:          "error handling violations (wrap with %w, compare with errors.Is, name sentinels ErrXxx)",
+	RuleContext:                "context violations (take ctx as the first parameter and pass it on)",
+	RuleTermination:            "process termination violations (return errors and leave exiting to main)",
+	RuleConcurrency:            "concurrency violations (track goroutines, do not copy locks, no defer in loops)",
+	RuleImports:                "import dependency violations",
+	RuleTestFileNaming:         "test file naming violations",
+	RuleTestQuality:            "test quality violations (call t.Helper, use t.Setenv, give skip reasons)",
+	RuleTestDuration:           "test duration violations",
+	RuleCoverage:               "coverage violations",
+	RuleSuppressions:           "suppression violations (//yake:ignore directives must be used and name known rules)",
+}
+
+func ruleTitle(rule string) string {
+	if title, ok := ruleTitles[rule]; ok {
+		return title
+	}
+
+	return fmt.Sprintf("%s violations", rule)
+}
+
+// sortViolations orders violations by rule, in check order, and then by
+// location so reports do not depend on how checks were scheduled.
+func sortViolations(violations []Violation) {
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		return cmp.Or(
+			cmp.Compare(ruleOrder(a.Rule), ruleOrder(b.Rule)),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+}
+
+// ruleOrder returns the position of rule in ruleIDs; unknown rules sort last.
+func ruleOrder(rule string) int {
+	if i := slices.Index(ruleIDs, rule); i >= 0 {
+		return i
+	}
+
+	return len(ruleIDs)
+}
+
+// ViolationsError renders violations grouped by rule, in the order rules
+// first appear, and returns nil when there is nothing to report.
+func ViolationsError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	var rules []string
+
+	grouped := make(map[string][]Violation)
+
+	for _, v := range violations {
+		if _, ok := grouped[v.Rule]; !ok {
+			rules = append(rules, v.Rule)
+		}
+
+		grouped[v.Rule] = append(grouped[v.Rule], v)
+	}
+
+	sections := make([]string, 0, len(rules))
+
+	for _, rule := range rules {
+		lines := make([]string, 0, len(grouped[rule])+1)
+		lines = append(lines, fmt.Sprintf("%s:", ruleTitle(rule)))
+
+		for _, v := range grouped[rule] {
+			lines = append(lines, fmt.Sprintf("  - %s", v))
+		}
+
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	return fmt.Errorf("%s", strings.Join(sections, "\n"))
+}
@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitalvas/yake/internal/config"
+)
+
+func TestViolation(t *testing.T) {
+	t.Run("location with line and column", func(t *testing.T) {
+		v := Violation{File: "main.go", Line: 3, Column: 7, Message: "msg"}
+
+		assert.Equal(t, "main.go:3:7", v.Location())
+		assert.Equal(t, "main.go:3:7: msg", v.String())
+	})
+
+	t.Run("location with line only", func(t *testing.T) {
+		v := Violation{File: "main.go", Line: 3}
+
+		assert.Equal(t, "main.go:3", v.Location())
+	})
+
+	t.Run("location without position", func(t *testing.T) {
+		v := Violation{File: "internal/calc", Message: "no test files"}
+
+		assert.Equal(t, "internal/calc", v.Location())
+		assert.Equal(t, "internal/calc: no test files", v.String())
+	})
+}
+
+func Test_ruleTitle(t *testing.T) {
+	assert.Equal(t, "test file naming violations", ruleTitle(RuleTestFileNaming))
+	assert.Equal(t, "custom violations", ruleTitle("custom"))
+}
+
+func TestViolationsError(t *testing.T) {
+	t.Run("returns nil without violations", func(t *testing.T) {
+		assert.NoError(t, ViolationsError(nil))
+	})
+
+	t.Run("groups violations by rule in first-seen order", func(t *testing.T) {
+		violations := []Violation{
+			{Rule: RuleNoInit, File: "a.go", Line: 1, Message: "init() function is forbidden"},
+			{Rule: RuleStringConcat, File: "b.go", Line: 2, Column: 5, Message: "string concatenation with '+'"},
+			{Rule: RuleNoInit, File: "c.go", Line: 9, Message: "init() function is forbidden"},
+		}
+
+		err := ViolationsError(violations)
+		require.Error(t, err)
+
+		expected := strings.Join([]string{
+			"init() function violations (init() is forbidden; use explicit constructors or wire setup from main()):",
+			"  - a.go:1: init() function is forbidden",
+			"  - c.go:9: init() function is forbidden",
+			"string concatenation violations (use fmt.Sprintf or strings.Builder):",
+			"  - b.go:2:5: string concatenation with '+'",
+		}, "\n")
+		assert.Equal(t, expected, err.Error())
+	})
+}
+
+func Test_ruleIDs(t *testing.T) {
+	checks := golangPolicyChecks(&config.Config{})
+
+	// Every rule but the trailing suppressions rule is backed by a check.
+	require.Len(t, checks, len(ruleIDs)-1)
+	assert.Equal(t, RuleSuppressions, ruleIDs[len(ruleIDs)-1])
+
+	for i, check := range checks {
+		assert.Equal(t, ruleIDs[i], check.rule)
+		assert.Contains(t, ruleTitles, check.rule)
+	}
+
+	assert.Contains(t, ruleTitles, RuleSuppressions)
+}
+
+func Test_sortViolations(t *testing.T) {
+	violations := []Violation{
+		{Rule: "custom", File: "a.go"},
+		{Rule: RuleNoInit, File: "b.go", Line: 3},
+		{Rule: RuleStringConcat, File: "b.go", Line: 9, Column: 4},
+		{Rule: RuleNoInit, File: "a.go", Line: 7},
+		{Rule: RuleStringConcat, File: "b.go", Line: 9, Column: 2},
+		{Rule: RuleEntryPoints, File: "main.go"},
+	}
+
+	sortViolations(violations)
+
+	locations := make([]string, 0, len(violations))
+	for _, v := range violations {
+		locations = append(locations, fmt.Sprintf("%s %s", v.Rule, v.Location()))
+	}
+
+	assert.Equal(t, []string{
+		"entry_points main.go",
+		"string_concat b.go:9:2",
+		"string_concat b.go:9:4",
+		"no_init a.go:7",
+		"no_init b.go:3",
+		"custom a.go",
+	}, locations)
+}
+
+func Test_ruleOrder(t *testing.T) {
+	assert.Equal(t, 0, ruleOrder(RuleEntryPoints))
+	assert.Equal(t, len(ruleIDs)-1, ruleOrder(RuleSuppressions))
+	assert.Equal(t, len(ruleIDs), ruleOrder("custom"))
+}
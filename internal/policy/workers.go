@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultJobs is the worker count used when none is configured.
+func DefaultJobs() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// workerPool bounds how many goroutines analyze at once, shared by nested
+// parallel maps such as the checks and the files each check walks. The
+// goroutine that starts a map always works on it, so a pool of jobs holds
+// jobs-1 slots for the helpers it borrows. A nil pool runs maps serially.
+type workerPool struct {
+	slots chan struct{}
+}
+
+func newWorkerPool(jobs int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, max(jobs, 1)-1)}
+}
+
+// tryAcquire takes a free helper slot without waiting, so a map nested in a
+// busy pool keeps going on its caller instead of blocking on itself.
+func (p *workerPool) tryAcquire() bool {
+	if p == nil {
+		return false
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *workerPool) release() {
+	<-p.slots
+}
+
+// parallelMap applies fn to every item on the calling goroutine and on
+// helpers borrowed from pool while items remain, and returns the results in
+// input order, so callers get the same output no matter how the work was
+// scheduled.
+func parallelMap[T, R any](pool *workerPool, items []T, fn func(T) R) []R {
+	results := make([]R, len(items))
+
+	var (
+		next atomic.Int64
+		wg   sync.WaitGroup
+		work func()
+	)
+
+	work = func() {
+		for {
+			i := int(next.Add(1)) - 1
+			if i >= len(items) {
+				return
+			}
+
+			if i+1 < len(items) && pool.tryAcquire() {
+				wg.Go(func() {
+					defer pool.release()
+					work()
+				})
+			}
+
+			results[i] = fn(items[i])
+		}
+	}
+
+	work()
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultJobs(t *testing.T) {
+	assert.GreaterOrEqual(t, DefaultJobs(), 1)
+}
+
+func Test_parallelMap(t *testing.T) {
+	t.Run("keeps input order", func(t *testing.T) {
+		items := make([]int, 100)
+		for i := range items {
+			items[i] = i
+		}
+
+		results := parallelMap(newWorkerPool(8), items, func(i int) int { return i * 2 })
+
+		for i, result := range results {
+			assert.Equal(t, i*2, result)
+		}
+	})
+
+	t.Run("never exceeds jobs workers", func(t *testing.T) {
+		var running, peak atomic.Int32
+
+		parallelMap(newWorkerPool(3), make([]struct{}, 50), func(struct{}) bool {
+			trackPeak(&running, &peak)
+			return true
+		})
+
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+
+	t.Run("shares the bound with nested maps", func(t *testing.T) {
+		var running, peak atomic.Int32
+
+		pool := newWorkerPool(3)
+
+		parallelMap(pool, make([]struct{}, 6), func(struct{}) bool {
+			parallelMap(pool, make([]struct{}, 20), func(struct{}) bool {
+				trackPeak(&running, &peak)
+				return true
+			})
+
+			return true
+		})
+
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+
+	t.Run("treats non-positive jobs as one worker", func(t *testing.T) {
+		results := parallelMap(newWorkerPool(0), []string{"a", "b"}, func(s string) string { return s })
+		assert.Equal(t, []string{"a", "b"}, results)
+	})
+
+	t.Run("runs serially without a pool", func(t *testing.T) {
+		results := parallelMap(nil, []string{"a", "b"}, func(s string) string { return s })
+		assert.Equal(t, []string{"a", "b"}, results)
+	})
+
+	t.Run("handles empty input", func(t *testing.T) {
+		results := parallelMap(newWorkerPool(4), nil, func(s string) string { return s })
+		assert.Empty(t, results)
+	})
+}
+
+// trackPeak records how many calls run at once while briefly holding a slot.
+func trackPeak(running, peak *atomic.Int32) {
+	current := running.Add(1)
+	defer running.Add(-1)
+
+	for {
+		seen := peak.Load()
+		if current <= seen || peak.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+}
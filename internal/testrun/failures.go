@@ -0,0 +1,155 @@
+package testrun
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// failureLineRegex matches the file:line prefix go test puts in front of a
+// t.Error or t.Fatal message.
+var failureLineRegex = regexp.MustCompile(`^\s+([\w.-]+\.go):(\d+):`)
+
+// testID identifies a test within a pass.
+type testID struct {
+	pkg  string
+	test string
+}
+
+// Failure is a test that failed in a pass. File is relative to the module
+// root and, with Line, points at the first location the test output names,
+// such as a failed assertion; it is the package directory when the output
+// names none.
+type Failure struct {
+	Package string
+	Test    string
+	File    string
+	Line    int
+	Output  string
+}
+
+// Failures returns the tests that failed in the pass in the order they
+// finished. A test that only failed through its subtests is left out, since
+// each failed subtest is reported on its own.
+func (p *Pass) Failures() []Failure {
+	output := make(map[testID]*strings.Builder)
+
+	var failed []testID
+
+	for _, event := range p.Events {
+		if event.Test == "" {
+			continue
+		}
+
+		key := testID{pkg: event.Package, test: event.Test}
+
+		switch event.Action {
+		case "output":
+			if output[key] == nil {
+				output[key] = &strings.Builder{}
+			}
+
+			output[key].WriteString(event.Output)
+		case "fail":
+			failed = append(failed, key)
+		}
+	}
+
+	modulePath := readModulePath()
+
+	var failures []Failure
+
+	for _, key := range failed {
+		if hasFailedSubtest(failed, key) {
+			continue
+		}
+
+		var text string
+		if output[key] != nil {
+			text = output[key].String()
+		}
+
+		dir := packageDir(key.pkg, modulePath)
+
+		failure := Failure{
+			Package: key.pkg,
+			Test:    key.test,
+			File:    dir,
+			Output:  text,
+		}
+
+		for line := range strings.Lines(text) {
+			if match := failureLineRegex.FindStringSubmatch(line); match != nil {
+				failure.File = path.Join(dir, match[1])
+				failure.Line, _ = strconv.Atoi(match[2])
+
+				break
+			}
+		}
+
+		failures = append(failures, failure)
+	}
+
+	return failures
+}
+
+// Failures joins the failed tests of every pass.
+func (r *Result) Failures() []Failure {
+	var failures []Failure
+
+	for _, pass := range r.Passes {
+		failures = append(failures, pass.Failures()...)
+	}
+
+	return failures
+}
+
+// hasFailedSubtest reports whether a subtest of id is among the failed tests.
+func hasFailedSubtest(failed []testID, id testID) bool {
+	prefix := fmt.Sprintf("%s/", id.test)
+
+	for _, other := range failed {
+		if other.pkg == id.pkg && strings.HasPrefix(other.test, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readModulePath returns the module path declared by go.mod in the working
+// directory, or "" when there is none.
+func readModulePath() string {
+	file, err := os.Open("go.mod")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+
+	return ""
+}
+
+// packageDir returns the directory of pkg relative to the module root.
+func packageDir(pkg, modulePath string) string {
+	if pkg == modulePath {
+		return "."
+	}
+
+	if rest, ok := strings.CutPrefix(pkg, fmt.Sprintf("%s/", modulePath)); ok && modulePath != "" {
+		return rest
+	}
+
+	return pkg
+}
@@ -0,0 +1,58 @@
+package testrun
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	require.NoError(t, os.Chdir(tmpDir))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0644))
+
+	pass := &Pass{Events: []Event{
+		{Action: "output", Package: "example.com/app/calc", Test: "TestAdd", Output: "=== RUN   TestAdd\n"},
+		{Action: "output", Package: "example.com/app/calc", Test: "TestAdd", Output: "    calc_test.go:12: bad sum\n"},
+		{Action: "output", Package: "example.com/app/calc", Test: "TestAdd", Output: "--- FAIL: TestAdd (0.00s)\n"},
+		{Action: "fail", Package: "example.com/app/calc", Test: "TestAdd"},
+		{Action: "output", Package: "example.com/app/calc", Test: "TestSub/negative", Output: "    sub_test.go:30: wrong sign\n"},
+		{Action: "fail", Package: "example.com/app/calc", Test: "TestSub/negative"},
+		{Action: "fail", Package: "example.com/app/calc", Test: "TestSub"},
+		{Action: "output", Package: "example.com/app", Test: "TestMain", Output: "panic: boom\n"},
+		{Action: "fail", Package: "example.com/app", Test: "TestMain"},
+		{Action: "pass", Package: "example.com/app/calc", Test: "TestMul"},
+		{Action: "fail", Package: "example.com/app/calc"},
+	}}
+
+	failures := pass.Failures()
+
+	require.Len(t, failures, 3)
+	assert.Equal(t, Failure{
+		Package: "example.com/app/calc",
+		Test:    "TestAdd",
+		File:    "calc/calc_test.go",
+		Line:    12,
+		Output:  "=== RUN   TestAdd\n    calc_test.go:12: bad sum\n--- FAIL: TestAdd (0.00s)\n",
+	}, failures[0])
+	assert.Equal(t, "TestSub/negative", failures[1].Test)
+	assert.Equal(t, "calc/sub_test.go", failures[1].File)
+	assert.Equal(t, 30, failures[1].Line)
+	assert.Equal(t, ".", failures[2].File)
+	assert.Zero(t, failures[2].Line)
+
+	result := &Result{Passes: []*Pass{pass, {}}}
+	assert.Equal(t, failures, result.Failures())
+}
+
+func Test_packageDir(t *testing.T) {
+	assert.Equal(t, ".", packageDir("example.com/app", "example.com/app"))
+	assert.Equal(t, "internal/db", packageDir("example.com/app/internal/db", "example.com/app"))
+	assert.Equal(t, "example.com/other", packageDir("example.com/other", "example.com/app"))
+	assert.Equal(t, "example.com/app", packageDir("example.com/app", ""))
+}
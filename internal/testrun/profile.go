@@ -0,0 +1,174 @@
+package testrun
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MergedProfile merges the coverage profiles of every pass with the
+// GOCOVERDIR data in coverDirs, as written by binaries built with
+// `go build -cover`, into one text profile and returns its path. Missing
+// profiles and coverage directories are skipped, so a failed build or an
+// end-to-end suite that did not run leaves the rest of the data usable.
+// The merged profile is removed by Close.
+func (r *Result) MergedProfile(ctx context.Context, coverDirs []string) (string, error) {
+	if r.dir == "" {
+		dir, err := os.MkdirTemp("", "yake-testrun-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create coverage directory: %w", err)
+		}
+
+		r.dir = dir
+	}
+
+	profiles := make([]string, 0, len(r.Passes)+len(coverDirs))
+	for _, pass := range r.Passes {
+		profiles = append(profiles, pass.Profile)
+	}
+
+	for i, coverDir := range coverDirs {
+		entries, err := os.ReadDir(coverDir)
+		if err != nil || len(entries) == 0 {
+			log.Printf("Skipping coverage directory %s: no coverage data", coverDir)
+			continue
+		}
+
+		profile := filepath.Join(r.dir, fmt.Sprintf("covdata-%d.out", i))
+		if err := convertCoverDir(ctx, coverDir, profile); err != nil {
+			return "", err
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	merged := newProfileMerger()
+	for _, profile := range profiles {
+		if err := merged.add(profile); err != nil {
+			return "", err
+		}
+	}
+
+	path := filepath.Join(r.dir, "merged.out")
+	if err := os.WriteFile(path, []byte(merged.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write merged coverage profile: %w", err)
+	}
+
+	return path, nil
+}
+
+// convertCoverDir writes the GOCOVERDIR data in dir as a text profile.
+func convertCoverDir(ctx context.Context, dir, profile string) error {
+	args := []string{"tool", "covdata", "textfmt", fmt.Sprintf("-i=%s", dir), fmt.Sprintf("-o=%s", profile)}
+
+	out, err := exec.CommandContext(ctx, "go", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to convert coverage data in %s: %w: %s", dir, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// profileMerger sums the counts of identical blocks across text profiles.
+// A block is listed once per test binary that instruments it, and the
+// same block from different passes or binaries counts as one.
+type profileMerger struct {
+	mode   string
+	blocks []string
+	counts map[string]int
+}
+
+func newProfileMerger() *profileMerger {
+	return &profileMerger{counts: make(map[string]int)}
+}
+
+func (m *profileMerger) add(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if mode, ok := strings.CutPrefix(line, "mode: "); ok {
+			m.setMode(mode)
+			continue
+		}
+
+		block, countText, ok := cutLast(line, " ")
+		if !ok {
+			continue
+		}
+
+		count, err := strconv.Atoi(countText)
+		if err != nil {
+			continue
+		}
+
+		if _, seen := m.counts[block]; !seen {
+			m.blocks = append(m.blocks, block)
+		}
+
+		m.counts[block] += count
+	}
+
+	return scanner.Err()
+}
+
+// setMode keeps the mode the profiles agree on. Profiles in different modes
+// merge in set mode, which every mode can be reduced to.
+func (m *profileMerger) setMode(mode string) {
+	switch m.mode {
+	case "", mode:
+		m.mode = mode
+	default:
+		m.mode = "set"
+	}
+}
+
+// String renders the merged profile, blocks in the order first seen.
+func (m *profileMerger) String() string {
+	mode := m.mode
+	if mode == "" {
+		mode = "set"
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "mode: %s\n", mode)
+
+	for _, block := range m.blocks {
+		count := m.counts[block]
+		if mode == "set" {
+			count = min(count, 1)
+		}
+
+		fmt.Fprintf(&b, "%s %d\n", block, count)
+	}
+
+	return b.String()
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
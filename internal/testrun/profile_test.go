@@ -0,0 +1,103 @@
+package testrun
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultMergedProfile(t *testing.T) {
+	t.Run("sums blocks across passes", func(t *testing.T) {
+		dir := t.TempDir()
+		untagged := filepath.Join(dir, "cover-0.out")
+		tagged := filepath.Join(dir, "cover-1.out")
+
+		require.NoError(t, os.WriteFile(untagged, []byte("mode: atomic\nm/a.go:3.1,4.2 1 1\nm/a.go:5.1,6.2 2 0\nm/b.go:3.1,4.2 1 0\n"), 0644))
+		require.NoError(t, os.WriteFile(tagged, []byte("mode: atomic\nm/a.go:5.1,6.2 2 3\nm/b.go:3.1,4.2 1 0\n"), 0644))
+
+		result := &Result{Passes: []*Pass{
+			{Profile: untagged},
+			{Profile: tagged},
+			{Profile: filepath.Join(dir, "missing.out")},
+		}}
+		defer result.Close()
+
+		path, err := result.MergedProfile(t.Context(), []string{filepath.Join(dir, "e2e")})
+		require.NoError(t, err)
+
+		merged, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "mode: atomic\nm/a.go:3.1,4.2 1 1\nm/a.go:5.1,6.2 2 3\nm/b.go:3.1,4.2 1 0\n", string(merged))
+
+		require.NoError(t, result.Close())
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("adds coverage of binaries built with -cover", func(t *testing.T) {
+		writeProject(t, "")
+		require.NoError(t, os.Mkdir("cmd", 0755))
+		require.NoError(t, os.WriteFile("cmd/main.go", []byte("package main\n\nimport calc \"testproject\"\n\nfunc main() {\n\tcalc.Add(1, 2)\n}\n"), 0644))
+
+		out, err := exec.Command("go", "build", "-cover", "-o", "app", "./cmd").CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		coverDir, err := filepath.Abs("e2e")
+		require.NoError(t, err)
+		require.NoError(t, os.Mkdir(coverDir, 0755))
+
+		app := exec.Command("./app")
+		app.Env = append(os.Environ(), fmt.Sprintf("GOCOVERDIR=%s", coverDir))
+		out, err = app.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		result := &Result{Passes: []*Pass{{Profile: "missing.out"}}}
+		defer result.Close()
+
+		path, err := result.MergedProfile(t.Context(), []string{coverDir})
+		require.NoError(t, err)
+
+		merged, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(merged), "mode: set\n")
+		assert.Contains(t, string(merged), "testproject/calc.go:4.2,5.1 1 1\n")
+	})
+
+	t.Run("returns error for unreadable coverage data", func(t *testing.T) {
+		coverDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(coverDir, "covmeta.bad"), []byte("x"), 0644))
+
+		result := &Result{}
+		defer result.Close()
+
+		_, err := result.MergedProfile(t.Context(), []string{coverDir})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to convert coverage data")
+	})
+}
+
+func Test_profileMerger(t *testing.T) {
+	t.Run("merges mixed modes as set", func(t *testing.T) {
+		dir := t.TempDir()
+		atomic := filepath.Join(dir, "atomic.out")
+		set := filepath.Join(dir, "set.out")
+
+		require.NoError(t, os.WriteFile(atomic, []byte("mode: atomic\nm/a.go:3.1,4.2 1 4\nbroken line\nm/a.go:5.1,6.2 1 x\n"), 0644))
+		require.NoError(t, os.WriteFile(set, []byte("mode: set\nm/a.go:3.1,4.2 1 1\n"), 0644))
+
+		merger := newProfileMerger()
+		require.NoError(t, merger.add(atomic))
+		require.NoError(t, merger.add(set))
+
+		assert.Equal(t, "mode: set\nm/a.go:3.1,4.2 1 1\n", merger.String())
+	})
+
+	t.Run("renders an empty profile", func(t *testing.T) {
+		assert.Equal(t, "mode: set\n", newProfileMerger().String())
+	})
+}
@@ -0,0 +1,245 @@
+package testrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Event is a single line of `go test -json` output.
+type Event struct {
+	Action  string  `json:"Action"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test,omitempty"`
+	Elapsed float64 `json:"Elapsed,omitempty"`
+	Output  string  `json:"Output,omitempty"`
+}
+
+// Pass is one instrumented `go test` invocation over the whole module.
+type Pass struct {
+	Tags    []string
+	Events  []Event
+	Profile string
+	// Err is set when go test exits with an error, typically because a test
+	// or a build failed. Events and Profile still hold whatever was produced.
+	Err error
+}
+
+// Output reassembles the package-level text output, as go test prints it
+// without -json.
+func (p *Pass) Output() string {
+	var b strings.Builder
+
+	for _, event := range p.Events {
+		if event.Action == "output" && event.Test == "" {
+			b.WriteString(event.Output)
+		}
+	}
+
+	return b.String()
+}
+
+// Result holds every pass of a run. The first pass is always untagged; a
+// tagged pass follows when build tags are configured.
+type Result struct {
+	Passes []*Pass
+	dir    string
+}
+
+// Untagged returns the pass that ran without build tags.
+func (r *Result) Untagged() *Pass {
+	return r.Passes[0]
+}
+
+// Output joins the package-level text output of every pass.
+func (r *Result) Output() string {
+	var b strings.Builder
+
+	for _, pass := range r.Passes {
+		b.WriteString(pass.Output())
+	}
+
+	return b.String()
+}
+
+// Err joins the errors of every pass.
+func (r *Result) Err() error {
+	errs := make([]error, 0, len(r.Passes))
+	for _, pass := range r.Passes {
+		errs = append(errs, pass.Err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close removes the coverage profiles written by the run.
+func (r *Result) Close() error {
+	return os.RemoveAll(r.dir)
+}
+
+// Options configures Run.
+type Options struct {
+	// Tags are build tags for an extra pass after the untagged one.
+	Tags []string
+	// Output receives the human-readable test output as it streams. When nil
+	// the output is dropped; stderr always goes to os.Stderr.
+	Output io.Writer
+	// Timeout limits each pass; zero means no limit.
+	Timeout time.Duration
+}
+
+// Run executes `go test -json -race -coverprofile` once per pass so every
+// consumer (test reporting, duration and coverage policies) shares the same
+// run. The error reports runs that could not start; test failures are
+// recorded on each Pass. Cancelling ctx stops the run.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	dir, err := os.MkdirTemp("", "yake-testrun-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coverage directory: %w", err)
+	}
+
+	result := &Result{dir: dir}
+
+	passTags := [][]string{nil}
+	if len(opts.Tags) > 0 {
+		passTags = append(passTags, opts.Tags)
+	}
+
+	for i, tags := range passTags {
+		profile := filepath.Join(dir, fmt.Sprintf("cover-%d.out", i))
+
+		pass, err := runPass(ctx, opts, tags, profile)
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+
+		result.Passes = append(result.Passes, pass)
+	}
+
+	return result, nil
+}
+
+// TagsArgs returns a "-tags=<tag>" argument for each build tag.
+func TagsArgs(tags []string) []string {
+	args := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		args = append(args, fmt.Sprintf("-tags=%s", tag))
+	}
+
+	return args
+}
+
+func runPass(ctx context.Context, opts Options, tags []string, profile string) (*Pass, error) {
+	cancel := func() {}
+
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+
+	defer cancel()
+
+	args := []string{"test", "-json", "-race", fmt.Sprintf("-coverprofile=%s", profile)}
+	args = append(args, TagsArgs(tags)...)
+	args = append(args, "./...")
+
+	command := append([]string{"go"}, args...)
+	log.Printf("Running: %v", command)
+
+	events := &eventWriter{echo: opts.Output}
+	if opts.Output == nil {
+		events.echo = io.Discard
+	}
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Stdout = events
+	cmd.Stderr = os.Stderr
+	// Test binaries left behind by a timeout keep the output pipe open;
+	// do not wait for them once go itself has been killed.
+	cmd.WaitDelay = time.Second
+
+	pass := &Pass{
+		Tags:    tags,
+		Profile: profile,
+	}
+
+	if err := cmd.Run(); err != nil {
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			pass.Err = fmt.Errorf("task timed out after %s", opts.Timeout)
+		case cmd.ProcessState == nil:
+			return nil, fmt.Errorf("failed to run %s: %w", command, err)
+		default:
+			pass.Err = fmt.Errorf("failed to run %s: %w", command, err)
+		}
+	}
+
+	events.flush()
+	pass.Events = events.events
+
+	return pass, nil
+}
+
+// ParseEvents decodes `go test -json` output, skipping lines that are not
+// JSON events.
+func ParseEvents(data []byte) []Event {
+	events := &eventWriter{echo: io.Discard}
+	events.Write(data)
+	events.flush()
+
+	return events.events
+}
+
+// eventWriter decodes `go test -json` lines as they are written and echoes
+// their text output, so the user sees regular test output while it runs.
+// Lines that are not JSON events are echoed as they are.
+type eventWriter struct {
+	echo   io.Writer
+	buf    []byte
+	events []Event
+}
+
+func (w *eventWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.handleLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush handles a trailing line without a newline.
+func (w *eventWriter) flush() {
+	if len(w.buf) > 0 {
+		w.handleLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *eventWriter) handleLine(line []byte) {
+	var event Event
+	if err := json.Unmarshal(line, &event); err != nil || event.Action == "" {
+		fmt.Fprintln(w.echo, string(line))
+		return
+	}
+
+	io.WriteString(w.echo, event.Output)
+
+	w.events = append(w.events, event)
+}
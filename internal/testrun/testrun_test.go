@@ -0,0 +1,204 @@
+package testrun
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProject(t *testing.T, testBody string) {
+	t.Helper()
+
+	// Skip the race detector's one second delay at exit of the nested run.
+	t.Setenv("GORACE", "atexit_sleep_ms=0")
+
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(originalDir) })
+
+	require.NoError(t, os.Chdir(tmpDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile("calc.go", []byte("package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"), 0644))
+
+	test := fmt.Sprintf("package calc\n\nimport (\n\t\"testing\"\n\t\"time\"\n)\n\nvar _ = time.Second\n\nfunc TestAdd(t *testing.T) {\n%s}\n", testBody)
+	require.NoError(t, os.WriteFile("calc_test.go", []byte(test), 0644))
+}
+
+func TestRun(t *testing.T) {
+	t.Run("runs a single instrumented pass", func(t *testing.T) {
+		writeProject(t, "\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"bad sum\")\n\t}\n")
+
+		var out bytes.Buffer
+
+		result, err := Run(t.Context(), Options{Output: &out})
+		require.NoError(t, err)
+		defer result.Close()
+
+		require.Len(t, result.Passes, 1)
+		assert.NoError(t, result.Err())
+
+		pass := result.Untagged()
+		assert.Empty(t, pass.Tags)
+		assert.NotEmpty(t, pass.Events)
+		assert.Contains(t, pass.Output(), "coverage: 100.0% of statements")
+		assert.Contains(t, out.String(), "ok  \ttestproject")
+		assert.NotContains(t, out.String(), `"Action"`)
+
+		profile, err := os.ReadFile(pass.Profile)
+		require.NoError(t, err)
+		assert.Contains(t, string(profile), "mode: atomic")
+	})
+
+	t.Run("adds a tagged pass", func(t *testing.T) {
+		writeProject(t, "")
+
+		result, err := Run(t.Context(), Options{Tags: []string{"integration"}})
+		require.NoError(t, err)
+		defer result.Close()
+
+		require.Len(t, result.Passes, 2)
+		assert.Empty(t, result.Passes[0].Tags)
+		assert.Equal(t, []string{"integration"}, result.Passes[1].Tags)
+		assert.NotEqual(t, result.Passes[0].Profile, result.Passes[1].Profile)
+	})
+
+	t.Run("records failing tests on the pass", func(t *testing.T) {
+		writeProject(t, "\tt.Fatal(\"boom\")\n")
+
+		result, err := Run(t.Context(), Options{})
+		require.NoError(t, err)
+		defer result.Close()
+
+		require.Error(t, result.Err())
+		assert.Contains(t, result.Err().Error(), "failed to run")
+		assert.Contains(t, result.Untagged().Output(), "FAIL")
+	})
+
+	t.Run("reports timeout", func(t *testing.T) {
+		writeProject(t, "\ttime.Sleep(10 * time.Second)\n")
+
+		result, err := Run(t.Context(), Options{Timeout: 100 * time.Millisecond})
+		require.NoError(t, err)
+		defer result.Close()
+
+		require.Error(t, result.Err())
+		assert.Contains(t, result.Err().Error(), "task timed out")
+	})
+
+	t.Run("returns error when go is not available", func(t *testing.T) {
+		writeProject(t, "")
+		t.Setenv("PATH", t.TempDir())
+
+		_, err := Run(t.Context(), Options{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to run")
+	})
+}
+
+func TestResult(t *testing.T) {
+	t.Run("joins pass errors", func(t *testing.T) {
+		result := &Result{Passes: []*Pass{{}, {Err: errors.New("tagged failed")}}}
+
+		require.Error(t, result.Err())
+		assert.Equal(t, "tagged failed", result.Err().Error())
+	})
+
+	t.Run("returns nil without failures", func(t *testing.T) {
+		result := &Result{Passes: []*Pass{{}}}
+
+		assert.NoError(t, result.Err())
+		assert.Same(t, result.Passes[0], result.Untagged())
+	})
+
+	t.Run("close removes profiles", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "run")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cover-0.out"), []byte("mode: atomic\n"), 0644))
+
+		result := &Result{dir: dir}
+		require.NoError(t, result.Close())
+
+		_, err := os.Stat(dir)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestPassOutput(t *testing.T) {
+	pass := &Pass{Events: []Event{
+		{Action: "output", Package: "a", Test: "TestA", Output: "=== RUN   TestA\n"},
+		{Action: "output", Package: "a", Output: "ok  \ta\t0.1s\n"},
+		{Action: "pass", Package: "a", Elapsed: 0.1},
+		{Action: "output", Package: "b", Output: "?   \tb\t[no test files]\n"},
+	}}
+
+	assert.Equal(t, "ok  \ta\t0.1s\n?   \tb\t[no test files]\n", pass.Output())
+
+	result := &Result{Passes: []*Pass{pass, {Events: []Event{{Action: "output", Package: "c", Output: "ok  \tc\t0.2s\n"}}}}}
+	assert.Equal(t, "ok  \ta\t0.1s\n?   \tb\t[no test files]\nok  \tc\t0.2s\n", result.Output())
+}
+
+func TestTagsArgs(t *testing.T) {
+	t.Run("empty tags returns no args", func(t *testing.T) {
+		assert.Empty(t, TagsArgs(nil))
+		assert.Empty(t, TagsArgs([]string{}))
+	})
+
+	t.Run("single tag", func(t *testing.T) {
+		assert.Equal(t, []string{"-tags=integration"}, TagsArgs([]string{"integration"}))
+	})
+
+	t.Run("each tag becomes its own flag", func(t *testing.T) {
+		assert.Equal(t, []string{"-tags=integration", "-tags=e2e"}, TagsArgs([]string{"integration", "e2e"}))
+	})
+}
+
+func TestParseEvents(t *testing.T) {
+	t.Run("decodes events", func(t *testing.T) {
+		data := `{"Action":"run","Package":"a","Test":"TestA"}
+{"Action":"pass","Package":"a","Elapsed":1.5}`
+
+		events := ParseEvents([]byte(data))
+		require.Len(t, events, 2)
+		assert.Equal(t, Event{Action: "run", Package: "a", Test: "TestA"}, events[0])
+		assert.Equal(t, 1.5, events[1].Elapsed)
+	})
+
+	t.Run("skips lines that are not events", func(t *testing.T) {
+		data := "not json\n{}\n{\"Action\":\"pass\",\"Package\":\"a\"}\n"
+
+		events := ParseEvents([]byte(data))
+		require.Len(t, events, 1)
+		assert.Equal(t, "pass", events[0].Action)
+	})
+
+	t.Run("handles empty input", func(t *testing.T) {
+		assert.Empty(t, ParseEvents(nil))
+	})
+}
+
+func Test_eventWriter(t *testing.T) {
+	var echo bytes.Buffer
+
+	w := &eventWriter{echo: &echo}
+
+	n, err := w.Write([]byte(`{"Action":"output","Package":"a","Output":"hel`))
+	require.NoError(t, err)
+	assert.Equal(t, 46, n)
+	assert.Empty(t, w.events)
+
+	_, err = w.Write([]byte("lo\\n\"}\nbuild failed\n"))
+	require.NoError(t, err)
+	require.Len(t, w.events, 1)
+	assert.Equal(t, "hello\nbuild failed\n", echo.String())
+
+	w.flush()
+	assert.Len(t, w.events, 1)
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// InGitHubActions reports whether the process runs inside a GitHub Actions job.
+func InGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// GitHubAnnotation is an ::error/::warning workflow command that GitHub
+// renders inline on the pull request diff.
+type GitHubAnnotation struct {
+	Level   string
+	File    string
+	Line    int
+	Column  int
+	Title   string
+	Message string
+}
+
+func (a GitHubAnnotation) String() string {
+	var props []string
+
+	if a.File != "" {
+		props = append(props, fmt.Sprintf("file=%s", escapeGitHubProperty(a.File)))
+	}
+
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+
+	if a.Column > 0 {
+		props = append(props, fmt.Sprintf("col=%d", a.Column))
+	}
+
+	if a.Title != "" {
+		props = append(props, fmt.Sprintf("title=%s", escapeGitHubProperty(a.Title)))
+	}
+
+	level := a.Level
+	if level == "" {
+		level = "error"
+	}
+
+	if len(props) == 0 {
+		return fmt.Sprintf("::%s::%s", level, escapeGitHubData(a.Message))
+	}
+
+	return fmt.Sprintf("::%s %s::%s", level, strings.Join(props, ","), escapeGitHubData(a.Message))
+}
+
+// AppendGitHubStepSummary appends markdown to the job summary file. It does
+// nothing when GITHUB_STEP_SUMMARY is not set.
+func AppendGitHubStepSummary(content string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+var (
+	gitHubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	gitHubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeGitHubData(s string) string {
+	return gitHubDataEscaper.Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return gitHubPropertyEscaper.Replace(s)
+}
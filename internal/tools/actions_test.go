@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInGitHubActions(t *testing.T) {
+	t.Run("detects GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
+		assert.True(t, InGitHubActions())
+	})
+
+	t.Run("returns false outside GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		assert.False(t, InGitHubActions())
+	})
+}
+
+func TestGitHubAnnotation(t *testing.T) {
+	t.Run("formats full annotation", func(t *testing.T) {
+		a := GitHubAnnotation{
+			Level:   "warning",
+			File:    "internal/app/app.go",
+			Line:    12,
+			Column:  9,
+			Title:   "string_concat",
+			Message: "string concatenation with '+'",
+		}
+
+		assert.Equal(t, "::warning file=internal/app/app.go,line=12,col=9,title=string_concat::string concatenation with '+'", a.String())
+	})
+
+	t.Run("defaults to error without properties", func(t *testing.T) {
+		a := GitHubAnnotation{Message: "failed"}
+
+		assert.Equal(t, "::error::failed", a.String())
+	})
+
+	t.Run("escapes message and properties", func(t *testing.T) {
+		a := GitHubAnnotation{
+			File:    "a,b:c.go",
+			Message: "100% broken\nsecond line",
+		}
+
+		assert.Equal(t, "::error file=a%2Cb%3Ac.go::100%25 broken%0Asecond line", a.String())
+	})
+}
+
+func TestAppendGitHubStepSummary(t *testing.T) {
+	t.Run("does nothing without summary file", func(t *testing.T) {
+		t.Setenv("GITHUB_STEP_SUMMARY", "")
+		assert.NoError(t, AppendGitHubStepSummary("content"))
+	})
+
+	t.Run("appends to summary file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "summary.md")
+		t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+		require.NoError(t, AppendGitHubStepSummary("first\n"))
+		require.NoError(t, AppendGitHubStepSummary("second\n"))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "first\nsecond\n", string(content))
+	})
+
+	t.Run("returns error for invalid path", func(t *testing.T) {
+		t.Setenv("GITHUB_STEP_SUMMARY", "/nonexistent/dir/summary.md")
+		assert.Error(t, AppendGitHubStepSummary("content"))
+	})
+}
@@ -1,19 +1,16 @@
 package tools
 
-import (
-	"log"
-	"os"
-)
+import "os"
 
-func WriteStringToFile(fileName string, content string) error {
+func WriteStringToFile(fileName string, content string) (err error) {
 	file, err := os.Create(fileName)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
-		if err := file.Close(); err != nil {
-			log.Fatal(err)
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
 		}
 	}()
 
@@ -1,13 +1,17 @@
 package tools
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
-func DetectDefaultBranch() (string, error) {
-	out, err := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD").Output()
+func DetectDefaultBranch(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "symbolic-ref", "refs/remotes/origin/HEAD").Output()
 	if err == nil {
 		branch := strings.TrimSpace(string(out))
 		branch = strings.TrimPrefix(branch, "refs/remotes/origin/")
@@ -17,15 +21,15 @@ func DetectDefaultBranch() (string, error) {
 		}
 	}
 
-	if err := exec.Command("git", "rev-parse", "--verify", "refs/heads/main").Run(); err == nil {
+	if err := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "refs/heads/main").Run(); err == nil {
 		return "main", nil
 	}
 
-	if err := exec.Command("git", "rev-parse", "--verify", "refs/heads/master").Run(); err == nil {
+	if err := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "refs/heads/master").Run(); err == nil {
 		return "master", nil
 	}
 
-	out, err = exec.Command("git", "symbolic-ref", "--short", "HEAD").Output()
+	out, err = exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "HEAD").Output()
 	if err == nil {
 		branch := strings.TrimSpace(string(out))
 		if branch == "main" || branch == "master" {
@@ -41,8 +45,8 @@ type GitHubRepo struct {
 	Name  string
 }
 
-func DetectGitHubRepo() (GitHubRepo, error) {
-	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+func DetectGitHubRepo(ctx context.Context) (GitHubRepo, error) {
+	out, err := exec.CommandContext(ctx, "git", "remote", "get-url", "origin").Output()
 	if err != nil {
 		return GitHubRepo{}, fmt.Errorf("could not detect GitHub repository: no origin remote")
 	}
@@ -76,3 +80,127 @@ func parseGitHubURL(url string) (owner, name string, ok bool) {
 
 	return "", "", false
 }
+
+// LineRange is an inclusive range of line numbers.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes maps files, relative to the working directory, to the line ranges
+// added or modified in them. A file whose changes only delete lines has no
+// ranges.
+type Changes map[string][]LineRange
+
+// HasFile reports whether the file changed.
+func (c Changes) HasFile(path string) bool {
+	_, ok := c[path]
+	return ok
+}
+
+// HasLine reports whether the given line of the file was added or modified.
+func (c Changes) HasLine(path string, line int) bool {
+	for _, r := range c[path] {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChangesSince returns the working tree changes since the merge base of ref
+// and HEAD, so changes that landed on ref after branching are left out.
+func ChangesSince(ctx context.Context, ref string) (Changes, error) {
+	out, err := exec.CommandContext(ctx, "git", "merge-base", ref, "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base with %s: %w", ref, err)
+	}
+
+	return gitDiffChanges(ctx, strings.TrimSpace(string(out)))
+}
+
+// StagedChanges returns the changes staged for the next commit.
+func StagedChanges(ctx context.Context) (Changes, error) {
+	return gitDiffChanges(ctx, "--cached")
+}
+
+func gitDiffChanges(ctx context.Context, args ...string) (Changes, error) {
+	diffArgs := []string{"diff", "--relative", "--unified=0", "--no-color", "--no-ext-diff", "--diff-filter=ACMR"}
+	diffArgs = append(diffArgs, args...)
+
+	out, err := exec.CommandContext(ctx, "git", diffArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git diff: %w", err)
+	}
+
+	return parseUnifiedDiff(out), nil
+}
+
+// parseUnifiedDiff collects the new-side line ranges of every hunk.
+func parseUnifiedDiff(data []byte) Changes {
+	changes := make(Changes)
+
+	var current string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if path, ok := strings.CutPrefix(line, "+++ "); ok {
+			current = ""
+
+			if path != "/dev/null" {
+				current = strings.TrimPrefix(path, "b/")
+				if _, ok := changes[current]; !ok {
+					changes[current] = []LineRange{}
+				}
+			}
+
+			continue
+		}
+
+		if !strings.HasPrefix(line, "@@ ") || current == "" {
+			continue
+		}
+
+		if r, ok := parseHunkHeader(line); ok {
+			changes[current] = append(changes[current], r)
+		}
+	}
+
+	return changes
+}
+
+// parseHunkHeader reads the new-side range from "@@ -a,b +c,d @@". Hunks that
+// only delete lines have no new-side range.
+func parseHunkHeader(line string) (LineRange, bool) {
+	for _, field := range strings.Fields(line) {
+		spec, ok := strings.CutPrefix(field, "+")
+		if !ok {
+			continue
+		}
+
+		startText, countText, hasCount := strings.Cut(spec, ",")
+
+		start, err := strconv.Atoi(startText)
+		if err != nil {
+			return LineRange{}, false
+		}
+
+		count := 1
+		if hasCount {
+			if count, err = strconv.Atoi(countText); err != nil {
+				return LineRange{}, false
+			}
+		}
+
+		if count == 0 {
+			return LineRange{}, false
+		}
+
+		return LineRange{Start: start, End: start + count - 1}, true
+	}
+
+	return LineRange{}, false
+}
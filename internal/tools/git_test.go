@@ -3,6 +3,7 @@ package tools
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,7 +39,7 @@ func TestDetectDefaultBranch(t *testing.T) {
 		cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/custom")
 		require.NoError(t, cmd.Run())
 
-		branch, err := DetectDefaultBranch()
+		branch, err := DetectDefaultBranch(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "custom", branch)
 	})
@@ -51,7 +52,7 @@ func TestDetectDefaultBranch(t *testing.T) {
 		os.Chdir(tmpDir)
 		initGitRepo(t, "main")
 
-		branch, err := DetectDefaultBranch()
+		branch, err := DetectDefaultBranch(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "main", branch)
 	})
@@ -64,7 +65,7 @@ func TestDetectDefaultBranch(t *testing.T) {
 		os.Chdir(tmpDir)
 		initGitRepo(t, "master")
 
-		branch, err := DetectDefaultBranch()
+		branch, err := DetectDefaultBranch(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "master", branch)
 	})
@@ -80,7 +81,7 @@ func TestDetectDefaultBranch(t *testing.T) {
 		cmd := exec.Command("git", "branch", "master")
 		require.NoError(t, cmd.Run())
 
-		branch, err := DetectDefaultBranch()
+		branch, err := DetectDefaultBranch(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "main", branch)
 	})
@@ -95,7 +96,7 @@ func TestDetectDefaultBranch(t *testing.T) {
 		cmd := exec.Command("git", "init", "-b", "main")
 		require.NoError(t, cmd.Run())
 
-		branch, err := DetectDefaultBranch()
+		branch, err := DetectDefaultBranch(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "main", branch)
 	})
@@ -108,7 +109,7 @@ func TestDetectDefaultBranch(t *testing.T) {
 		os.Chdir(tmpDir)
 		initGitRepo(t, "develop")
 
-		_, err := DetectDefaultBranch()
+		_, err := DetectDefaultBranch(t.Context())
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "could not detect default branch")
 	})
@@ -126,7 +127,7 @@ func TestDetectGitHubRepo(t *testing.T) {
 		cmd := exec.Command("git", "remote", "add", "origin", "https://github.com/myowner/myrepo.git")
 		require.NoError(t, cmd.Run())
 
-		repo, err := DetectGitHubRepo()
+		repo, err := DetectGitHubRepo(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "myowner", repo.Owner)
 		assert.Equal(t, "myrepo", repo.Name)
@@ -143,7 +144,7 @@ func TestDetectGitHubRepo(t *testing.T) {
 		cmd := exec.Command("git", "remote", "add", "origin", "git@github.com:myowner/myrepo.git")
 		require.NoError(t, cmd.Run())
 
-		repo, err := DetectGitHubRepo()
+		repo, err := DetectGitHubRepo(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "myowner", repo.Owner)
 		assert.Equal(t, "myrepo", repo.Name)
@@ -160,7 +161,7 @@ func TestDetectGitHubRepo(t *testing.T) {
 		cmd := exec.Command("git", "remote", "add", "origin", "https://github.com/myowner/myrepo")
 		require.NoError(t, cmd.Run())
 
-		repo, err := DetectGitHubRepo()
+		repo, err := DetectGitHubRepo(t.Context())
 		require.NoError(t, err)
 		assert.Equal(t, "myowner", repo.Owner)
 		assert.Equal(t, "myrepo", repo.Name)
@@ -174,7 +175,7 @@ func TestDetectGitHubRepo(t *testing.T) {
 		os.Chdir(tmpDir)
 		initGitRepo(t, "main")
 
-		_, err := DetectGitHubRepo()
+		_, err := DetectGitHubRepo(t.Context())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "no origin remote")
 	})
@@ -190,7 +191,7 @@ func TestDetectGitHubRepo(t *testing.T) {
 		cmd := exec.Command("git", "remote", "add", "origin", "https://gitlab.com/owner/repo.git")
 		require.NoError(t, cmd.Run())
 
-		_, err := DetectGitHubRepo()
+		_, err := DetectGitHubRepo(t.Context())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "could not parse GitHub repository")
 	})
@@ -221,3 +222,158 @@ func Test_parseGitHubURL(t *testing.T) {
 		assert.False(t, ok)
 	})
 }
+
+func gitRun(t *testing.T, args ...string) {
+	t.Helper()
+
+	require.NoError(t, exec.Command("git", args...).Run())
+}
+
+func TestChangesSince(t *testing.T) {
+	t.Run("returns changes since the merge base", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+		initGitRepo(t, "main")
+
+		require.NoError(t, os.WriteFile("a.go", []byte("package a\n\nvar x = 1\nvar y = 2\n"), 0644))
+		require.NoError(t, os.WriteFile("b.go", []byte("package a\n"), 0644))
+		gitRun(t, "add", ".")
+		gitRun(t, "commit", "-m", "base")
+
+		gitRun(t, "checkout", "-q", "-b", "feature")
+		require.NoError(t, os.WriteFile("a.go", []byte("package a\n\nvar x = 10\nvar y = 2\nvar z = 3\n"), 0644))
+		require.NoError(t, os.WriteFile("c.go", []byte("package a\n"), 0644))
+		gitRun(t, "add", ".")
+		gitRun(t, "commit", "-m", "feature")
+
+		gitRun(t, "checkout", "-q", "main")
+		require.NoError(t, os.WriteFile("b.go", []byte("package a\n\nvar b = 1\n"), 0644))
+		gitRun(t, "commit", "-qam", "main moves on")
+		gitRun(t, "checkout", "-q", "feature")
+
+		changes, err := ChangesSince(t.Context(), "main")
+		require.NoError(t, err)
+		assert.Equal(t, Changes{
+			"a.go": {{Start: 3, End: 3}, {Start: 5, End: 5}},
+			"c.go": {{Start: 1, End: 1}},
+		}, changes)
+	})
+
+	t.Run("returns error for unknown ref", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+		initGitRepo(t, "main")
+
+		_, err := ChangesSince(t.Context(), "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to find merge base with missing")
+	})
+}
+
+func TestStagedChanges(t *testing.T) {
+	t.Run("returns only staged changes", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+		initGitRepo(t, "main")
+
+		require.NoError(t, os.WriteFile("a.go", []byte("package a\n"), 0644))
+		require.NoError(t, os.WriteFile("b.go", []byte("package a\n"), 0644))
+		gitRun(t, "add", "a.go")
+
+		changes, err := StagedChanges(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, Changes{"a.go": {{Start: 1, End: 1}}}, changes)
+	})
+
+	t.Run("returns error outside a repository", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		originalDir, _ := os.Getwd()
+		defer os.Chdir(originalDir)
+
+		os.Chdir(tmpDir)
+		t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(tmpDir))
+
+		_, err := StagedChanges(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to run git diff")
+	})
+}
+
+func TestChanges(t *testing.T) {
+	changes := Changes{
+		"a.go": {{Start: 3, End: 5}},
+		"b.go": {},
+	}
+
+	assert.True(t, changes.HasFile("a.go"))
+	assert.True(t, changes.HasFile("b.go"))
+	assert.False(t, changes.HasFile("c.go"))
+
+	assert.True(t, changes.HasLine("a.go", 3))
+	assert.True(t, changes.HasLine("a.go", 5))
+	assert.False(t, changes.HasLine("a.go", 6))
+	assert.False(t, changes.HasLine("b.go", 1))
+}
+
+func Test_parseUnifiedDiff(t *testing.T) {
+	diff := `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -3 +3 @@ package a
+-var x = 1
++var x = 10
+@@ -8,2 +8,0 @@ func f() {
+@@ -10,0 +9,3 @@ func f() {
++a
++b
++c
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package a
+diff --git a/trimmed.go b/trimmed.go
+--- a/trimmed.go
++++ b/trimmed.go
+@@ -4,2 +3,0 @@
+`
+
+	assert.Equal(t, Changes{
+		"a.go":       {{Start: 3, End: 3}, {Start: 9, End: 11}},
+		"trimmed.go": {},
+	}, parseUnifiedDiff([]byte(diff)))
+}
+
+func Test_parseHunkHeader(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected LineRange
+		ok       bool
+	}{
+		{"@@ -3 +3 @@", LineRange{Start: 3, End: 3}, true},
+		{"@@ -1,2 +4,3 @@ func f() {", LineRange{Start: 4, End: 6}, true},
+		{"@@ -1,2 +1,0 @@", LineRange{}, false},
+		{"@@ -1 +x @@", LineRange{}, false},
+		{"@@ -1 +1,x @@", LineRange{}, false},
+		{"@@ -1 @@", LineRange{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := parseHunkHeader(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -2,19 +2,18 @@ package tools
 
 import (
 	"encoding/json"
-	"log"
 	"os"
 )
 
-func WriteJSONFile(filename string, data any) error {
+func WriteJSONFile(filename string, data any) (err error) {
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
-		if err := f.Close(); err != nil {
-			log.Fatal(err)
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
 		}
 	}()
 
@@ -1,21 +1,20 @@
 package tools
 
 import (
-	"log"
 	"os"
 
 	"gopkg.in/yaml.v3"
 )
 
-func WriteYamlFile(filename string, data interface{}) error {
+func WriteYamlFile(filename string, data interface{}) (err error) {
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
-		if err := f.Close(); err != nil {
-			log.Fatal(err)
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
 		}
 	}()
 